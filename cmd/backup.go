@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/config"
+	"github.com/Oxyrus/financebot/internal/storage/sqlite"
+)
+
+const (
+	backupPrefix     = "financebot-"
+	backupSuffix     = ".db"
+	backupTimeFormat = "20060102-150405"
+)
+
+// runBackup implements `financebot backup`, writing a timestamped copy of the database
+// once, or repeatedly with -every until interrupted.
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	dirFlag := flags.String("dir", filepath.Join("data", "backups"), "directory the backups are written to")
+	everyFlag := flags.Duration("every", 0, "keep running and take a backup at this interval, e.g. 24h")
+	keepFlag := flags.Int("keep", 0, "delete the oldest backups beyond this many (0 keeps all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: financebot backup [-dir data/backups] [-every 24h] [-keep 7]")
+	}
+	if *everyFlag < 0 || *keepFlag < 0 {
+		return errors.New("backup: -every and -keep cannot be negative")
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := backupOnce(ctx, store, *dirFlag, *keepFlag); err != nil {
+		return err
+	}
+	if *everyFlag == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(*everyFlag)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// A failed run is retried at the next tick rather than stopping the schedule.
+			if err := backupOnce(ctx, store, *dirFlag, *keepFlag); err != nil {
+				slog.ErrorContext(ctx, "scheduled backup", "err", err)
+			}
+		}
+	}
+}
+
+// backupOnce writes a new backup into dir and then rotates old ones.
+func backupOnce(ctx context.Context, store *sqlite.Store, dir string, keep int) error {
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupTimeFormat)+backupSuffix)
+	if err := store.Backup(ctx, path); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "backup written to", path)
+	if keep == 0 {
+		return nil
+	}
+	return rotateBackups(dir, keep)
+}
+
+// rotateBackups deletes all but the newest keep backups in dir. Backup names sort in the
+// order they were taken, and other files in dir are left alone.
+func rotateBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("backup: rotate: %w", err)
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+	sort.Strings(backups)
+	for _, name := range backups[:len(backups)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("backup: rotate: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "removed old backup", name)
+	}
+	return nil
+}
+
+// runRestore implements `financebot restore`, replacing the database with a checked
+// backup. The bot must be stopped while it runs; the restore is refused when the
+// database is locked or has a leftover journal.
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	forceFlag := flags.Bool("force", false, "replace an existing database; it is kept alongside with a .before-restore suffix")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: financebot restore [-force] <backup.db>")
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	// Check before touching the current database, so a bad backup changes nothing.
+	if _, err := sqlite.CheckBackup(ctx, flags.Arg(0)); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(cfg.DatabasePath); err == nil {
+		if !*forceFlag {
+			return fmt.Errorf("restore: %s already exists; pass -force to replace it", cfg.DatabasePath)
+		}
+		previous := cfg.DatabasePath + ".before-restore"
+		if err := copyFile(cfg.DatabasePath, previous); err != nil {
+			return fmt.Errorf("restore: keep current database: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "current database kept as", previous)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	version, err := sqlite.Restore(ctx, flags.Arg(0), cfg.DatabasePath)
+	if err != nil {
+		return err
+	}
+	// Opening the store brings an older backup up to the current schema right away.
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	store.Close()
+	fmt.Fprintf(os.Stderr, "restored %s (schema version %d, now %d)\n", flags.Arg(0), version, sqlite.SchemaVersion())
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
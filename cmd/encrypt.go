@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Oxyrus/financebot/internal/config"
+	"github.com/Oxyrus/financebot/internal/storage/sqlite"
+)
+
+// runEncrypt implements `financebot encrypt`, rewriting stored expenses and queued
+// messages after encryption is turned on, the encrypted fields change or a new key is
+// put first. Backups taken earlier are not touched and keep the old values.
+func runEncrypt(args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	decryptFlag := flags.Bool("decrypt", false, "decrypt every field instead, before the keys are removed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: financebot encrypt [-decrypt]")
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return err
+	}
+	if cfg.EncryptionKeys == "" {
+		return errors.New("encrypt: set ENCRYPTION_KEY or ENCRYPTION_KEY_FILE first")
+	}
+	enc, err := storeEncryption(cfg)
+	if err != nil {
+		return err
+	}
+	if *decryptFlag {
+		enc = sqlite.Encryption{Keys: enc.Keys}
+	}
+	store, err := sqlite.NewStore(cfg.DatabasePath, sqlite.WithEncryption(enc))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	changed, err := store.Reencrypt(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d rows rewritten\n", changed)
+	fmt.Fprintln(os.Stderr, "backups taken before this run still hold the old values; replace or delete them")
+	return nil
+}
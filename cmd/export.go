@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/config"
+	"github.com/Oxyrus/financebot/internal/export"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// runExport implements `financebot export`, writing expenses to stdout.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatFlag := flags.String("format", "csv", "output format: csv or json")
+	sinceFlag := flags.String("since", "", "only expenses on or after this date (YYYY-MM-DD)")
+	untilFlag := flags.String("until", "", "only expenses before this date (YYYY-MM-DD)")
+	userFlag := flags.Int64("user", 0, "only expenses of this Telegram user ID")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+	filter := storage.ExportFilter{UserID: *userFlag}
+	if filter.Since, err = parseDateFlag("since", *sinceFlag); err != nil {
+		return err
+	}
+	if filter.Until, err = parseDateFlag("until", *untilFlag); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	out := bufio.NewWriter(os.Stdout)
+	if _, err := export.Write(context.Background(), store, out, format, filter); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func parseDateFlag(name, raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s %q: expected YYYY-MM-DD", name, raw)
+	}
+	return t, nil
+}
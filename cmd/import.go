@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Oxyrus/financebot/internal/config"
+	"github.com/Oxyrus/financebot/internal/importer"
+)
+
+// runImport implements `financebot import`, recording the missing expenses of a statement.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	userFlag := flags.Int64("user", 0, "Telegram user ID the imported expenses belong to (required)")
+	dryRunFlag := flags.Bool("dry-run", false, "show what would be imported without saving")
+	mappingFlag := flags.String("mapping", "", "CSV column mapping, overriding IMPORT_CSV_MAPPING")
+	categorizeFlag := flags.Bool("categorize", true, "categorize rows without a category via the extractor")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: financebot import -user <telegram id> [-dry-run] [-mapping ...] <statement.csv|.ofx|.qfx>")
+	}
+	if *userFlag == 0 {
+		return errors.New("import: -user is required so imported expenses can be matched and attributed")
+	}
+
+	cfg, err := config.LoadLocal()
+	if err != nil {
+		return err
+	}
+	mapping := cfg.ImportMapping
+	if *mappingFlag != "" {
+		if mapping, err = importer.ParseCSVMapping(*mappingFlag); err != nil {
+			return err
+		}
+	}
+
+	path := flags.Arg(0)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	txns, err := importer.Parse(path, file, mapping)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	opts := importer.Options{UserID: *userFlag, MatchWindow: cfg.ImportMatchWindow, DryRun: *dryRunFlag}
+	if *categorizeFlag {
+		opts.Categorizer, _, _ = newExtractor(cfg, store)
+	}
+	result, err := importer.Import(context.Background(), store, txns, opts)
+	for _, item := range result.Items {
+		fmt.Printf("%s\t%s\t%.2f\t%s\n", item.Date.Format("2006-01-02"), item.Category, item.Amount, item.Description)
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "imported"
+	if opts.DryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d expenses; %d already recorded, %d incoming payments skipped\n",
+		verb, result.Imported, result.Duplicates, result.Skipped)
+	return nil
+}
@@ -4,59 +4,304 @@ import (
 	"context"
 	"errors"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel"
 
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/api"
 	"github.com/Oxyrus/financebot/internal/bot"
 	"github.com/Oxyrus/financebot/internal/config"
 	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/health"
+	"github.com/Oxyrus/financebot/internal/logging"
+	"github.com/Oxyrus/financebot/internal/metrics"
+	"github.com/Oxyrus/financebot/internal/storage"
 	"github.com/Oxyrus/financebot/internal/storage/sqlite"
+	"github.com/Oxyrus/financebot/internal/tracing"
+	"github.com/Oxyrus/financebot/internal/web"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "encrypt":
+			if err := runEncrypt(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "backup":
+			if err := runBackup(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "restore":
+			if err := runRestore(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal(err)
 	}
+	setupLogging(cfg)
 
 	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
 	if err != nil {
-		log.Fatal(err)
+		fatal("connect to telegram", err)
 	}
 
 	botAPI.Debug = false
-	log.Printf("authorized on account %s", botAPI.Self.UserName)
+	slog.Info("authorized on telegram", "account", botAPI.Self.UserName)
 
-	commands := []tgbotapi.BotCommand{
-		{Command: "add", Description: "Record a new expense"},
-		{Command: "stats", Description: "Show expense stats"},
-	}
-	if _, err := botAPI.Request(tgbotapi.NewSetMyCommands(commands...)); err != nil {
-		log.Printf("failed to set bot commands: %v", err)
+	if _, err := botAPI.Request(tgbotapi.NewSetMyCommands(bot.Commands...)); err != nil {
+		slog.Warn("failed to set bot commands", "err", err)
 	}
 
-	openaiClient := openai.NewClient(cfg.OpenAIKey)
-	extractorSvc := extractor.NewOpenAI(openaiClient)
-	store, err := sqlite.NewStore(cfg.DatabasePath)
+	store, err := openStore(cfg)
 	if err != nil {
-		log.Fatal(err)
+		fatal("open store", err)
 	}
 	defer func() {
 		if err := store.Close(); err != nil {
-			log.Printf("close store: %v", err)
+			slog.Error("close store", "err", err)
 		}
 	}()
 
-	expenseBot := bot.New(botAPI, cfg, extractorSvc, store)
+	extractorSvc, queryExtractor, circuit := newExtractor(cfg, store)
+
+	opts := []bot.Option{
+		bot.WithPendingQueue(store),
+		bot.WithAdmins(cfg),
+		bot.WithUsageReports(store, cfg.LLMPrices, cfg.MonthlyTokenBudget),
+		bot.WithStatementImport(cfg.ImportMapping, cfg.ImportMatchWindow, cfg.ImportCategorize),
+		bot.WithAnalytics(store, analytics.Detector{Threshold: cfg.AnomalyThreshold, MinSamples: analytics.DefaultMinSamples}),
+		bot.WithQuestions(queryExtractor),
+		bot.WithTags(store),
+		bot.WithAccounts(store),
+		bot.WithGoals(store),
+		bot.WithSettings(store),
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	tracerProvider, shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, os.Stdout)
+	if err != nil {
+		fatal("set up tracing", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Error("flush traces", "err", err)
+		}
+	}()
+	otel.SetTracerProvider(tracerProvider)
+	extractorSvc = tracing.NewExtractor(extractorSvc, tracerProvider)
+	opts = append(opts, bot.WithTracerProvider(tracerProvider))
+
+	if cfg.DashboardAddr != "" {
+		signer, err := web.NewSigner([]byte(cfg.DashboardSecret))
+		if err != nil {
+			fatal("create dashboard signer", err)
+		}
+		dashboard := web.NewServer(store, signer, cfg, web.Config{Addr: cfg.DashboardAddr, BaseURL: cfg.DashboardURL})
+		dashboard.Handle(api.Prefix+"/", api.NewHandler(store, cfg))
+		opts = append(opts, bot.WithDashboard(dashboard), bot.WithAPITokens(store))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := dashboard.Run(ctx); err != nil {
+				slog.Error("dashboard stopped", "err", err)
+			}
+		}()
+		// Let in-flight dashboard requests finish before the store is closed.
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	var (
+		telegramAPI  bot.TelegramAPI      = botAPI
+		expenseStore storage.ExpenseStore = tracing.NewExpenseStore(store, tracerProvider)
+	)
+	if cfg.MetricsAddr != "" {
+		m := metrics.New()
+		telegramAPI = metrics.NewTelegram(botAPI, m, bot.CommandLabel)
+		extractorSvc = metrics.NewExtractor(extractorSvc, m)
+		expenseStore = metrics.NewExpenseStore(expenseStore, m)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := metrics.Serve(ctx, cfg.MetricsAddr, m); err != nil {
+				slog.Error("metrics stopped", "err", err)
+			}
+		}()
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
+	expenseBot := bot.New(telegramAPI, cfg, extractorSvc, expenseStore, opts...)
+
+	if cfg.HealthAddr != "" {
+		probes := health.NewHandler(
+			[]health.Check{health.Heartbeat("update_loop", expenseBot.LastActive, cfg.HealthMaxStall)},
+			readinessChecks(botAPI, store, circuit),
+		)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := health.Serve(ctx, cfg.HealthAddr, probes); err != nil {
+				slog.Error("health probes stopped", "err", err)
+			}
+		}()
+		defer func() {
+			stop()
+			<-done
+		}()
+	}
+
 	if err := expenseBot.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
-		log.Fatalf("bot stopped: %v", err)
+		fatal("bot stopped", err)
+	}
+}
+
+// openStore opens the database with the encryption settings from cfg.
+func openStore(cfg *config.Config) (*sqlite.Store, error) {
+	enc, err := storeEncryption(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sqlite.NewStore(cfg.DatabasePath, sqlite.WithEncryption(enc))
+}
+
+// storeEncryption maps the encryption settings of cfg to the store's. Descriptions are
+// always encrypted once a key is set.
+func storeEncryption(cfg *config.Config) (sqlite.Encryption, error) {
+	if cfg.EncryptionKeys == "" {
+		return sqlite.Encryption{}, nil
+	}
+	keys, err := sqlite.ParseKeyring(cfg.EncryptionKeys)
+	if err != nil {
+		return sqlite.Encryption{}, err
+	}
+	return sqlite.Encryption{
+		Keys:        keys,
+		Description: true,
+		Category:    cfg.EncryptCategory,
+		Amount:      cfg.EncryptAmount,
+	}, nil
+}
+
+// setupLogging makes JSON lines the output of slog, the log package and the Telegram
+// client, with the credentials scrubbed from all of them.
+func setupLogging(cfg *config.Config) {
+	secrets := []string{cfg.TelegramToken, cfg.OpenAIKey, cfg.DashboardSecret}
+	// Each key is scrubbed on its own, since one may show up without the rest of the
+	// setting. A keyring that does not parse fails later, when the store is opened.
+	if keys, err := sqlite.ParseKeyring(cfg.EncryptionKeys); err == nil {
+		secrets = append(secrets, keys.Encoded()...)
+	}
+	logger := logging.New(os.Stderr, logging.Config{
+		Level:   cfg.LogLevel,
+		Content: cfg.LogContent,
+		Secrets: append(secrets, cfg.EncryptionKeys),
+	})
+	slog.SetDefault(logger)
+	if err := tgbotapi.SetLogger(slog.NewLogLogger(logger.Handler(), slog.LevelWarn)); err != nil {
+		slog.Warn("set telegram logger", "err", err)
+	}
+}
+
+// fatal logs err and exits. Deferred functions do not run.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// readinessChecks verify that the database answers, that Telegram accepts the token and
+// that the extractor's circuit breaker, when there is one, is closed.
+func readinessChecks(botAPI *tgbotapi.BotAPI, store *sqlite.Store, circuit *extractor.Resilient) []health.Check {
+	checks := []health.Check{
+		{Name: "sqlite", Run: store.Ping},
+		// getMe is rate limited like any other call, so probes share one result a minute.
+		health.Cached(health.Check{Name: "telegram", Run: func(context.Context) error {
+			_, err := botAPI.GetMe()
+			return err
+		}}, time.Minute),
+	}
+	if circuit != nil {
+		checks = append(checks, health.Check{Name: "extractor_circuit", Run: func(context.Context) error {
+			if circuit.CircuitOpen() {
+				return extractor.ErrCircuitOpen
+			}
+			return nil
+		}})
+	}
+	return checks
+}
+
+// newExtractor builds the extraction chain: OpenAI behind retries, the token budget and
+// the cache, with the rule-based extractor as fallback. It also returns the question
+// extractor, which shares the retries and budget but not the cache, and the retrying
+// layer, whose circuit breaker readiness watches. Without an OpenAI key (possible for CLI
+// subcommands) only the rules are used and there is no breaker.
+func newExtractor(cfg *config.Config, store *sqlite.Store) (extractor.Service, extractor.QueryExtractor, *extractor.Resilient) {
+	if cfg.OpenAIKey == "" {
+		return extractor.NewRules(), extractor.NewRules(), nil
 	}
+
+	resilience := extractor.DefaultResilienceConfig()
+	resilience.AttemptTimeout = cfg.ExtractorTimeout
+	resilience.MaxRetries = cfg.ExtractorRetries
+	openaiExtractor := extractor.NewOpenAI(newOpenAIClient(cfg), extractor.WithUsageRecorder(store))
+	resilient := extractor.NewResilient(openaiExtractor, resilience)
+	// Questions go through the same breaker and budget as expenses.
+	budget := extractor.NewBudget(
+		resilient,
+		store,
+		cfg.MonthlyTokenBudget,
+	)
+	queries := extractor.NewQueryFallback(budget, extractor.NewRules())
+
+	var llmExtractor extractor.Service = budget
+	if cfg.CacheSize > 0 {
+		var persistent extractor.CacheStore
+		if cfg.CachePersist {
+			persistent = store
+		}
+		llmExtractor = extractor.NewCache(llmExtractor, extractor.CacheConfig{TTL: cfg.CacheTTL, MaxEntries: cfg.CacheSize}, persistent)
+	}
+	return extractor.NewFallback(llmExtractor, extractor.NewRules(), 0), queries, resilient
+}
+
+func newOpenAIClient(cfg *config.Config) *openai.Client {
+	openaiConfig := openai.DefaultConfig(cfg.OpenAIKey)
+	openaiConfig.HTTPClient = extractor.NewHTTPClient(nil)
+	return openai.NewClientWithConfig(openaiConfig)
 }
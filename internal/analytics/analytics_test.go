@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestCompareOrdersAndLabelsTrends(t *testing.T) {
+	current := map[string]float64{"Dining": 142, "Coffee": 20, "Books": 30}
+	previous := map[string]float64{"Dining": 100, "Coffee": 25, "Travel": 400}
+
+	trends := Compare(current, previous)
+	want := []struct {
+		category string
+		label    string
+	}{
+		{"Dining", "+42%"},
+		{"Books", "new"},
+		{"Coffee", "-20%"},
+		{"Travel", "gone"},
+	}
+	if len(trends) != len(want) {
+		t.Fatalf("unexpected trends %#v", trends)
+	}
+	for i, w := range want {
+		if trends[i].Category != w.category || trends[i].Label() != w.label {
+			t.Fatalf("trend %d = %s %s, want %s %s", i, trends[i].Category, trends[i].Label(), w.category, w.label)
+		}
+	}
+	if !math.IsInf(trends[1].Change(), 1) || trends[3].Change() != -1 {
+		t.Fatalf("unexpected changes %v %v", trends[1].Change(), trends[3].Change())
+	}
+	if (Trend{Category: "Flat", Current: 10, Previous: 10}).Label() != "+0%" {
+		t.Fatal("expected unchanged spending to read +0%")
+	}
+}
+
+func TestTotalsSumsAcrossBuckets(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	totals := Totals([]storage.BucketTotal{
+		{Start: day, Category: "Food", Total: 10},
+		{Start: day.AddDate(0, 0, 1), Category: "Food", Total: 5},
+		{Start: day, Category: "Coffee", Total: 3},
+	})
+	if totals["Food"] != 15 || totals["Coffee"] != 3 || len(totals) != 2 {
+		t.Fatalf("unexpected totals %v", totals)
+	}
+}
+
+func TestBaselineFrom(t *testing.T) {
+	// Amounts 2, 4, 4, 4, 5, 5, 7, 9 have mean 5 and population standard deviation 2.
+	stat := storage.CategoryStat{Count: 8, Sum: 40, SumSquares: 4 + 16 + 16 + 16 + 25 + 25 + 49 + 81}
+	baseline := BaselineFrom(stat)
+	if baseline.Count != 8 || baseline.Mean != 5 || math.Abs(baseline.StdDev-2) > 1e-9 {
+		t.Fatalf("unexpected baseline %#v", baseline)
+	}
+	if (BaselineFrom(storage.CategoryStat{}) != Baseline{}) {
+		t.Fatal("expected an empty baseline without history")
+	}
+}
+
+func TestDetectorCheck(t *testing.T) {
+	detector := DefaultDetector()
+	baseline := Baseline{Count: 20, Mean: 30, StdDev: 10}
+
+	cases := []struct {
+		name     string
+		baseline Baseline
+		amount   float64
+		flagged  bool
+	}{
+		{"usual amount", baseline, 45, false},
+		{"just under threshold", baseline, 59, false},
+		{"three deviations above", baseline, 60, true},
+		{"far below", baseline, 1, false},
+		{"not enough history", Baseline{Count: 4, Mean: 30, StdDev: 1}, 500, false},
+		// Identical history floors the spread at a quarter of the mean: 3 × 1 = 3 above.
+		{"constant history small bump", Baseline{Count: 10, Mean: 4, StdDev: 0}, 6, false},
+		{"constant history big jump", Baseline{Count: 10, Mean: 4, StdDev: 0}, 16, true},
+	}
+	for _, tc := range cases {
+		anomaly, flagged := detector.Check(tc.baseline, tc.amount)
+		if flagged != tc.flagged {
+			t.Fatalf("%s: flagged = %v, want %v (%#v)", tc.name, flagged, tc.flagged, anomaly)
+		}
+		if flagged && (anomaly.Amount != tc.amount || anomaly.Score < detector.Threshold) {
+			t.Fatalf("%s: unexpected anomaly %#v", tc.name, anomaly)
+		}
+	}
+}
+
+func TestProjectGoal(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.AddDate(0, 0, 100)
+	goal := storage.GoalProgress{
+		Goal:  storage.Goal{Target: 3000, Deadline: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC), CreatedAt: created},
+		Saved: 1000,
+	}
+
+	p := ProjectGoal(goal, now)
+	if p.Reached || math.Abs(p.Percent-1.0/3) > 1e-9 || !p.Completion.Equal(now.AddDate(0, 0, 200)) || p.OnTrack {
+		t.Fatalf("unexpected projection %#v", p)
+	}
+
+	goal.Deadline = time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)
+	if p := ProjectGoal(goal, now); !p.OnTrack {
+		t.Fatalf("expected a later deadline to be on track, got %#v", p)
+	}
+
+	goal.Saved = 0
+	if p := ProjectGoal(goal, now); !p.Completion.IsZero() || p.OnTrack {
+		t.Fatalf("expected no projection without savings, got %#v", p)
+	}
+
+	goal.Saved = 3100
+	if p := ProjectGoal(goal, now); !p.Reached || p.Percent <= 1 {
+		t.Fatalf("expected the goal to be reached, got %#v", p)
+	}
+}
@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"math"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// Defaults for anomaly detection.
+const (
+	DefaultThreshold  = 3.0
+	DefaultMinSamples = 5
+	// minSpreadRatio floors the standard deviation at a share of the mean so a category
+	// with near-identical amounts (the same coffee every day) does not flag small changes.
+	minSpreadRatio = 0.25
+)
+
+// Baseline describes a category's usual expense amounts.
+type Baseline struct {
+	Count  int
+	Mean   float64
+	StdDev float64
+}
+
+// BaselineFrom derives the mean and population standard deviation from running sums.
+func BaselineFrom(stat storage.CategoryStat) Baseline {
+	if stat.Count == 0 {
+		return Baseline{}
+	}
+	n := float64(stat.Count)
+	mean := stat.Sum / n
+	variance := stat.SumSquares/n - mean*mean
+	// Guard against tiny negative values from floating-point cancellation.
+	return Baseline{Count: stat.Count, Mean: mean, StdDev: math.Sqrt(math.Max(variance, 0))}
+}
+
+// Detector flags amounts far above a category's baseline.
+type Detector struct {
+	// Threshold is how many standard deviations above the mean count as unusual.
+	Threshold float64
+	// MinSamples is the history needed before anything is flagged.
+	MinSamples int
+}
+
+// DefaultDetector returns a detector with the package defaults.
+func DefaultDetector() Detector {
+	return Detector{Threshold: DefaultThreshold, MinSamples: DefaultMinSamples}
+}
+
+// Anomaly describes an unusually large expense.
+type Anomaly struct {
+	Amount float64
+	Baseline
+	// Score is how many (floored) standard deviations the amount lies above the mean.
+	Score float64
+}
+
+// Check reports whether amount is unusual against baseline. Only unusually high amounts
+// are flagged; spending less than usual is never a problem worth interrupting for.
+func (d Detector) Check(baseline Baseline, amount float64) (Anomaly, bool) {
+	if baseline.Count < d.MinSamples || baseline.Count == 0 || amount <= baseline.Mean {
+		return Anomaly{}, false
+	}
+	spread := math.Max(baseline.StdDev, baseline.Mean*minSpreadRatio)
+	if spread == 0 {
+		return Anomaly{}, false
+	}
+	score := (amount - baseline.Mean) / spread
+	if score < d.Threshold {
+		return Anomaly{}, false
+	}
+	return Anomaly{Amount: amount, Baseline: baseline, Score: score}, true
+}
@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// maxProjection caps completion estimates; a pace slower than this is reported as none.
+const maxProjection = 100 * 365 * 24 * time.Hour
+
+// Projection estimates when a savings goal will be reached at its contribution pace so far.
+type Projection struct {
+	// Percent is the share of the target already saved, from 0 upward.
+	Percent float64
+	// Reached is set once the savings cover the target.
+	Reached bool
+	// Completion is the estimated date the target is reached; zero when there is no pace
+	// to extrapolate from yet.
+	Completion time.Time
+	// OnTrack reports whether Completion falls on or before the goal's deadline. It is
+	// true for goals without a deadline that have a completion date.
+	OnTrack bool
+}
+
+// ProjectGoal extrapolates the average daily savings since the goal was created. A goal
+// younger than a day counts as one day old so a first contribution does not suggest an
+// absurd pace.
+func ProjectGoal(goal storage.GoalProgress, now time.Time) Projection {
+	var p Projection
+	if goal.Target > 0 {
+		p.Percent = math.Max(goal.Saved, 0) / goal.Target
+	}
+	if goal.Saved >= goal.Target {
+		p.Reached = true
+		p.OnTrack = true
+		return p
+	}
+	if goal.Saved <= 0 {
+		return p
+	}
+
+	days := math.Max(now.Sub(goal.CreatedAt).Hours()/24, 1)
+	pace := goal.Saved / days
+	remaining := time.Duration((goal.Target - goal.Saved) / pace * float64(24*time.Hour))
+	if remaining > maxProjection {
+		return p
+	}
+	p.Completion = now.Add(remaining)
+	deadline := goal.Deadline
+	if !deadline.IsZero() {
+		// The deadline is a whole day.
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	p.OnTrack = deadline.IsZero() || p.Completion.Before(deadline)
+	return p
+}
@@ -0,0 +1,74 @@
+// Package analytics derives spending trends and flags unusual expenses from aggregate
+// store queries.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// Trend compares a category's spending in the current period with the previous one.
+type Trend struct {
+	Category string
+	Current  float64
+	Previous float64
+}
+
+// Change is the relative change from the previous period, e.g. 0.42 for +42%. It is
+// +Inf for categories that had no spending before.
+func (t Trend) Change() float64 {
+	if t.Previous == 0 {
+		if t.Current == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return (t.Current - t.Previous) / t.Previous
+}
+
+// Label formats the change for display: "+42%", "-10%", "new" or "gone".
+func (t Trend) Label() string {
+	switch {
+	case t.Previous == 0 && t.Current > 0:
+		return "new"
+	case t.Current == 0 && t.Previous > 0:
+		return "gone"
+	}
+	return fmt.Sprintf("%+.0f%%", t.Change()*100)
+}
+
+// Totals sums bucket totals per category.
+func Totals(buckets []storage.BucketTotal) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, b := range buckets {
+		totals[b.Category] += b.Total
+	}
+	return totals
+}
+
+// Compare pairs up category totals of two periods, ordered by current spending and then
+// by previous spending, so categories that dropped to zero come last.
+func Compare(current, previous map[string]float64) []Trend {
+	trends := make([]Trend, 0, len(current))
+	for category, total := range current {
+		trends = append(trends, Trend{Category: category, Current: total, Previous: previous[category]})
+	}
+	for category, total := range previous {
+		if _, ok := current[category]; !ok {
+			trends = append(trends, Trend{Category: category, Previous: total})
+		}
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Current != trends[j].Current {
+			return trends[i].Current > trends[j].Current
+		}
+		if trends[i].Previous != trends[j].Previous {
+			return trends[i].Previous > trends[j].Previous
+		}
+		return trends[i].Category < trends[j].Category
+	})
+	return trends
+}
@@ -0,0 +1,644 @@
+// Package api serves a versioned JSON API over the expense store, authenticated with
+// per-user bearer tokens issued by the bot.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// Prefix is the path under which every route of this API version is served.
+const Prefix = "/api/v1"
+
+const (
+	defaultLimit     = 50
+	maxLimit         = 200
+	defaultStatsDays = 30
+	maxBodyBytes     = 64 << 10
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Store is the storage the API needs: expenses, budgets and the tokens that authenticate it.
+type Store interface {
+	storage.ExpenseStore
+	storage.BudgetStore
+	storage.APITokenStore
+}
+
+// Authorizer decides whether a Telegram user may still use the API. Tokens outlive the
+// allow list, so it is consulted on every request.
+type Authorizer interface {
+	IsUserAllowed(username string) bool
+}
+
+// Handler serves the API routes.
+type Handler struct {
+	store      Store
+	authorizer Authorizer
+	mux        *http.ServeMux
+	now        func() time.Time
+}
+
+// NewHandler wires the API routes under Prefix.
+func NewHandler(store Store, authorizer Authorizer) *Handler {
+	h := &Handler{store: store, authorizer: authorizer, mux: http.NewServeMux(), now: time.Now}
+
+	h.mux.HandleFunc("GET "+Prefix+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPIDocument)
+	})
+	h.handle("GET /expenses", h.listExpenses)
+	h.handle("POST /expenses", h.createExpense)
+	h.handle("GET /expenses/{id}", h.getExpense)
+	h.handle("PUT /expenses/{id}", h.updateExpense)
+	h.handle("DELETE /expenses/{id}", h.deleteExpense)
+	h.handle("GET /stats", h.stats)
+	h.handle("GET /categories", h.categories)
+	h.handle("GET /budgets", h.listBudgets)
+	h.handle("PUT /budgets/{category}", h.setBudget)
+	h.handle("DELETE /budgets/{category}", h.deleteBudget)
+	h.mux.HandleFunc(Prefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	})
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	h.mux.ServeHTTP(w, r)
+}
+
+// handle registers an authenticated route; pattern is "METHOD /path" relative to Prefix.
+func (h *Handler) handle(pattern string, fn func(http.ResponseWriter, *http.Request, storage.APIToken)) {
+	method, path, _ := strings.Cut(pattern, " ")
+	h.mux.HandleFunc(method+" "+Prefix+path, func(w http.ResponseWriter, r *http.Request) {
+		token, ok := h.authenticate(r.Context(), r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="financebot"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token; send /token to the bot to get one")
+			return
+		}
+		fn(w, r, token)
+	})
+}
+
+func (h *Handler) authenticate(ctx context.Context, r *http.Request) (storage.APIToken, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || !strings.HasPrefix(strings.TrimSpace(token), TokenPrefix) {
+		return storage.APIToken{}, false
+	}
+	stored, err := h.store.APITokenByHash(ctx, HashToken(token))
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.ErrorContext(ctx, "look up api token", "err", err)
+		}
+		return storage.APIToken{}, false
+	}
+	if !h.authorizer.IsUserAllowed(stored.Username) {
+		return storage.APIToken{}, false
+	}
+	return stored, true
+}
+
+// Expense is an expense as exposed by the API.
+type Expense struct {
+	ID          int64     `json:"id"`
+	Date        time.Time `json:"date"`
+	Category    string    `json:"category"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	// Type is "expense" or "income".
+	Type string `json:"type"`
+}
+
+// ExpenseInput is the body of expense create and update requests.
+type ExpenseInput struct {
+	Category    string  `json:"category"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	// Type is "expense" (the default) or "income".
+	Type string `json:"type,omitempty"`
+	// Date is optional; either YYYY-MM-DD or RFC 3339.
+	Date string `json:"date,omitempty"`
+	// Tags are applied when an expense is created; updates keep the existing tags.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Page is one page of a list endpoint. NextOffset is omitted on the last page.
+type Page[T any] struct {
+	Items      []T  `json:"items"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+func toExpense(e storage.Expense) Expense {
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return Expense{ID: e.ID, Date: e.CreatedAt.UTC(), Category: e.Category, Amount: e.Amount, Description: e.Description, Tags: tags, Type: string(expense.KindOf(e.Kind.Sign()))}
+}
+
+func (h *Handler) listExpenses(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	limit, offset, err := pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter, err := rangeFilter(r, token.UserID, time.Time{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.Category = r.URL.Query().Get("category")
+
+	// Fetch one extra row to learn whether another page follows.
+	expenses, err := h.store.ListExpenses(r.Context(), filter, limit+1, offset)
+	if err != nil {
+		internalError(w, "list expenses", err)
+		return
+	}
+	page := Page[Expense]{Items: make([]Expense, 0, len(expenses)), Limit: limit, Offset: offset}
+	if len(expenses) > limit {
+		expenses = expenses[:limit]
+		next := offset + limit
+		page.NextOffset = &next
+	}
+	for _, e := range expenses {
+		page.Items = append(page.Items, toExpense(e))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) createExpense(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	item, ok := decodeExpense(w, r)
+	if !ok {
+		return
+	}
+	if item.Date.IsZero() {
+		item.Date = h.now().UTC()
+	}
+	id, err := h.store.SaveExpense(r.Context(), token.UserID, item)
+	if err != nil {
+		internalError(w, "save expense", err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/expenses/%d", Prefix, id))
+	writeJSON(w, http.StatusCreated, toExpense(storage.Expense{ID: id, UserID: token.UserID, CreatedAt: item.Date, Item: item}))
+}
+
+func (h *Handler) getExpense(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	id, ok := expenseID(w, r)
+	if !ok {
+		return
+	}
+	e, err := h.store.GetExpense(r.Context(), token.UserID, id)
+	if err != nil {
+		storeError(w, "get expense", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toExpense(e))
+}
+
+func (h *Handler) updateExpense(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	id, ok := expenseID(w, r)
+	if !ok {
+		return
+	}
+	item, ok := decodeExpense(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.UpdateExpense(r.Context(), token.UserID, id, item); err != nil {
+		storeError(w, "update expense", err)
+		return
+	}
+	e, err := h.store.GetExpense(r.Context(), token.UserID, id)
+	if err != nil {
+		storeError(w, "get expense", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toExpense(e))
+}
+
+func (h *Handler) deleteExpense(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	id, ok := expenseID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteExpense(r.Context(), token.UserID, id); err != nil {
+		storeError(w, "delete expense", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CategoryTotal is the spending and number of expenses in one category.
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+	Count    int     `json:"count"`
+}
+
+// TagTotal is the spending and number of expenses carrying one tag.
+type TagTotal struct {
+	Tag   string  `json:"tag"`
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+// Stats summarizes spending between two dates, both inclusive. An expense with several
+// tags counts toward each of them. Income is reported separately and never counts toward
+// the totals; SavingsRate is the share of income left after spending, omitted without
+// income.
+type Stats struct {
+	Since       string          `json:"since"`
+	Until       string          `json:"until"`
+	Tag         string          `json:"tag,omitempty"`
+	Total       float64         `json:"total"`
+	Count       int             `json:"count"`
+	Income      float64         `json:"income"`
+	IncomeCount int             `json:"income_count"`
+	SavingsRate *float64        `json:"savings_rate,omitempty"`
+	Categories  []CategoryTotal `json:"categories"`
+	Tags        []TagTotal      `json:"tags"`
+}
+
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	today := startOfDay(h.now())
+	filter, err := rangeFilter(r, token.UserID, today.AddDate(0, 0, -(defaultStatsDays-1)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Until.IsZero() {
+		filter.Until = today.AddDate(0, 0, 1)
+	}
+	if !filter.Until.After(filter.Since) {
+		writeError(w, http.StatusBadRequest, "until must not be before since")
+		return
+	}
+
+	stats := Stats{Since: filter.Since.Format(time.DateOnly), Until: filter.Until.AddDate(0, 0, -1).Format(time.DateOnly), Tag: filter.Tag}
+	filter.Kind = expense.KindExpense
+	categories, tags, err := h.totals(r.Context(), filter)
+	if err != nil {
+		internalError(w, "stats", err)
+		return
+	}
+	for _, cat := range categories {
+		stats.Total += cat.Total
+		stats.Count += cat.Count
+	}
+	stats.Categories = categories
+	stats.Tags = tags
+
+	filter.Kind = expense.KindIncome
+	income, _, err := h.totals(r.Context(), filter)
+	if err != nil {
+		internalError(w, "stats", err)
+		return
+	}
+	for _, cat := range income {
+		stats.Income += cat.Total
+		stats.IncomeCount += cat.Count
+	}
+	if stats.Income > 0 {
+		rate := (stats.Income - stats.Total) / stats.Income
+		stats.SavingsRate = &rate
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) categories(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	categories, _, err := h.totals(r.Context(), storage.ExportFilter{UserID: token.UserID, Kind: expense.KindExpense})
+	if err != nil {
+		internalError(w, "categories", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]CategoryTotal{"items": categories})
+}
+
+// totals aggregates the expenses matching filter by category and by tag, largest total
+// first.
+func (h *Handler) totals(ctx context.Context, filter storage.ExportFilter) ([]CategoryTotal, []TagTotal, error) {
+	categoryTotals := map[string]*CategoryTotal{}
+	tagTotals := map[string]*TagTotal{}
+	err := h.store.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		cat, ok := categoryTotals[e.Category]
+		if !ok {
+			cat = &CategoryTotal{Category: e.Category}
+			categoryTotals[e.Category] = cat
+		}
+		cat.Total += e.Amount
+		cat.Count++
+		for _, name := range e.Tags {
+			tag, ok := tagTotals[name]
+			if !ok {
+				tag = &TagTotal{Tag: name}
+				tagTotals[name] = tag
+			}
+			tag.Total += e.Amount
+			tag.Count++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories := make([]CategoryTotal, 0, len(categoryTotals))
+	for _, cat := range categoryTotals {
+		categories = append(categories, *cat)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Total == categories[j].Total {
+			return categories[i].Category < categories[j].Category
+		}
+		return categories[i].Total > categories[j].Total
+	})
+	tags := make([]TagTotal, 0, len(tagTotals))
+	for _, tag := range tagTotals {
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Total == tags[j].Total {
+			return tags[i].Tag < tags[j].Tag
+		}
+		return tags[i].Total > tags[j].Total
+	})
+	return categories, tags, nil
+}
+
+// Budget is a monthly category limit together with this month's spending against it.
+type Budget struct {
+	Category     string  `json:"category"`
+	MonthlyLimit float64 `json:"monthly_limit"`
+	Spent        float64 `json:"spent"`
+	Remaining    float64 `json:"remaining"`
+}
+
+func (h *Handler) listBudgets(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	budgets, err := h.store.ListBudgets(r.Context(), token.UserID)
+	if err != nil {
+		internalError(w, "list budgets", err)
+		return
+	}
+	now := h.now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	spent := map[string]float64{}
+	err = h.store.ExportExpenses(r.Context(), storage.ExportFilter{UserID: token.UserID, Since: monthStart, Kind: expense.KindExpense}, func(e storage.Expense) error {
+		spent[e.Category] += e.Amount
+		return nil
+	})
+	if err != nil {
+		internalError(w, "list budgets", err)
+		return
+	}
+
+	items := make([]Budget, 0, len(budgets))
+	for _, b := range budgets {
+		items = append(items, Budget{
+			Category:     b.Category,
+			MonthlyLimit: b.MonthlyLimit,
+			Spent:        spent[b.Category],
+			Remaining:    b.MonthlyLimit - spent[b.Category],
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string][]Budget{"items": items})
+}
+
+func (h *Handler) setBudget(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	category, err := normalizeCategory(r.PathValue("category"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body struct {
+		MonthlyLimit float64 `json:"monthly_limit"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if err := checkAmount(body.MonthlyLimit, "monthly_limit"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	budget := storage.CategoryBudget{UserID: token.UserID, Category: category, MonthlyLimit: body.MonthlyLimit}
+	if err := h.store.SetBudget(r.Context(), budget); err != nil {
+		internalError(w, "set budget", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Budget{Category: category, MonthlyLimit: body.MonthlyLimit})
+}
+
+func (h *Handler) deleteBudget(w http.ResponseWriter, r *http.Request, token storage.APIToken) {
+	category, err := normalizeCategory(r.PathValue("category"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.DeleteBudget(r.Context(), token.UserID, category); err != nil {
+		storeError(w, "delete budget", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pagination(r *http.Request) (limit, offset int, err error) {
+	limit = defaultLimit
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+		}
+	}
+	if raw := query.Get("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// rangeFilter reads the since and until query parameters (YYYY-MM-DD, until inclusive),
+// the optional tag and the optional type.
+func rangeFilter(r *http.Request, userID int64, defaultSince time.Time) (storage.ExportFilter, error) {
+	filter := storage.ExportFilter{UserID: userID, Since: defaultSince}
+	query := r.URL.Query()
+	if raw := query.Get("since"); raw != "" {
+		since, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, errors.New("since must be a date like 2026-01-31")
+		}
+		filter.Since = since
+	}
+	if raw := query.Get("until"); raw != "" {
+		until, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return filter, errors.New("until must be a date like 2026-01-31")
+		}
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+	if raw := query.Get("tag"); raw != "" {
+		tag, ok := expense.NormalizeTag(raw)
+		if !ok {
+			return filter, errors.New("tag may only contain letters, digits, '-' and '_'")
+		}
+		filter.Tag = tag
+	}
+	if raw := query.Get("type"); raw != "" {
+		kind, ok := expense.ParseKind(raw)
+		if !ok {
+			return filter, errors.New(`type must be "expense" or "income"`)
+		}
+		filter.Kind = kind
+	}
+	return filter, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func expenseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusNotFound, "expense not found")
+		return 0, false
+	}
+	return id, true
+}
+
+func decodeExpense(w http.ResponseWriter, r *http.Request) (expense.Item, bool) {
+	var input ExpenseInput
+	if !decodeBody(w, r, &input) {
+		return expense.Item{}, false
+	}
+	item, err := input.item()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return expense.Item{}, false
+	}
+	return item, true
+}
+
+// item validates the input with the same limits applied to extracted expenses.
+func (in ExpenseInput) item() (expense.Item, error) {
+	if err := checkAmount(in.Amount, "amount"); err != nil {
+		return expense.Item{}, err
+	}
+	category, err := normalizeCategory(in.Category)
+	if err != nil {
+		return expense.Item{}, err
+	}
+	description := strings.Join(strings.Fields(in.Description), " ")
+	if utf8.RuneCountInString(description) > expense.MaxDescriptionLength {
+		return expense.Item{}, fmt.Errorf("description must be at most %d characters", expense.MaxDescriptionLength)
+	}
+	if description == "" {
+		// Stores require a description; the bot's extractors fall back to the category too.
+		description = category
+	}
+	kind, ok := expense.ParseKind(in.Type)
+	if !ok {
+		return expense.Item{}, errors.New(`type must be "expense" or "income"`)
+	}
+	item := expense.Item{Category: category, Amount: in.Amount, Description: description, Kind: kind}
+	if len(in.Tags) > expense.MaxTags {
+		return expense.Item{}, fmt.Errorf("an expense can have at most %d tags", expense.MaxTags)
+	}
+	for _, raw := range in.Tags {
+		tag, ok := expense.NormalizeTag(raw)
+		if !ok {
+			return expense.Item{}, fmt.Errorf("tag %q may only contain letters, digits, '-' and '_' (up to %d characters)", raw, expense.MaxTagLength)
+		}
+		item.Tags = expense.AddTags(item.Tags, tag)
+	}
+	if in.Date != "" {
+		date, err := time.Parse(time.RFC3339, in.Date)
+		if err != nil {
+			date, err = time.Parse(time.DateOnly, in.Date)
+		}
+		if err != nil {
+			return expense.Item{}, errors.New("date must be YYYY-MM-DD or RFC 3339")
+		}
+		item.Date = date.UTC()
+	}
+	return item, nil
+}
+
+func checkAmount(amount float64, field string) error {
+	if math.IsNaN(amount) || amount <= 0 || amount > expense.MaxAmount {
+		return fmt.Errorf("%s must be greater than 0 and at most %d", field, expense.MaxAmount)
+	}
+	return nil
+}
+
+func normalizeCategory(raw string) (string, error) {
+	category := strings.Join(strings.Fields(raw), " ")
+	if category == "" {
+		return "", errors.New("category is required")
+	}
+	if utf8.RuneCountInString(category) > expense.MaxCategoryLength {
+		return "", fmt.Errorf("category must be at most %d characters", expense.MaxCategoryLength)
+	}
+	return category, nil
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return false
+	}
+	return true
+}
+
+func storeError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	internalError(w, op, err)
+}
+
+func internalError(w http.ResponseWriter, op string, err error) {
+	slog.Error("api "+op, "err", err)
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("encode api response", "err", err)
+	}
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+var testNow = time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+
+type allowList map[string]bool
+
+func (a allowList) IsUserAllowed(username string) bool { return a[username] }
+
+func newTestHandler(t *testing.T) (*Handler, *memory.Store, string) {
+	t.Helper()
+	store := memory.NewStore()
+	handler := NewHandler(store, allowList{"iamoxyrus": true, "other": true})
+	handler.now = func() time.Time { return testNow }
+	return handler, store, issueToken(t, store, 7, "iamoxyrus")
+}
+
+func issueToken(t *testing.T, store *memory.Store, userID int64, username string) string {
+	t.Helper()
+	token, hash, err := GenerateToken()
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+	if _, err := store.CreateAPIToken(context.Background(), storage.APIToken{UserID: userID, Username: username, Hash: hash}); err != nil {
+		t.Fatalf("CreateAPIToken error: %v", err)
+	}
+	return token
+}
+
+func do(t *testing.T, handler http.Handler, token, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON, got %q: %s", ct, rec.Body.String())
+	}
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func TestAPIRequiresBearerToken(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+
+	for _, bad := range []string{"", "fbt_forged", strings.TrimPrefix(token, TokenPrefix)} {
+		rec := do(t, handler, bad, http.MethodGet, "/api/v1/expenses", "")
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+			t.Fatalf("token %q: expected 401, got %d", bad, rec.Code)
+		}
+		if body := decode[map[string]string](t, rec); body["error"] == "" {
+			t.Fatalf("expected an error message, got %v", body)
+		}
+	}
+
+	if rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected valid token to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A user removed from the allow list loses access even with a valid token.
+	removed := issueToken(t, store, 9, "former")
+	if rec := do(t, handler, removed, http.MethodGet, "/api/v1/expenses", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a token of a user no longer allowed to be rejected, got %d", rec.Code)
+	}
+
+	if err := store.RevokeAPIToken(context.Background(), 7, 0); err != nil {
+		t.Fatal(err)
+	}
+	if rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected revoked token to be rejected, got %d", rec.Code)
+	}
+
+	// The OpenAPI document is public.
+	rec := do(t, handler, "", http.MethodGet, "/api/v1/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected openapi document, got %d", rec.Code)
+	}
+	doc := decode[map[string]any](t, rec)
+	if doc["openapi"] == nil || doc["paths"] == nil {
+		t.Fatalf("unexpected openapi document %v", doc)
+	}
+}
+
+func TestAPIExpenseCRUD(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+	otherToken := issueToken(t, store, 8, "other")
+
+	rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"  Food ","amount":12.5,"description":"Lunch","date":"2026-10-17"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created := decode[Expense](t, rec)
+	if created.ID == 0 || created.Category != "Food" || created.Amount != 12.5 || !created.Date.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected created expense %#v", created)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/api/v1/expenses/1" {
+		t.Fatalf("unexpected location %q", loc)
+	}
+
+	rec = do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"Coffee","amount":3}`)
+	if rec.Code != http.StatusCreated || decode[Expense](t, rec).Description != "Coffee" {
+		t.Fatalf("expected a missing description to default to the category, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := store.DeleteExpense(context.Background(), 7, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = do(t, handler, token, http.MethodGet, "/api/v1/expenses/1", "")
+	if rec.Code != http.StatusOK || decode[Expense](t, rec).Description != "Lunch" {
+		t.Fatalf("unexpected get response %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, handler, otherToken, http.MethodGet, "/api/v1/expenses/1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected another user's expense to be hidden, got %d", rec.Code)
+	}
+
+	rec = do(t, handler, token, http.MethodPut, "/api/v1/expenses/1", `{"category":"Travel","amount":30,"description":"Taxi"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	updated := decode[Expense](t, rec)
+	if updated.Category != "Travel" || updated.Amount != 30 || !updated.Date.Equal(created.Date) {
+		t.Fatalf("expected fields updated and date kept, got %#v", updated)
+	}
+	if rec := do(t, handler, otherToken, http.MethodPut, "/api/v1/expenses/1", `{"category":"x","amount":1}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected update by another user to 404, got %d", rec.Code)
+	}
+
+	if rec := do(t, handler, otherToken, http.MethodDelete, "/api/v1/expenses/1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected delete by another user to 404, got %d", rec.Code)
+	}
+	if rec := do(t, handler, token, http.MethodDelete, "/api/v1/expenses/1", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses/1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected deleted expense to 404, got %d", rec.Code)
+	}
+}
+
+func TestAPIRejectsInvalidExpenses(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+
+	cases := []string{
+		`{"category":"Food","amount":0}`,
+		`{"category":"Food","amount":-3}`,
+		`{"category":"Food","amount":2000000}`,
+		`{"category":"   ","amount":3}`,
+		`{"category":"` + strings.Repeat("x", 41) + `","amount":3}`,
+		`{"category":"Food","amount":3,"description":"` + strings.Repeat("x", 201) + `"}`,
+		`{"category":"Food","amount":3,"date":"yesterday"}`,
+		`{"category":"Food","amount":3,"user_id":8}`,
+		`not json`,
+	}
+	for _, body := range cases {
+		rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, rec.Code)
+		}
+	}
+	if items := store.Items(); len(items) != 0 {
+		t.Fatalf("expected nothing stored, got %#v", items)
+	}
+}
+
+func TestAPIListExpensesPaginates(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		day := time.Date(2026, 10, 1+i, 12, 0, 0, 0, time.UTC)
+		if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: float64(i + 1), Date: day}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 99, Date: testNow}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses?limit=2", "")
+	page := decode[Page[Expense]](t, rec)
+	if len(page.Items) != 2 || page.Items[0].Amount != 5 || page.Items[1].Amount != 4 || page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Fatalf("unexpected first page %#v", page)
+	}
+
+	rec = do(t, handler, token, http.MethodGet, "/api/v1/expenses?limit=2&offset=4", "")
+	page = decode[Page[Expense]](t, rec)
+	if len(page.Items) != 1 || page.Items[0].Amount != 1 || page.NextOffset != nil {
+		t.Fatalf("unexpected last page %#v", page)
+	}
+
+	rec = do(t, handler, token, http.MethodGet, "/api/v1/expenses?since=2026-10-02&until=2026-10-03", "")
+	page = decode[Page[Expense]](t, rec)
+	if len(page.Items) != 2 || page.Items[0].Amount != 3 || page.Items[1].Amount != 2 {
+		t.Fatalf("expected inclusive date range, got %#v", page)
+	}
+
+	for _, query := range []string{"limit=0", "limit=201", "offset=-1", "since=10/02/2026"} {
+		if rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Fatalf("query %s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestAPIStatsAndCategories(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+	ctx := context.Background()
+	seed := []struct {
+		category string
+		amount   float64
+		day      time.Time
+	}{
+		{"Food", 10, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{"Food", 5, time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{"Coffee", 3, time.Date(2026, 10, 10, 9, 0, 0, 0, time.UTC)},
+		{"Rent", 900, time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, s := range seed {
+		if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: s.category, Amount: s.amount, Date: s.day}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 1000, Date: testNow}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := decode[Stats](t, do(t, handler, token, http.MethodGet, "/api/v1/stats", ""))
+	if stats.Since != "2026-09-19" || stats.Until != "2026-10-18" || stats.Total != 18 || stats.Count != 3 {
+		t.Fatalf("unexpected default stats %#v", stats)
+	}
+	if len(stats.Categories) != 2 || stats.Categories[0].Category != "Food" || stats.Categories[0].Total != 15 {
+		t.Fatalf("unexpected category totals %#v", stats.Categories)
+	}
+
+	stats = decode[Stats](t, do(t, handler, token, http.MethodGet, "/api/v1/stats?since=2026-08-01&until=2026-10-01", ""))
+	if stats.Total != 905 || stats.Count != 2 {
+		t.Fatalf("unexpected ranged stats %#v", stats)
+	}
+
+	if rec := do(t, handler, token, http.MethodGet, "/api/v1/stats?since=2026-10-05&until=2026-10-01", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected inverted range to be rejected, got %d", rec.Code)
+	}
+
+	categories := decode[map[string][]CategoryTotal](t, do(t, handler, token, http.MethodGet, "/api/v1/categories", ""))
+	got := categories["items"]
+	if len(got) != 3 || got[0].Category != "Rent" || got[1].Category != "Food" || got[1].Count != 2 {
+		t.Fatalf("unexpected categories %#v", got)
+	}
+}
+
+func TestAPITags(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+
+	rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"Travel","amount":300,"description":"Hotel","tags":["Vacation-2026","#work"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created := decode[Expense](t, rec)
+	if strings.Join(created.Tags, ",") != "vacation-2026,work" {
+		t.Fatalf("expected normalized tags, got %v", created.Tags)
+	}
+	if _, err := store.SaveExpense(context.Background(), 7, expense.Item{Category: "Food", Amount: 40, Date: testNow, Tags: []string{"vacation-2026"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SaveExpense(context.Background(), 7, expense.Item{Category: "Food", Amount: 8, Date: testNow}); err != nil {
+		t.Fatal(err)
+	}
+
+	page := decode[Page[Expense]](t, do(t, handler, token, http.MethodGet, "/api/v1/expenses?tag=VACATION-2026", ""))
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 tagged expenses, got %#v", page.Items)
+	}
+
+	stats := decode[Stats](t, do(t, handler, token, http.MethodGet, "/api/v1/stats", ""))
+	if stats.Total != 348 || len(stats.Tags) != 2 || stats.Tags[0] != (TagTotal{Tag: "vacation-2026", Total: 340, Count: 2}) {
+		t.Fatalf("unexpected tag totals %#v", stats.Tags)
+	}
+	stats = decode[Stats](t, do(t, handler, token, http.MethodGet, "/api/v1/stats?tag=work", ""))
+	if stats.Tag != "work" || stats.Total != 300 || stats.Count != 1 {
+		t.Fatalf("unexpected tag-filtered stats %#v", stats)
+	}
+
+	for _, path := range []string{"/api/v1/stats?tag=no%20spaces", "/api/v1/expenses?tag=%25"} {
+		if rec := do(t, handler, token, http.MethodGet, path, ""); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+	if rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"Food","amount":1,"tags":["a b"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid tag to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestAPIIncome(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+
+	rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"Salary","amount":2000,"type":"income"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if created := decode[Expense](t, rec); created.Type != "income" {
+		t.Fatalf("expected income entry, got %#v", created)
+	}
+	if _, err := store.SaveExpense(context.Background(), 7, expense.Item{Category: "Rent", Amount: 1500, Date: testNow}); err != nil {
+		t.Fatal(err)
+	}
+
+	page := decode[Page[Expense]](t, do(t, handler, token, http.MethodGet, "/api/v1/expenses?type=expense", ""))
+	if len(page.Items) != 1 || page.Items[0].Category != "Rent" || page.Items[0].Type != "expense" {
+		t.Fatalf("expected only the expense, got %#v", page.Items)
+	}
+
+	stats := decode[Stats](t, do(t, handler, token, http.MethodGet, "/api/v1/stats", ""))
+	if stats.Total != 1500 || stats.Count != 1 || stats.Income != 2000 || stats.IncomeCount != 1 {
+		t.Fatalf("expected income to be reported apart from spending, got %#v", stats)
+	}
+	if stats.SavingsRate == nil || *stats.SavingsRate != 0.25 {
+		t.Fatalf("expected 25%% savings rate, got %v", stats.SavingsRate)
+	}
+
+	if rec := do(t, handler, token, http.MethodPost, "/api/v1/expenses", `{"category":"Food","amount":1,"type":"gift"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown type to be rejected, got %d", rec.Code)
+	}
+	if rec := do(t, handler, token, http.MethodGet, "/api/v1/expenses?type=gift", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown type filter to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestAPIBudgets(t *testing.T) {
+	handler, store, token := newTestHandler(t)
+	ctx := context.Background()
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 40, Date: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 500, Date: time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := do(t, handler, token, http.MethodPut, "/api/v1/budgets/Food", `{"monthly_limit":300}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, handler, token, http.MethodPut, "/api/v1/budgets/Food", `{"monthly_limit":0}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected zero limit to be rejected, got %d", rec.Code)
+	}
+
+	budgets := decode[map[string][]Budget](t, do(t, handler, token, http.MethodGet, "/api/v1/budgets", ""))
+	got := budgets["items"]
+	if len(got) != 1 || got[0].MonthlyLimit != 300 || got[0].Spent != 40 || got[0].Remaining != 260 {
+		t.Fatalf("unexpected budgets %#v", got)
+	}
+
+	if rec := do(t, handler, token, http.MethodDelete, "/api/v1/budgets/Food", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec := do(t, handler, token, http.MethodDelete, "/api/v1/budgets/Food", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	if rec := do(t, handler, token, http.MethodPut, "/api/v1/budgets/%20Eating%20%20Out%20", `{"monthly_limit":120}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(t, handler, token, http.MethodDelete, "/api/v1/budgets/Eating%20Out%20", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the budget to be deleted under its normalized name, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerateTokenHashesConsistently(t *testing.T) {
+	token, hash, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, TokenPrefix) || len(token) < 40 {
+		t.Fatalf("unexpected token %q", token)
+	}
+	if HashToken(token) != hash || strings.Contains(hash, token) {
+		t.Fatalf("hash does not match token")
+	}
+	other, _, _ := GenerateToken()
+	if other == token {
+		t.Fatal("expected distinct tokens")
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TokenPrefix marks financebot API tokens so they are easy to recognize in configs and logs.
+const TokenPrefix = "fbt_"
+
+// GenerateToken returns a new random bearer token and the hash to store for it. The token
+// itself is shown to the user once and never persisted.
+func GenerateToken() (token, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("api: generate token: %w", err)
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex SHA-256 digest under which a token is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
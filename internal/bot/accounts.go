@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const maxAccountNameLength = 40
+
+// WithAccounts enables the /accounts command and the account balances in /stats.
+func WithAccounts(store storage.AccountStore) Option {
+	return func(b *Bot) {
+		b.accounts = store
+	}
+}
+
+func (b *Bot) handleAccounts(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.accounts == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.disabled"))
+		return
+	}
+
+	args := strings.Fields(msg.CommandArguments())
+	action := "list"
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+	switch {
+	case action == "list" && len(args) <= 1:
+		b.listAccounts(ctx, msg)
+	case action == "add" && len(args) >= 2:
+		b.addAccount(ctx, msg, args[1:])
+	case action == "default" && len(args) == 2:
+		b.setDefaultAccount(ctx, msg, args[1])
+	default:
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.usage"))
+	}
+}
+
+func (b *Bot) addAccount(ctx context.Context, msg *tgbotapi.Message, args []string) {
+	settings := locale.From(ctx)
+	account, err := parseAccountArgs(args, settings)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(err), settings.T("accounts.usage")))
+		return
+	}
+	account.UserID = msg.From.ID
+
+	_, err = b.accounts.CreateAccount(ctx, account)
+	if errors.Is(err, storage.ErrExists) {
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.exists", account.Name))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "create account", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.add_failed"))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("accounts.added", account.Name, settings.T("account_type."+string(account.Type)), account.Name))
+}
+
+// parseAccountArgs reads "<name> <type> [opening balance]" from /accounts add arguments.
+// A name that is itself a type, such as "cash", needs no separate type.
+func parseAccountArgs(args []string, settings locale.Settings) (storage.Account, error) {
+	account := storage.Account{Name: args[0]}
+	if utf8.RuneCountInString(account.Name) > maxAccountNameLength {
+		return storage.Account{}, locale.Errorf("accounts.err.name_length", maxAccountNameLength)
+	}
+	rest := args[1:]
+	if len(rest) > 0 {
+		if t, ok := storage.ParseAccountType(rest[0]); ok {
+			account.Type = t
+			rest = rest[1:]
+		}
+	}
+	if account.Type == "" {
+		t, ok := storage.ParseAccountType(account.Name)
+		if !ok {
+			return storage.Account{}, locale.Errorf("accounts.err.type", account.Name)
+		}
+		account.Type = t
+	}
+	if len(rest) > 1 {
+		return storage.Account{}, locale.Errorf("accounts.err.single_word")
+	}
+	if len(rest) == 1 {
+		balance, err := parseMoney(rest[0], settings)
+		if err != nil {
+			return storage.Account{}, locale.Errorf("accounts.err.balance", rest[0])
+		}
+		account.OpeningBalance = balance
+	}
+	return account, nil
+}
+
+func (b *Bot) listAccounts(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	accounts, err := b.accounts.ListAccounts(ctx, msg.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "list accounts", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.load_failed"))
+		return
+	}
+	if len(accounts) == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.none"))
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(settings.T("accounts.list_heading"))
+	for _, a := range accounts {
+		builder.WriteString(settings.T("accounts.list_item", a.Name, settings.T("account_type."+string(a.Type))))
+		if a.Default {
+			builder.WriteString(settings.T("accounts.list_default"))
+		}
+		builder.WriteString("\n")
+	}
+	b.reply(ctx, msg.Chat.ID, strings.TrimRight(builder.String(), "\n"))
+}
+
+func (b *Bot) setDefaultAccount(ctx context.Context, msg *tgbotapi.Message, name string) {
+	settings := locale.From(ctx)
+	err := b.accounts.SetDefaultAccount(ctx, msg.From.ID, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.not_found", name))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "set default account", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("accounts.default_failed"))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("accounts.default_set", name))
+}
+
+// accountSection lists the user's account balances and this month's card spending for
+// /stats, or returns "" when accounts are disabled or the user has none.
+func (b *Bot) accountSection(ctx context.Context, userID int64, now time.Time) string {
+	if b.accounts == nil {
+		return ""
+	}
+	settings := locale.From(ctx)
+	balances, err := b.accounts.AccountBalances(ctx, userID, settings.StartOfMonth(now))
+	if err != nil {
+		// Balances are a bonus; the summary is still worth sending.
+		slog.WarnContext(ctx, "load account balances", "err", err)
+		return ""
+	}
+	if len(balances) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(settings.T("accounts.section_heading"))
+	for _, a := range balances {
+		builder.WriteString(settings.T("common.list_amount", a.Name, settings.Money(a.Balance)))
+		if a.Type != storage.AccountCash {
+			builder.WriteString(settings.T("accounts.card_spent", settings.T("account_type."+string(a.Type)), settings.Money(a.Spent)))
+		}
+		builder.WriteString("\n")
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func TestAccountsCommand(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, store, WithAccounts(store))
+	ctx := context.Background()
+
+	for _, text := range []string{
+		"/accounts add Wallet cash 120",
+		"/accounts add Visa credit",
+		"/accounts add visa debit",
+		"/accounts add Nubank",
+		"/accounts default Visa",
+		"/accounts default Amex",
+		"/accounts",
+	} {
+		b.handleUpdate(ctx, commandUpdate(text))
+	}
+
+	want := []string{
+		"Added Wallet (cash).",
+		"Added Visa (credit).",
+		"You already have an account named visa.",
+		"Sorry, tell me whether Nubank is cash, debit or credit.",
+		"Expenses that name no account now go to Visa.",
+		"You have no account named Amex.",
+		"Your accounts:\n- Visa (credit) — default\n- Wallet (cash)",
+	}
+	if len(fake.messages) != len(want) {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(fake.messages[i], w) {
+			t.Errorf("reply %d: expected prefix %q, got %q", i, w, fake.messages[i])
+		}
+	}
+}
+
+func TestStatsShowsAccountBalances(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	ctx := context.Background()
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "Wallet", Type: storage.AccountCash, OpeningBalance: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "Visa", Type: storage.AccountCredit}); err != nil {
+		t.Fatal(err)
+	}
+	extractor := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 4, Description: "Latte", Account: "visa"}}
+	b := New(fake, allowAllAuthorizer{}, extractor, store, WithAccounts(store))
+
+	b.handleUpdate(ctx, textUpdate("latte 4 with visa"))
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Taxi", Amount: 10, Description: "Taxi"}); err != nil {
+		t.Fatal(err)
+	}
+	b.handleUpdate(ctx, commandUpdate("/stats month"))
+
+	if !strings.Contains(fake.messages[0], "Account: visa") {
+		t.Fatalf("expected the account in the confirmation, got %q", fake.messages[0])
+	}
+	if !strings.Contains(fake.messages[1], "Accounts:\n- Visa: -$4.00 (credit card, $4.00 this month)\n- Wallet: $90.00") {
+		t.Fatalf("expected account balances in /stats, got %q", fake.messages[1])
+	}
+}
+
+func TestParseAccountArgs(t *testing.T) {
+	account, err := parseAccountArgs([]string{"cash"}, locale.Default())
+	if err != nil || account.Type != storage.AccountCash {
+		t.Fatalf("expected a cash account, got %#v (%v)", account, err)
+	}
+	account, err = parseAccountArgs([]string{"Checking", "debit", "$1500.50"}, locale.Default())
+	if err != nil || account.Type != storage.AccountDebit || account.OpeningBalance != 1500.5 {
+		t.Fatalf("unexpected account %#v (%v)", account, err)
+	}
+	for _, args := range [][]string{{"Visa", "credit", "lots"}, {"Visa", "credit", "NaN"}, {"Visa", "credit", "1e308"}, {"My", "Visa", "card"}, {strings.Repeat("x", 41), "cash"}} {
+		if _, err := parseAccountArgs(args, locale.Default()); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -2,16 +2,24 @@ package bot
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"sort"
-	"strings"
+	"errors"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/expense"
 	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/logging"
+	"github.com/Oxyrus/financebot/internal/reqctx"
 	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/usage"
 )
 
 // Authorizer determines whether a Telegram username may interact with the bot.
@@ -19,11 +27,18 @@ type Authorizer interface {
 	IsUserAllowed(username string) bool
 }
 
+// AdminChecker determines whether a Telegram username may use administrative commands.
+type AdminChecker interface {
+	IsAdmin(username string) bool
+}
+
 // TelegramAPI abstracts sending and receiving Telegram updates.
 type TelegramAPI interface {
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
 	StopReceivingUpdates()
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
 }
 
 // Bot wraps Telegram update handling with expense extraction and persistence.
@@ -32,16 +47,72 @@ type Bot struct {
 	extractor  extractor.Service
 	store      storage.ExpenseStore
 	authorizer Authorizer
+	pending    storage.PendingQueue
+	confirms   *confirmations
+	admins     AdminChecker
+	usage      storage.UsageStore
+	prices     usage.PriceTable
+	budget     int64
+	imports    *statementImport
+	dashboard  DashboardLinker
+	analytics  storage.AggregateStore
+	detector   analytics.Detector
+	tokens     storage.APITokenStore
+	tags       storage.TagStore
+	questions  extractor.QueryExtractor
+	accounts   storage.AccountStore
+	goals      storage.GoalStore
+	settings   storage.SettingsStore
+	tracer     trace.Tracer
+	// lastActive is when the update loop last went round, in Unix nanoseconds.
+	lastActive atomic.Int64
+}
+
+// heartbeatInterval is how often an idle update loop marks itself active.
+const heartbeatInterval = 10 * time.Second
+
+// Option configures optional Bot features.
+type Option func(*Bot)
+
+// WithPendingQueue enables queueing of messages that fail to extract or persist so they
+// are retried in the background instead of being dropped.
+func WithPendingQueue(queue storage.PendingQueue) Option {
+	return func(b *Bot) {
+		b.pending = queue
+	}
+}
+
+// WithAdmins enables administrative commands for the usernames admins accepts.
+func WithAdmins(admins AdminChecker) Option {
+	return func(b *Bot) {
+		b.admins = admins
+	}
+}
+
+// WithUsageReports enables the admin /usage command with cost estimates from prices and,
+// when monthlyBudget is positive, progress against the monthly token budget.
+func WithUsageReports(store storage.UsageStore, prices usage.PriceTable, monthlyBudget int64) Option {
+	return func(b *Bot) {
+		b.usage = store
+		b.prices = prices
+		b.budget = monthlyBudget
+	}
 }
 
 // New constructs a bot ready to process updates.
-func New(api TelegramAPI, authorizer Authorizer, extractor extractor.Service, store storage.ExpenseStore) *Bot {
-	return &Bot{
+func New(api TelegramAPI, authorizer Authorizer, extractor extractor.Service, store storage.ExpenseStore, opts ...Option) *Bot {
+	b := &Bot{
 		api:        api,
 		extractor:  extractor,
 		store:      store,
 		authorizer: authorizer,
+		confirms:   newConfirmations(),
+		tracer:     noop.NewTracerProvider().Tracer(tracerName),
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 // Start begins consuming telegram updates until the context is canceled.
@@ -52,10 +123,21 @@ func (b *Bot) Start(ctx context.Context) error {
 	updates := b.api.GetUpdatesChan(u)
 	defer b.api.StopReceivingUpdates()
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if b.pending != nil {
+		go b.runPendingRetries(ctx)
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
 	for {
+		b.lastActive.Store(time.Now().UnixNano())
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-heartbeat.C:
 		case update, ok := <-updates:
 			if !ok {
 				return nil
@@ -65,18 +147,46 @@ func (b *Bot) Start(ctx context.Context) error {
 	}
 }
 
+// LastActive returns when the update loop last went round, or the zero time before Start.
+// An idle loop still goes round every heartbeatInterval, so a stale time means an update
+// handler is stuck.
+func (b *Bot) LastActive() time.Time {
+	nanos := b.lastActive.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	ctx = reqctx.WithRequest(ctx, reqctx.Request{ID: reqctx.NewRequestID(), ChatID: updateChatID(update)})
+	ctx, span := b.tracer.Start(ctx, "bot.handleUpdate", trace.WithAttributes(attribute.String("financebot.update.type", updateType(update))))
+	defer span.End()
+
+	if update.CallbackQuery != nil {
+		b.handleCallback(ctx, update.CallbackQuery)
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
 
 	if update.Message.From == nil {
-		log.Println("skipping message without sender")
+		slog.DebugContext(ctx, "skipping message without sender")
 		return
 	}
 
 	username := update.Message.From.UserName
 	if username == "" || !b.authorizer.IsUserAllowed(username) {
+		setOutcome(ctx, "unauthorized")
+		return
+	}
+	ctx = reqctx.WithUser(ctx, reqctx.User{ID: update.Message.From.ID, Username: username})
+	ctx = b.withUserSettings(ctx, update.Message.From.ID)
+
+	if update.Message.Document != nil {
+		b.handleDocument(ctx, update.Message)
 		return
 	}
 
@@ -85,85 +195,135 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 		return
 	}
 
+	if b.questions != nil && extractor.ClassifyIntent(update.Message.Text) == extractor.IntentQuestion {
+		b.answerQuestion(ctx, update.Message, update.Message.Text)
+		return
+	}
+
 	b.processExpense(ctx, update)
 }
 
-func (b *Bot) reply(chatID int64, text string) {
+// updateChatID returns the chat an update came from, or 0 when it has none.
+func updateChatID(update tgbotapi.Update) int64 {
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	return 0
+}
+
+func (b *Bot) reply(ctx context.Context, chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	if _, err := b.api.Send(msg); err != nil {
-		log.Printf("failed to send message: %v", err)
+	if _, err := b.send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send message", "err", err)
 	}
 }
 
 func (b *Bot) handleCommand(ctx context.Context, update tgbotapi.Update) {
 	msg := update.Message
+	ctx, span := b.tracer.Start(ctx, "bot.command", trace.WithAttributes(attribute.String("financebot.command", CommandLabel(msg.Command()))))
+	defer span.End()
+	outcome := "handled"
 	switch msg.Command() {
 	case "add":
 		args := msg.CommandArguments()
 		if args == "" {
-			b.reply(msg.Chat.ID, "Send an expense description after /add, e.g. `/add Coffee $3.50`.")
+			setOutcome(ctx, "usage")
+			b.reply(ctx, msg.Chat.ID, locale.From(ctx).T("add.usage"))
 			return
 		}
 		update.Message.Text = args
+		// processExpense records its own outcome.
 		b.processExpense(ctx, update)
+		return
 	case "stats":
-		since := time.Now().AddDate(0, 0, -7)
-		summary, err := b.store.Stats(ctx, since)
-		if err != nil {
-			b.reply(msg.Chat.ID, fmt.Sprintf("Failed to load stats: %v", err))
-			return
-		}
-		if summary.TotalCount == 0 {
-			b.reply(msg.Chat.ID, "No expenses recorded in the last 7 days.")
-			return
-		}
-		b.reply(msg.Chat.ID, formatSummary(summary, since))
+		b.handleStats(ctx, msg)
+	case "pending":
+		b.handlePending(ctx, msg)
+	case "usage":
+		b.handleUsage(ctx, msg)
+	case "export":
+		b.handleExport(ctx, msg)
+	case "chart":
+		b.handleChart(ctx, msg)
+	case "dashboard":
+		b.handleDashboard(ctx, msg)
+	case "token":
+		b.handleToken(ctx, msg)
+	case "ask":
+		b.handleAsk(ctx, msg)
+	case "tag":
+		b.handleTag(ctx, msg)
+	case "accounts":
+		b.handleAccounts(ctx, msg)
+	case "goal", "goals":
+		b.handleGoal(ctx, msg)
+	case "settings":
+		b.handleSettings(ctx, msg)
 	default:
-		b.reply(msg.Chat.ID, fmt.Sprintf("Unknown command: /%s", msg.Command()))
+		outcome = "unknown_command"
+		b.reply(ctx, msg.Chat.ID, locale.From(ctx).T("command.unknown", msg.Command()))
 	}
+	setOutcome(ctx, outcome)
 }
 
 func (b *Bot) processExpense(ctx context.Context, update tgbotapi.Update) {
 	text := update.Message.Text
-	log.Printf("[%s] %s", update.Message.From.UserName, text)
+	slog.DebugContext(ctx, "expense message", logging.Text("text", text))
 
-	item, err := b.extractor.Extract(ctx, text)
+	settings := locale.From(ctx)
+	item, err := b.extract(ctx, text)
 	if err != nil {
-		b.reply(update.Message.Chat.ID, fmt.Sprintf("Error: %v", err))
+		slog.WarnContext(ctx, "extract expense", "reason", extractor.Reason(err), "err", err)
+		reason := extractor.UserMessage(err, settings)
+		if extractor.IsRetryable(err) && b.enqueuePending(ctx, update.Message, reason) {
+			setOutcome(ctx, "queued")
+			return
+		}
+		setOutcome(ctx, "extract_failed")
+		b.reply(ctx, update.Message.Chat.ID, reason)
 		return
 	}
 
-	if err := b.store.SaveExpense(ctx, item); err != nil {
-		b.reply(update.Message.Chat.ID, fmt.Sprintf("Failed to store expense: %v", err))
+	if dup, ok := b.findDuplicate(ctx, update.Message.From.ID, item); ok {
+		setOutcome(ctx, "duplicate")
+		b.askDuplicate(ctx, update.Message.Chat.ID, update.Message.From.ID, item, dup)
 		return
 	}
 
-	b.reply(update.Message.Chat.ID, item.ReplyMessage())
-}
-
-func formatSummary(summary storage.Summary, since time.Time) string {
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("Last 7 days (since %s):\n", since.Format("2006-01-02")))
-	builder.WriteString(fmt.Sprintf("Total: $%.2f across %d expenses\n", summary.TotalAmount, summary.TotalCount))
+	// Judge the amount against history before it becomes part of that history.
+	note := b.anomalyNote(ctx, update.Message.From.ID, item)
 
-	type catTotal struct {
-		name  string
-		value float64
-	}
-	catTotals := make([]catTotal, 0, len(summary.CategoryTotals))
-	for name, total := range summary.CategoryTotals {
-		catTotals = append(catTotals, catTotal{name: name, value: total})
+	id, err := b.store.SaveExpense(ctx, update.Message.From.ID, item)
+	if err != nil {
+		slog.ErrorContext(ctx, "store expense", "err", err)
+		// An expense the store rejects would be rejected on every retry too.
+		if !errors.Is(err, storage.ErrInvalidExpense) && b.enqueuePending(ctx, update.Message, settings.T("pending.store_failed")) {
+			setOutcome(ctx, "queued")
+			return
+		}
+		setOutcome(ctx, "store_failed")
+		b.reply(ctx, update.Message.Chat.ID, settings.T("expense.store_failed", err))
+		return
 	}
-	sort.Slice(catTotals, func(i, j int) bool {
-		return catTotals[i].value > catTotals[j].value
-	})
+	setOutcome(ctx, "recorded")
 
-	if len(catTotals) > 0 {
-		builder.WriteString("By category:\n")
-		for _, ct := range catTotals {
-			builder.WriteString(fmt.Sprintf("- %s: $%.2f\n", ct.name, ct.value))
-		}
+	slog.InfoContext(ctx, "expense recorded", "expense_id", id, "category", item.Category, logging.Amount("amount", item.Amount), logging.Text("description", item.Description))
+	b.reply(ctx, update.Message.Chat.ID, recordedReply(id, item, settings)+note)
+}
+
+// extract pulls #hashtags out of text as tags and extracts the expense from the rest, so
+// the tags neither reach the model nor end up in the description.
+func (b *Bot) extract(ctx context.Context, text string) (expense.Item, error) {
+	rest, tags := expense.ExtractTags(text)
+	item, err := b.extractor.Extract(ctx, rest)
+	if err != nil {
+		return expense.Item{}, err
 	}
+	item.Tags = tags
+	return item, nil
+}
 
-	return strings.TrimRight(builder.String(), "\n")
+// recordedReply confirms a stored expense, including the ID later commands refer to.
+func recordedReply(id int64, item expense.Item, settings locale.Settings) string {
+	return settings.T("expense.recorded_id", item.ReplyMessage(settings), id)
 }
@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,8 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/locale"
 	"github.com/Oxyrus/financebot/internal/storage"
 )
 
@@ -32,19 +35,54 @@ type fakeStore struct {
 	err      error
 	stats    storage.Summary
 	statsErr error
+	recent   []storage.Expense
+
+	exportFilter storage.ExportFilter
+	statsFilter  storage.ExportFilter
 }
 
-func (f *fakeStore) SaveExpense(_ context.Context, item expense.Item) error {
+func (f *fakeStore) SaveExpense(_ context.Context, _ int64, item expense.Item) (int64, error) {
 	if f.err != nil {
-		return f.err
+		return 0, f.err
 	}
 	f.items = append(f.items, item)
+	return int64(len(f.items)), nil
+}
+
+func (f *fakeStore) RecentExpenses(context.Context, int64, time.Time) ([]storage.Expense, error) {
+	return f.recent, nil
+}
+
+func (f *fakeStore) ExportExpenses(_ context.Context, filter storage.ExportFilter, fn func(storage.Expense) error) error {
+	f.exportFilter = filter
+	for _, e := range f.recent {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (f *fakeStore) ListExpenses(context.Context, storage.ExportFilter, int, int) ([]storage.Expense, error) {
+	return f.recent, nil
+}
+
+func (f *fakeStore) GetExpense(context.Context, int64, int64) (storage.Expense, error) {
+	return storage.Expense{}, storage.ErrNotFound
+}
+
+func (f *fakeStore) UpdateExpense(context.Context, int64, int64, expense.Item) error {
+	return storage.ErrNotFound
+}
+
+func (f *fakeStore) DeleteExpense(context.Context, int64, int64) error {
+	return storage.ErrNotFound
+}
+
 func (f *fakeStore) Close() error { return nil }
 
-func (f *fakeStore) Stats(_ context.Context, _ time.Time) (storage.Summary, error) {
+func (f *fakeStore) Stats(_ context.Context, filter storage.ExportFilter) (storage.Summary, error) {
+	f.statsFilter = filter
 	if f.statsErr != nil {
 		return storage.Summary{}, f.statsErr
 	}
@@ -52,22 +90,76 @@ func (f *fakeStore) Stats(_ context.Context, _ time.Time) (storage.Summary, erro
 }
 
 type fakeAPI struct {
-	messages []string
+	updates   chan tgbotapi.Update
+	messages  []string
+	markups   []any
+	edits     []string
+	callbacks []string
+	documents []fakeDocument
+	photos    []fakeDocument
+	fileURL   string
 }
 
-func (f *fakeAPI) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel { return nil }
+type fakeDocument struct {
+	name    string
+	caption string
+	data    []byte
+}
+
+func (f *fakeAPI) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel { return f.updates }
 
 func (f *fakeAPI) StopReceivingUpdates() {}
 
 func (f *fakeAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
-	msg, ok := c.(tgbotapi.MessageConfig)
-	if !ok {
+	switch msg := c.(type) {
+	case tgbotapi.MessageConfig:
+		f.messages = append(f.messages, msg.Text)
+		f.markups = append(f.markups, msg.ReplyMarkup)
+	case tgbotapi.EditMessageTextConfig:
+		f.edits = append(f.edits, msg.Text)
+	case tgbotapi.DocumentConfig:
+		doc := fakeDocument{caption: msg.Caption}
+		switch file := msg.File.(type) {
+		case tgbotapi.FileBytes:
+			doc.name, doc.data = file.Name, file.Bytes
+		case tgbotapi.FileReader:
+			data, err := io.ReadAll(file.Reader)
+			if err != nil {
+				return tgbotapi.Message{}, err
+			}
+			doc.name, doc.data = file.Name, data
+		default:
+			return tgbotapi.Message{}, errors.New("unexpected document file type")
+		}
+		f.documents = append(f.documents, doc)
+	case tgbotapi.PhotoConfig:
+		file, ok := msg.File.(tgbotapi.FileBytes)
+		if !ok {
+			return tgbotapi.Message{}, errors.New("unexpected photo file type")
+		}
+		f.photos = append(f.photos, fakeDocument{name: file.Name, caption: msg.Caption, data: file.Bytes})
+	default:
 		return tgbotapi.Message{}, errors.New("unexpected chattable type")
 	}
-	f.messages = append(f.messages, msg.Text)
 	return tgbotapi.Message{}, nil
 }
 
+func (f *fakeAPI) GetFileDirectURL(fileID string) (string, error) {
+	if f.fileURL == "" {
+		return "", errors.New("no file server")
+	}
+	return f.fileURL + "/" + fileID, nil
+}
+
+func (f *fakeAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	callback, ok := c.(tgbotapi.CallbackConfig)
+	if !ok {
+		return nil, errors.New("unexpected chattable type")
+	}
+	f.callbacks = append(f.callbacks, callback.Text)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
 type allowAllAuthorizer struct{}
 
 func (allowAllAuthorizer) IsUserAllowed(string) bool { return true }
@@ -128,7 +220,7 @@ func TestHandleUpdateExtractorError(t *testing.T) {
 	if len(store.items) != 0 {
 		t.Fatalf("expected no items stored, got %d", len(store.items))
 	}
-	if len(api.messages) != 1 || api.messages[0] != "Error: extract failed" {
+	if len(api.messages) != 1 || api.messages[0] != extractor.UserMessage(extract.err, locale.Default()) {
 		t.Fatalf("unexpected messages %#v", api.messages)
 	}
 }
@@ -271,3 +363,133 @@ func TestHandleCommandStats(t *testing.T) {
 		t.Fatalf("expected category breakdown, got %q", api.messages[0])
 	}
 }
+
+func TestHandleUpdateAsksBeforeSavingDuplicate(t *testing.T) {
+	api := &fakeAPI{}
+	extract := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}}
+	store := &fakeStore{recent: []storage.Expense{{
+		ID:        123,
+		UserID:    7,
+		CreatedAt: time.Now().Add(-2 * time.Minute),
+		Item:      expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"},
+	}}}
+	b := New(api, allowAllAuthorizer{}, extract, store)
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 3.5"))
+
+	if len(store.items) != 0 {
+		t.Fatalf("expected duplicate to wait for confirmation, got %d stored", len(store.items))
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "duplicate of #123 recorded 2 minutes ago") {
+		t.Fatalf("unexpected prompt %#v", api.messages)
+	}
+	markup, ok := api.markups[0].(tgbotapi.InlineKeyboardMarkup)
+	if !ok || len(markup.InlineKeyboard) != 1 || len(markup.InlineKeyboard[0]) != 2 {
+		t.Fatalf("expected save/discard buttons, got %#v", api.markups[0])
+	}
+	saveData := *markup.InlineKeyboard[0][0].CallbackData
+
+	callback := tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb1",
+		From:    &tgbotapi.User{ID: 7, UserName: "iamoxyrus"},
+		Message: &tgbotapi.Message{MessageID: 10, Chat: &tgbotapi.Chat{ID: 1}},
+		Data:    saveData,
+	}}
+	b.handleUpdate(context.Background(), callback)
+
+	if len(store.items) != 1 || store.items[0].Description != "Latte" {
+		t.Fatalf("expected confirmed duplicate to be stored, got %#v", store.items)
+	}
+	if len(api.edits) != 1 || !strings.Contains(api.edits[0], "Recorded") {
+		t.Fatalf("expected prompt to be replaced with confirmation, got %#v", api.edits)
+	}
+
+	// The same button cannot save twice.
+	b.handleUpdate(context.Background(), callback)
+	if len(store.items) != 1 {
+		t.Fatalf("expected confirmation to be single-use, got %d stored", len(store.items))
+	}
+}
+
+func TestHandleUpdateFindsBackdatedDuplicate(t *testing.T) {
+	api := &fakeAPI{}
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	extract := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte", Date: date}}
+	store := &fakeStore{recent: []storage.Expense{{
+		ID:        42,
+		UserID:    7,
+		CreatedAt: date.Add(9 * time.Hour),
+		Item:      expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"},
+	}}}
+	b := New(api, allowAllAuthorizer{}, extract, store)
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 3.5 on march 14"))
+
+	if len(store.items) != 0 {
+		t.Fatalf("expected backdated duplicate to wait for confirmation, got %d stored", len(store.items))
+	}
+	if !store.exportFilter.Since.Equal(date) || !store.exportFilter.Until.Equal(date.AddDate(0, 0, 1)) {
+		t.Fatalf("expected the item's day to be searched, got %+v", store.exportFilter)
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "duplicate of #42 recorded on 2026-03-14") {
+		t.Fatalf("unexpected prompt %#v", api.messages)
+	}
+}
+
+func TestHandleUpdateDiscardsDuplicate(t *testing.T) {
+	api := &fakeAPI{}
+	extract := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}}
+	store := &fakeStore{recent: []storage.Expense{{ID: 5, CreatedAt: time.Now(), Item: expense.Item{Category: "Coffee", Amount: 3.5}}}}
+	b := New(api, allowAllAuthorizer{}, extract, store)
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 3.5"))
+	markup := api.markups[0].(tgbotapi.InlineKeyboardMarkup)
+
+	b.handleUpdate(context.Background(), tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb1",
+		From:    &tgbotapi.User{ID: 7, UserName: "iamoxyrus"},
+		Message: &tgbotapi.Message{MessageID: 10, Chat: &tgbotapi.Chat{ID: 1}},
+		Data:    *markup.InlineKeyboard[0][1].CallbackData,
+	}})
+
+	if len(store.items) != 0 {
+		t.Fatalf("expected duplicate to be discarded, got %#v", store.items)
+	}
+	if len(api.callbacks) != 1 || len(api.edits) != 1 {
+		t.Fatalf("expected callback answer and edit, got %#v %#v", api.callbacks, api.edits)
+	}
+}
+
+func TestHandleUpdateDifferentAmountIsNotDuplicate(t *testing.T) {
+	api := &fakeAPI{}
+	extract := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 4, Description: "Latte"}}
+	store := &fakeStore{recent: []storage.Expense{{ID: 5, CreatedAt: time.Now(), Item: expense.Item{Category: "Coffee", Amount: 3.5}}}}
+	b := New(api, allowAllAuthorizer{}, extract, store)
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 4"))
+
+	if len(store.items) != 1 {
+		t.Fatalf("expected expense to be stored directly, got %d", len(store.items))
+	}
+}
+
+func TestStartRecordsActivity(t *testing.T) {
+	api := &fakeAPI{updates: make(chan tgbotapi.Update, 1)}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{item: expense.Item{Category: "Food", Amount: 12}}, &fakeStore{})
+	if !b.LastActive().IsZero() {
+		t.Fatalf("expected no activity before Start, got %v", b.LastActive())
+	}
+
+	before := time.Now()
+	api.updates <- textUpdate("lunch 12")
+	close(api.updates)
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if len(api.messages) != 1 {
+		t.Fatalf("expected the update to be handled, got %#v", api.messages)
+	}
+	if b.LastActive().Before(before) {
+		t.Fatalf("expected activity after %v, got %v", before, b.LastActive())
+	}
+}
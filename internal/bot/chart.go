@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/chart"
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const (
+	defaultChartPeriod = "30d"
+	// Periods longer than this are charted by week instead of by day.
+	maxDailyBuckets = 31
+)
+
+func (b *Bot) handleChart(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	args := strings.Fields(strings.ToLower(msg.CommandArguments()))
+	if len(args) > 1 {
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.usage"))
+		return
+	}
+	period := defaultChartPeriod
+	if len(args) == 1 {
+		period = args[0]
+	}
+	now := settings.In(time.Now())
+	filter, label, err := parsePeriod(period, now)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.invalid", settings.Error(err), settings.T("chart.usage")))
+		return
+	}
+	filter.UserID = msg.From.ID
+
+	report, ok, err := b.buildChartReport(ctx, filter, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "build chart", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.load_failed", err))
+		return
+	}
+	if !ok {
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.empty", label))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := chart.Render(&buf, report); err != nil {
+		slog.ErrorContext(ctx, "render chart", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.render_failed"))
+		return
+	}
+	photo := tgbotapi.NewPhoto(msg.Chat.ID, tgbotapi.FileBytes{Name: fmt.Sprintf("chart-%s.png", label), Bytes: buf.Bytes()})
+	photo.Caption = report.Title
+	if _, err := b.send(ctx, photo); err != nil {
+		slog.ErrorContext(ctx, "send chart", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("chart.send_failed", err))
+	}
+}
+
+// buildChartReport totals expenses matching filter by category and by day, or by week
+// for periods longer than maxDailyBuckets days, with days and weeks following the user's
+// settings. It reports false when nothing matched.
+func (b *Bot) buildChartReport(ctx context.Context, filter storage.ExportFilter, now time.Time) (chart.Report, bool, error) {
+	filter.Kind = expense.KindExpense
+	var expenses []storage.Expense
+	err := b.store.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		expenses = append(expenses, e)
+		return nil
+	})
+	if err != nil || len(expenses) == 0 {
+		return chart.Report{}, false, err
+	}
+
+	settings := locale.From(ctx)
+	first := filter.Since
+	if first.IsZero() {
+		first = expenses[0].CreatedAt
+	}
+	first = settings.StartOfDay(first)
+	last := settings.StartOfDay(now)
+	if !filter.Until.IsZero() {
+		last = filter.Until.AddDate(0, 0, -1)
+	}
+	if last.Before(first) {
+		last = first
+	}
+	report := chart.Report{
+		Title:        settings.T("chart.title", first.Format("2006-01-02"), last.Format("2006-01-02")),
+		BucketTitle:  settings.T("chart.daily"),
+		Currency:     settings.Currency,
+		DecimalComma: settings.DecimalComma,
+		OtherLabel:   settings.T("chart.other"),
+	}
+
+	step, weekly := 1, false
+	if days := daysBetween(first, last) + 1; days > maxDailyBuckets {
+		step, weekly, report.BucketTitle = 7, true, settings.T("chart.weekly")
+		first = settings.StartOfWeek(first)
+	}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, step) {
+		label := day.Format("01-02")
+		if weekly {
+			label = settings.T("chart.week_label", shortMonth(settings, day.Month()), day.Day())
+		}
+		report.Buckets = append(report.Buckets, chart.Point{Label: label})
+	}
+
+	categories := map[string]float64{}
+	for _, e := range expenses {
+		categories[e.Category] += e.Amount
+		i := daysBetween(first, settings.StartOfDay(e.CreatedAt)) / step
+		if i >= 0 && i < len(report.Buckets) {
+			report.Buckets[i].Value += e.Amount
+		}
+	}
+	for name, total := range categories {
+		report.Categories = append(report.Categories, chart.Point{Label: settings.Category(name), Value: total})
+	}
+	return report, true, nil
+}
+
+// shortMonth abbreviates m to its first three letters in the user's language.
+func shortMonth(settings locale.Settings, m time.Month) string {
+	name := []rune(settings.Month(m))
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	return string(name)
+}
+
+// daysBetween counts the calendar days from one midnight to another, allowing for days
+// that daylight saving time makes 23 or 25 hours long.
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func chartExpense(day time.Time, category string, amount float64) storage.Expense {
+	return storage.Expense{CreatedAt: day, Item: expense.Item{Category: category, Amount: amount}}
+}
+
+func TestBuildChartReportDailyBuckets(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	store := &fakeStore{recent: []storage.Expense{
+		chartExpense(time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), "Food", 10),
+		chartExpense(time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC), "Coffee", 3),
+		chartExpense(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC), "Food", 7),
+	}}
+	b := New(&fakeAPI{}, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	filter, _, _ := parsePeriod("week", now)
+	report, ok, err := b.buildChartReport(context.Background(), filter, now)
+	if err != nil || !ok {
+		t.Fatalf("buildChartReport = %v, %v", ok, err)
+	}
+	if report.Title != "Spending 2026-10-12 to 2026-10-18" || report.BucketTitle != "Daily totals" {
+		t.Fatalf("unexpected titles %q / %q", report.Title, report.BucketTitle)
+	}
+	if len(report.Buckets) != 7 || report.Buckets[0].Label != "10-12" || report.Buckets[0].Value != 13 || report.Buckets[6].Value != 7 {
+		t.Fatalf("unexpected buckets %#v", report.Buckets)
+	}
+	if len(report.Categories) != 2 {
+		t.Fatalf("unexpected categories %#v", report.Categories)
+	}
+}
+
+func TestBuildChartReportWeeklyBuckets(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	store := &fakeStore{recent: []storage.Expense{
+		// Wednesday and the following Sunday fall in the same Monday-based week.
+		chartExpense(time.Date(2026, 8, 5, 9, 0, 0, 0, time.UTC), "Rent", 900),
+		chartExpense(time.Date(2026, 8, 9, 9, 0, 0, 0, time.UTC), "Food", 20),
+		chartExpense(time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), "Food", 30),
+	}}
+	b := New(&fakeAPI{}, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	report, ok, err := b.buildChartReport(context.Background(), storage.ExportFilter{}, now)
+	if err != nil || !ok {
+		t.Fatalf("buildChartReport = %v, %v", ok, err)
+	}
+	if report.BucketTitle != "Weekly totals" || report.Title != "Spending 2026-08-05 to 2026-10-18" {
+		t.Fatalf("unexpected titles %q / %q", report.Title, report.BucketTitle)
+	}
+	if report.Buckets[0].Label != "Aug 03" || report.Buckets[0].Value != 920 {
+		t.Fatalf("unexpected first bucket %#v", report.Buckets[0])
+	}
+	last := report.Buckets[len(report.Buckets)-1]
+	if last.Label != "Oct 12" || last.Value != 30 || len(report.Buckets) != 11 {
+		t.Fatalf("unexpected buckets %#v", report.Buckets)
+	}
+}
+
+func TestChartCommandSendsPhoto(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{recent: []storage.Expense{chartExpense(time.Now().UTC(), "Food", 12)}}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	b.handleUpdate(context.Background(), commandUpdate("/chart week"))
+
+	if len(api.photos) != 1 || len(api.messages) != 0 {
+		t.Fatalf("expected one photo, got %d photos and messages %#v", len(api.photos), api.messages)
+	}
+	photo := api.photos[0]
+	if photo.name != "chart-week.png" || !strings.HasPrefix(photo.caption, "Spending ") {
+		t.Fatalf("unexpected photo %q %q", photo.name, photo.caption)
+	}
+	if _, err := png.Decode(bytes.NewReader(photo.data)); err != nil {
+		t.Fatalf("expected a PNG, got %v", err)
+	}
+	if store.exportFilter.Since.IsZero() {
+		t.Fatalf("expected the week to bound the query, got %#v", store.exportFilter)
+	}
+}
+
+func TestChartCommandOnlyChartsTheSender(t *testing.T) {
+	api := &fakeAPI{}
+	store := memory.NewStore()
+	ctx := context.Background()
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 50, Description: "Someone else's dinner"}); err != nil {
+		t.Fatal(err)
+	}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	b.handleUpdate(ctx, commandUpdate("/chart week"))
+	if len(api.photos) != 0 || len(api.messages) != 1 || api.messages[0] != "No expenses recorded for week." {
+		t.Fatalf("expected another user's expenses to stay out of the chart, got %d photos and %#v", len(api.photos), api.messages)
+	}
+
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 12, Description: "Lunch"}); err != nil {
+		t.Fatal(err)
+	}
+	b.handleUpdate(ctx, commandUpdate("/chart week"))
+	if len(api.photos) != 1 {
+		t.Fatalf("expected the sender's chart, got %d photos and %#v", len(api.photos), api.messages)
+	}
+}
+
+func TestChartCommandRepliesWithoutData(t *testing.T) {
+	api := &fakeAPI{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{})
+
+	b.handleUpdate(context.Background(), commandUpdate("/chart"))
+	b.handleUpdate(context.Background(), commandUpdate("/chart fortnight"))
+
+	if len(api.photos) != 0 || len(api.messages) != 2 {
+		t.Fatalf("expected two text replies, got %#v", api.messages)
+	}
+	if api.messages[0] != "No expenses recorded for 30d." || !strings.Contains(api.messages[1], `unknown period "fortnight"`) {
+		t.Fatalf("unexpected replies %#v", api.messages)
+	}
+}
@@ -0,0 +1,32 @@
+package bot
+
+import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+// Commands are the commands the bot answers, as registered with Telegram.
+var Commands = []tgbotapi.BotCommand{
+	{Command: "add", Description: "Record a new expense"},
+	{Command: "stats", Description: "Show expense stats and trends"},
+	{Command: "pending", Description: "List or discard expenses waiting for a retry"},
+	{Command: "usage", Description: "Show LLM token usage and cost (admins only)"},
+	{Command: "chart", Description: "Show spending charts for a period"},
+	{Command: "export", Description: "Download expenses as CSV or JSON"},
+	{Command: "dashboard", Description: "Get a login link for the web dashboard"},
+	{Command: "token", Description: "Create, list or revoke API tokens"},
+	{Command: "ask", Description: "Ask a question about your spending"},
+	{Command: "tag", Description: "Add or remove tags on an expense"},
+	{Command: "accounts", Description: "Add or list payment accounts"},
+	{Command: "goals", Description: "Show savings goals and when you will reach them"},
+	{Command: "goal", Description: "Add a savings goal or log savings toward one"},
+	{Command: "settings", Description: "Set your time zone, currency and number format"},
+}
+
+// CommandLabel returns command if it is one of Commands and "unknown" otherwise, so
+// metrics and traces cannot grow a series per typo.
+func CommandLabel(command string) string {
+	for _, c := range Commands {
+		if c.Command == command {
+			return command
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+// DashboardLinker issues short-lived login links for the web dashboard.
+type DashboardLinker interface {
+	LoginURL(userID int64, username string) (string, time.Duration, error)
+}
+
+// WithDashboard enables the /dashboard command.
+func WithDashboard(linker DashboardLinker) Option {
+	return func(b *Bot) {
+		b.dashboard = linker
+	}
+}
+
+func (b *Bot) handleDashboard(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.dashboard == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("dashboard.disabled"))
+		return
+	}
+	// Anyone who sees the link can use it, so keep it out of group chats.
+	if !msg.Chat.IsPrivate() {
+		b.reply(ctx, msg.Chat.ID, settings.T("dashboard.private_only"))
+		return
+	}
+
+	link, ttl, err := b.dashboard.LoginURL(msg.From.ID, msg.From.UserName)
+	if err != nil {
+		slog.ErrorContext(ctx, "issue dashboard link", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("dashboard.failed"))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("dashboard.link", formatTTL(ttl, settings), link))
+}
+
+func formatTTL(d time.Duration, settings locale.Settings) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return settings.Plural("count.hours", int(d.Hours()))
+	}
+	return settings.Plural("count.minutes", int(d.Round(time.Minute).Minutes()))
+}
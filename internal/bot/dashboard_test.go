@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeLinker struct {
+	userID   int64
+	username string
+}
+
+func (f *fakeLinker) LoginURL(userID int64, username string) (string, time.Duration, error) {
+	f.userID, f.username = userID, username
+	return "https://finance.example/login?token=abc", 10 * time.Minute, nil
+}
+
+func TestDashboardCommandSendsLoginLink(t *testing.T) {
+	api := &fakeAPI{}
+	linker := &fakeLinker{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithDashboard(linker))
+
+	update := commandUpdate("/dashboard")
+	update.Message.Chat.Type = "private"
+	b.handleUpdate(context.Background(), update)
+
+	if linker.userID != 7 || linker.username != "iamoxyrus" {
+		t.Fatalf("expected link for the sender, got %#v", linker)
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "valid for 10 minutes") || !strings.HasSuffix(api.messages[0], "token=abc") {
+		t.Fatalf("unexpected reply %#v", api.messages)
+	}
+}
+
+func TestDashboardCommandRefusesGroupChats(t *testing.T) {
+	api := &fakeAPI{}
+	linker := &fakeLinker{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithDashboard(linker))
+
+	update := commandUpdate("/dashboard")
+	update.Message.Chat.Type = "group"
+	b.handleUpdate(context.Background(), update)
+
+	if linker.userID != 0 || len(api.messages) != 1 || !strings.Contains(api.messages[0], "private chat") {
+		t.Fatalf("expected the link to be withheld, got %#v", api.messages)
+	}
+}
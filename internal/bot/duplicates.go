@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/reqctx"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const (
+	duplicateWindow = 10 * time.Minute
+	confirmationTTL = 15 * time.Minute
+	callbackSaveDup = "dup:save:"
+	callbackSkipDup = "dup:skip:"
+)
+
+// confirmations holds expenses waiting for the user to confirm a suspected duplicate.
+type confirmations struct {
+	mu    sync.Mutex
+	seq   int64
+	items map[string]pendingConfirmation
+}
+
+type pendingConfirmation struct {
+	userID  int64
+	item    expense.Item
+	expires time.Time
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{items: make(map[string]pendingConfirmation)}
+}
+
+func (c *confirmations) add(p pendingConfirmation, now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for token, existing := range c.items {
+		if now.After(existing.expires) {
+			delete(c.items, token)
+		}
+	}
+	c.seq++
+	token := strconv.FormatInt(c.seq, 10)
+	c.items[token] = p
+	return token
+}
+
+// take removes and returns a confirmation if it exists, belongs to userID and has not expired.
+func (c *confirmations) take(token string, userID int64, now time.Time) (pendingConfirmation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.items[token]
+	if !ok || p.userID != userID {
+		return pendingConfirmation{}, false
+	}
+	delete(c.items, token)
+	if now.After(p.expires) {
+		return pendingConfirmation{}, false
+	}
+	return p, true
+}
+
+// findDuplicate looks for an entry by the same user of the same kind and amount and with
+// either the same category or description. Entries without a date are compared with those
+// recorded in the last few minutes; backdated ones with the rest of that local day.
+func (b *Bot) findDuplicate(ctx context.Context, userID int64, item expense.Item) (storage.Expense, bool) {
+	var candidates []storage.Expense
+	if item.Date.IsZero() {
+		recent, err := b.store.RecentExpenses(ctx, userID, time.Now().Add(-duplicateWindow))
+		if err != nil {
+			slog.WarnContext(ctx, "load recent expenses", "err", err)
+			return storage.Expense{}, false
+		}
+		candidates = recent
+	} else {
+		day := locale.From(ctx).StartOfDay(item.Date)
+		filter := storage.ExportFilter{UserID: userID, Since: day, Until: day.AddDate(0, 0, 1)}
+		err := b.store.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+			candidates = append(candidates, e)
+			return nil
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "load expenses for date", "err", err)
+			return storage.Expense{}, false
+		}
+	}
+	for _, e := range candidates {
+		if e.Kind.Sign() != item.Kind.Sign() || math.Abs(e.Amount-item.Amount) > 0.005 {
+			continue
+		}
+		if strings.EqualFold(e.Category, item.Category) || strings.EqualFold(e.Description, item.Description) {
+			return e, true
+		}
+	}
+	return storage.Expense{}, false
+}
+
+func (b *Bot) askDuplicate(ctx context.Context, chatID, userID int64, item expense.Item, dup storage.Expense) {
+	now := time.Now()
+	token := b.confirms.add(pendingConfirmation{
+		userID:  userID,
+		item:    item,
+		expires: now.Add(confirmationTTL),
+	}, now)
+
+	settings := locale.From(ctx)
+	msg := tgbotapi.NewMessage(chatID, settings.T("duplicate.prompt",
+		dup.ID, recordedWhen(now, dup.CreatedAt, settings), settings.Category(item.Category), settings.Money(item.Amount), item.Description))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(settings.T("duplicate.save_button"), callbackSaveDup+token),
+		tgbotapi.NewInlineKeyboardButtonData(settings.T("duplicate.discard_button"), callbackSkipDup+token),
+	))
+	if _, err := b.send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send message", "err", err)
+	}
+}
+
+func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.From == nil || query.From.UserName == "" || !b.authorizer.IsUserAllowed(query.From.UserName) {
+		return
+	}
+	ctx = reqctx.WithUser(ctx, reqctx.User{ID: query.From.ID, Username: query.From.UserName})
+	ctx = b.withUserSettings(ctx, query.From.ID)
+	settings := locale.From(ctx)
+
+	var (
+		save  bool
+		token string
+	)
+	switch {
+	case strings.HasPrefix(query.Data, callbackSaveDup):
+		save, token = true, strings.TrimPrefix(query.Data, callbackSaveDup)
+	case strings.HasPrefix(query.Data, callbackSkipDup):
+		token = strings.TrimPrefix(query.Data, callbackSkipDup)
+	default:
+		b.answerCallback(ctx, query.ID, "")
+		return
+	}
+
+	p, ok := b.confirms.take(token, query.From.ID, time.Now())
+	if !ok {
+		b.answerCallback(ctx, query.ID, settings.T("duplicate.expired_short"))
+		b.editCallbackMessage(ctx, query, settings.T("duplicate.expired"))
+		return
+	}
+
+	if !save {
+		b.answerCallback(ctx, query.ID, settings.T("duplicate.discarded_short"))
+		b.editCallbackMessage(ctx, query, settings.T("duplicate.discarded"))
+		return
+	}
+
+	id, err := b.store.SaveExpense(ctx, p.userID, p.item)
+	if err != nil {
+		slog.ErrorContext(ctx, "store confirmed expense", "err", err)
+		b.answerCallback(ctx, query.ID, settings.T("duplicate.failed_short"))
+		b.editCallbackMessage(ctx, query, settings.T("expense.store_failed", err))
+		return
+	}
+	b.answerCallback(ctx, query.ID, settings.T("duplicate.saved_short"))
+	b.editCallbackMessage(ctx, query, recordedReply(id, p.item, settings))
+}
+
+func (b *Bot) answerCallback(ctx context.Context, id, text string) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(id, text)); err != nil {
+		slog.ErrorContext(ctx, "failed to answer callback", "err", err)
+	}
+}
+
+// editCallbackMessage replaces the prompt (and its buttons) with the outcome.
+func (b *Bot) editCallbackMessage(ctx context.Context, query *tgbotapi.CallbackQuery, text string) {
+	if query.Message == nil || query.Message.Chat == nil {
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	if _, err := b.send(ctx, edit); err != nil {
+		slog.ErrorContext(ctx, "failed to edit message", "err", err)
+	}
+}
+
+// recordedWhen describes when a suspected duplicate was recorded: minutes ago for recent
+// entries and the local date for older, backdated ones.
+func recordedWhen(now, createdAt time.Time, settings locale.Settings) string {
+	if now.Sub(createdAt) > time.Hour {
+		return settings.T("ago.on_date", settings.Date(createdAt))
+	}
+	return humanizeAgo(now.Sub(createdAt), settings)
+}
+
+func humanizeAgo(d time.Duration, settings locale.Settings) string {
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		return settings.T("ago.just_now")
+	}
+	return settings.Plural("ago.minutes", minutes)
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/export"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var (
+	exportDaysPattern  = regexp.MustCompile(`^(\d{1,4})d$`)
+	exportMonthPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+)
+
+// exportRequest is a parsed /export command.
+type exportRequest struct {
+	format export.Format
+	filter storage.ExportFilter
+	label  string
+}
+
+func (b *Bot) handleExport(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	req, err := parseExportArgs(msg.CommandArguments(), settings.In(time.Now()))
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("export.invalid", settings.Error(err), settings.T("export.usage")))
+		return
+	}
+	req.filter.UserID = msg.From.ID
+
+	// Stream straight from the store into the upload instead of buffering the whole file.
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := export.Write(ctx, b.store, writer, req.format, req.filter)
+		writer.CloseWithError(err)
+	}()
+
+	name := fmt.Sprintf("expenses-%s%s", req.label, req.format.Extension())
+	doc := tgbotapi.NewDocument(msg.Chat.ID, tgbotapi.FileReader{Name: name, Reader: reader})
+	doc.Caption = settings.T("export.caption", req.label)
+	_, err = b.send(ctx, doc)
+	// Unblock the writer if the upload gave up before reading everything.
+	reader.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		slog.ErrorContext(ctx, "send export", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("export.failed", err))
+	}
+}
+
+// parseExportArgs reads an optional period and format in any order. The period defaults
+// to all expenses and the format to CSV.
+func parseExportArgs(args string, now time.Time) (exportRequest, error) {
+	req := exportRequest{format: export.CSV, label: "all"}
+	periodSeen := false
+
+	for _, arg := range strings.Fields(strings.ToLower(args)) {
+		if format, err := export.ParseFormat(arg); err == nil {
+			req.format = format
+			continue
+		}
+		if periodSeen {
+			return exportRequest{}, locale.Errorf("period.err.unexpected", arg)
+		}
+		periodSeen = true
+
+		filter, label, err := parsePeriod(arg, now)
+		if err != nil {
+			return exportRequest{}, err
+		}
+		req.filter, req.label = filter, label
+	}
+	return req, nil
+}
+
+// parsePeriod turns a period argument into a date filter and a label for file names and
+// captions. "all" leaves the filter open. Days start at midnight in now's location.
+func parsePeriod(arg string, now time.Time) (storage.ExportFilter, string, error) {
+	var filter storage.ExportFilter
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case arg == "all":
+		return filter, "all", nil
+	case arg == "week":
+		filter.Since = today.AddDate(0, 0, -6)
+		return filter, "week", nil
+	case arg == "month":
+		filter.Since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return filter, filter.Since.Format("2006-01"), nil
+	case arg == "year":
+		filter.Since = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		return filter, filter.Since.Format("2006"), nil
+	case exportDaysPattern.MatchString(arg):
+		days, _ := strconv.Atoi(strings.TrimSuffix(arg, "d"))
+		if days < 1 {
+			return filter, "", locale.Errorf("period.err.days", arg)
+		}
+		filter.Since = today.AddDate(0, 0, -(days - 1))
+		return filter, arg, nil
+	case exportMonthPattern.MatchString(arg):
+		month, err := time.ParseInLocation("2006-01", arg, now.Location())
+		if err != nil {
+			return filter, "", locale.Errorf("period.err.month", arg)
+		}
+		filter.Since = month
+		filter.Until = month.AddDate(0, 1, 0)
+		return filter, arg, nil
+	default:
+		return filter, "", locale.Errorf("period.err.unknown", arg)
+	}
+}
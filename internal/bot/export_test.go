@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/export"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestExportCommandSendsCSVDocument(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{recent: []storage.Expense{
+		{ID: 1, UserID: 7, CreatedAt: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), Item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}},
+		{ID: 2, UserID: 7, CreatedAt: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC), Item: expense.Item{Category: "Food", Amount: 12, Description: "Lunch"}},
+	}}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	b.handleUpdate(context.Background(), commandUpdate("/export"))
+
+	if len(api.messages) != 0 || len(api.documents) != 1 {
+		t.Fatalf("expected a single document, got messages %#v and %d documents", api.messages, len(api.documents))
+	}
+	doc := api.documents[0]
+	if doc.name != "expenses-all.csv" {
+		t.Fatalf("unexpected file name %q", doc.name)
+	}
+	want := "id,date,user_id,category,amount,description,type,account\n" +
+		"1,2026-10-01T08:00:00Z,7,Coffee,3.5,Latte,expense,\n" +
+		"2,2026-10-02T12:00:00Z,7,Food,12,Lunch,expense,\n"
+	if string(doc.data) != want {
+		t.Fatalf("unexpected CSV:\n%s", doc.data)
+	}
+	if store.exportFilter != (storage.ExportFilter{UserID: 7}) {
+		t.Fatalf("expected an unbounded export of the sender's expenses, got %#v", store.exportFilter)
+	}
+}
+
+func TestExportCommandSendsJSONForMonth(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{recent: []storage.Expense{
+		{ID: 1, UserID: 7, CreatedAt: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC), Item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}},
+	}}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	b.handleUpdate(context.Background(), commandUpdate("/export json 2026-09"))
+
+	if len(api.documents) != 1 || api.documents[0].name != "expenses-2026-09.json" {
+		t.Fatalf("expected a JSON document, got %#v", api.documents)
+	}
+	var records []export.Record
+	if err := json.Unmarshal(api.documents[0].data, &records); err != nil || len(records) != 1 {
+		t.Fatalf("expected one JSON record, got %v (%s)", err, api.documents[0].data)
+	}
+	wantSince := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if !store.exportFilter.Since.Equal(wantSince) || !store.exportFilter.Until.Equal(wantSince.AddDate(0, 1, 0)) {
+		t.Fatalf("unexpected filter %#v", store.exportFilter)
+	}
+}
+
+func TestExportCommandRejectsUnknownPeriod(t *testing.T) {
+	api := &fakeAPI{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{})
+
+	b.handleUpdate(context.Background(), commandUpdate("/export fortnight"))
+
+	if len(api.documents) != 0 || len(api.messages) != 1 || !strings.Contains(api.messages[0], "Usage: /export") {
+		t.Fatalf("expected usage message, got %#v", api.messages)
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		since  time.Time
+		format export.Format
+		label  string
+	}{
+		"":           {format: export.CSV, label: "all"},
+		"all json":   {format: export.JSON, label: "all"},
+		"week":       {since: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), format: export.CSV, label: "week"},
+		"JSON month": {since: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), format: export.JSON, label: "2026-10"},
+		"year":       {since: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), format: export.CSV, label: "2026"},
+		"30d csv":    {since: time.Date(2026, 9, 19, 0, 0, 0, 0, time.UTC), format: export.CSV, label: "30d"},
+	}
+	for args, want := range cases {
+		got, err := parseExportArgs(args, now)
+		if err != nil {
+			t.Fatalf("parseExportArgs(%q) error: %v", args, err)
+		}
+		if !got.filter.Since.Equal(want.since) || got.format != want.format || got.label != want.label {
+			t.Errorf("parseExportArgs(%q) = %+v, want %+v", args, got, want)
+		}
+	}
+
+	for _, args := range []string{"0d", "month week", "2026-13", "xlsx"} {
+		if _, err := parseExportArgs(args, now); err == nil {
+			t.Errorf("parseExportArgs(%q): expected error", args)
+		}
+	}
+}
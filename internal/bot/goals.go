@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const maxGoalNameLength = 40
+
+// WithGoals enables the /goal and /goals commands and the goal progress in /stats.
+func WithGoals(store storage.GoalStore) Option {
+	return func(b *Bot) {
+		b.goals = store
+	}
+}
+
+func (b *Bot) handleGoal(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.goals == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.disabled"))
+		return
+	}
+	args, err := splitQuoted(msg.CommandArguments())
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(err), settings.T("goals.usage")))
+		return
+	}
+	action := "list"
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+	switch {
+	case action == "list" && len(args) <= 1:
+		b.listGoals(ctx, msg)
+	case action == "add" && len(args) >= 3:
+		b.addGoal(ctx, msg, args[1:])
+	case action == "save" && len(args) == 3:
+		b.contributeToGoal(ctx, msg, args[1], args[2])
+	case action == "delete" && len(args) == 2:
+		b.deleteGoal(ctx, msg, args[1])
+	default:
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.usage"))
+	}
+}
+
+func (b *Bot) addGoal(ctx context.Context, msg *tgbotapi.Message, args []string) {
+	settings := locale.From(ctx)
+	goal, err := parseGoalArgs(args, settings.In(time.Now()), settings)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(err), settings.T("goals.usage")))
+		return
+	}
+	goal.UserID = msg.From.ID
+
+	_, err = b.goals.CreateGoal(ctx, goal)
+	if errors.Is(err, storage.ErrExists) {
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.exists", goal.Name))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "create goal", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.add_failed"))
+		return
+	}
+	reply := settings.T("goals.added", goal.Name, settings.Money(goal.Target))
+	if !goal.Deadline.IsZero() {
+		reply += settings.T("goals.added_by", goal.Deadline.Format("2006-01-02"))
+	}
+	b.reply(ctx, msg.Chat.ID, reply+settings.T("goals.added_hint", goal.Name))
+}
+
+// parseGoalArgs reads "<name> <target> [by YYYY-MM[-DD]]" from /goal add arguments. A
+// month deadline means its last day. Deadlines are calendar dates, kept at midnight UTC;
+// now is in the user's time zone.
+func parseGoalArgs(args []string, now time.Time, settings locale.Settings) (storage.Goal, error) {
+	goal := storage.Goal{Name: strings.Join(strings.Fields(args[0]), " ")}
+	if goal.Name == "" || utf8.RuneCountInString(goal.Name) > maxGoalNameLength {
+		return storage.Goal{}, locale.Errorf("goals.err.name_length", maxGoalNameLength)
+	}
+	target, err := parseMoney(args[1], settings)
+	if err != nil || target <= 0 {
+		return storage.Goal{}, locale.Errorf("goals.err.target", args[1])
+	}
+	goal.Target = target
+
+	rest := args[2:]
+	if len(rest) == 0 {
+		return goal, nil
+	}
+	if len(rest) != 2 || !(strings.EqualFold(rest[0], "by") || strings.EqualFold(rest[0], "hasta")) {
+		return storage.Goal{}, locale.Errorf("goals.err.quotes")
+	}
+	if deadline, err := time.Parse("2006-01-02", rest[1]); err == nil {
+		goal.Deadline = deadline
+	} else if month, err := time.Parse("2006-01", rest[1]); err == nil {
+		goal.Deadline = month.AddDate(0, 1, -1)
+	} else {
+		return storage.Goal{}, locale.Errorf("goals.err.date", rest[1])
+	}
+	if goal.Deadline.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		return storage.Goal{}, locale.Errorf("goals.err.past")
+	}
+	return goal, nil
+}
+
+func (b *Bot) contributeToGoal(ctx context.Context, msg *tgbotapi.Message, name, rawAmount string) {
+	settings := locale.From(ctx)
+	amount, err := parseMoney(rawAmount, settings)
+	if err != nil || amount == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.T("goals.err.amount", rawAmount), settings.T("goals.usage")))
+		return
+	}
+	err = b.goals.ContributeToGoal(ctx, msg.From.ID, name, amount, time.Now())
+	if errors.Is(err, storage.ErrNotFound) {
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.not_found", name))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "contribute to goal", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.contribute_failed"))
+		return
+	}
+
+	goals, err := b.goals.GoalProgress(ctx, msg.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "load goal progress", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.logged", settings.Money(amount), name))
+		return
+	}
+	for _, g := range goals {
+		if strings.EqualFold(g.Name, name) {
+			b.reply(ctx, msg.Chat.ID, settings.T("goals.logged_progress", settings.Money(amount), g.Name, formatGoal(g, time.Now(), settings)))
+			return
+		}
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("goals.logged", settings.Money(amount), name))
+}
+
+func (b *Bot) deleteGoal(ctx context.Context, msg *tgbotapi.Message, name string) {
+	settings := locale.From(ctx)
+	err := b.goals.DeleteGoal(ctx, msg.From.ID, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.not_found", name))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "delete goal", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.delete_failed"))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("goals.deleted", name))
+}
+
+func (b *Bot) listGoals(ctx context.Context, msg *tgbotapi.Message) {
+	now, settings := time.Now(), locale.From(ctx)
+	goals, err := b.goals.GoalProgress(ctx, msg.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "load goal progress", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.load_failed"))
+		return
+	}
+	if len(goals) == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("goals.none"))
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(settings.T("goals.list_heading"))
+	for _, g := range goals {
+		builder.WriteString(formatGoal(g, now, settings))
+		builder.WriteString("\n")
+	}
+	b.reply(ctx, msg.Chat.ID, strings.TrimRight(builder.String(), "\n"))
+}
+
+// formatGoal describes a goal's progress and, when there is a pace to go by, when it
+// should be reached.
+func formatGoal(g storage.GoalProgress, now time.Time, settings locale.Settings) string {
+	p := analytics.ProjectGoal(g, now)
+	line := settings.T("goals.progress", g.Name, settings.Money(g.Saved), settings.Money(g.Target), p.Percent*100)
+	if !g.Deadline.IsZero() {
+		line += settings.T("goals.due", g.Deadline.Format("2006-01-02"))
+	}
+	switch {
+	case p.Reached:
+		return line + settings.T("goals.reached")
+	case p.Completion.IsZero():
+		return line + settings.T("goals.no_savings")
+	case g.Deadline.IsZero():
+		return line + settings.T("goals.pace", settings.Date(p.Completion))
+	case p.OnTrack:
+		return line + settings.T("goals.pace_on_track", settings.Date(p.Completion))
+	default:
+		return line + settings.T("goals.pace_behind", settings.Date(p.Completion))
+	}
+}
+
+// goalSection summarizes the user's goals for /stats, or returns "" when goals are
+// disabled or the user has none.
+func (b *Bot) goalSection(ctx context.Context, userID int64) string {
+	if b.goals == nil {
+		return ""
+	}
+	goals, err := b.goals.GoalProgress(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "load goal progress", "err", err)
+		return ""
+	}
+	if len(goals) == 0 {
+		return ""
+	}
+	settings := locale.From(ctx)
+	var builder strings.Builder
+	builder.WriteString(settings.T("goals.section_heading"))
+	for _, g := range goals {
+		p := analytics.ProjectGoal(g, time.Now())
+		builder.WriteString(settings.T("goals.section_item", g.Name, p.Percent*100, settings.Money(g.Saved), settings.Money(g.Target)))
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// parseMoney reads an amount typed with or without the user's currency symbol or "$".
+func parseMoney(raw string, settings locale.Settings) (float64, error) {
+	for _, symbol := range []string{settings.Currency, "$"} {
+		if symbol != "" {
+			raw = strings.Replace(strings.TrimPrefix(raw, symbol), "-"+symbol, "-", 1)
+		}
+	}
+	return settings.ParseAmount(raw)
+}
+
+// splitQuoted splits command arguments on spaces, keeping "quoted phrases" (including
+// the curly quotes some keyboards insert) together.
+func splitQuoted(args string) ([]string, error) {
+	var (
+		fields  []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+	for _, r := range args {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if started {
+				fields = append(fields, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, locale.Errorf("goals.err.unclosed_quote")
+	}
+	if started {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
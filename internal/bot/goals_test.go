@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func TestGoalCommands(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, store, WithGoals(store))
+	ctx := context.Background()
+	next := time.Now().AddDate(1, 0, 0).Format("2006-01")
+
+	for _, text := range []string{
+		`/goal add "Japan trip" 3000 by ` + next,
+		`/goal add “japan trip” 10`,
+		`/goal add Japan trip 3000`,
+		`/goal add Laptop 1500 by 2020-01`,
+		`/goal add Laptop Inf`,
+		`/goal save "Japan trip" NaN`,
+		`/goal save "Japan trip" 600`,
+		`/goal save Laptop 50`,
+		`/goal delete "Japan trip`,
+		`/goals`,
+	} {
+		b.handleUpdate(ctx, commandUpdate(text))
+	}
+
+	want := []string{
+		`Added goal "Japan trip": save $3000.00 by ` + next,
+		`You already have a goal named "japan trip".`,
+		`Sorry, "trip" is not a target amount.`,
+		"Sorry, the deadline has already passed.",
+		`Sorry, "Inf" is not a target amount.`,
+		`Sorry, "NaN" is not`,
+		"Logged $600.00 toward Japan trip.\n- Japan trip: $600.00 of $3000.00 (20%), due " + next,
+		`You have no goal named "Laptop".`,
+		"Sorry, a quote is missing its closing mark.",
+		"Your goals:\n- Japan trip: $600.00 of $3000.00 (20%)",
+	}
+	if len(fake.messages) != len(want) {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(fake.messages[i], w) {
+			t.Errorf("reply %d: expected prefix %q, got %q", i, w, fake.messages[i])
+		}
+	}
+}
+
+func TestFormatGoalProjectsCompletion(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	goal := storage.GoalProgress{
+		Goal:  storage.Goal{Name: "Japan trip", Target: 3000, Deadline: time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC), CreatedAt: now.AddDate(0, 0, -30)},
+		Saved: 600,
+	}
+	want := "- Japan trip: $600.00 of $3000.00 (20%), due 2027-04-30\n  On pace for 2027-02-15 (on track)"
+	if got := formatGoal(goal, now, locale.Default()); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	goal.Saved = 100
+	if got := formatGoal(goal, now, locale.Default()); !strings.HasSuffix(got, "(behind schedule)") {
+		t.Fatalf("expected a slow pace to be behind schedule, got %q", got)
+	}
+}
+
+func TestStatsShowsGoalProgress(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	ctx := context.Background()
+	if _, err := store.CreateGoal(ctx, storage.Goal{UserID: 7, Name: "Laptop", Target: 1500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ContributeToGoal(ctx, 7, "laptop", 375, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	stats := &fakeStore{stats: storage.Summary{TotalCount: 1, TotalAmount: 5, CategoryTotals: map[string]float64{"Coffee": 5}}}
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, stats, WithGoals(store))
+
+	b.handleUpdate(ctx, commandUpdate("/stats"))
+
+	if !strings.HasSuffix(fake.messages[0], "Goals:\n- Laptop: 25% ($375.00 of $1500.00)") {
+		t.Fatalf("expected goal progress in /stats, got %q", fake.messages[0])
+	}
+}
+
+func TestSplitQuoted(t *testing.T) {
+	got, err := splitQuoted(`add  "Japan trip" 3000 by 2027-04`)
+	if err != nil || strings.Join(got, "|") != "add|Japan trip|3000|by|2027-04" {
+		t.Fatalf("unexpected fields %q (%v)", got, err)
+	}
+}
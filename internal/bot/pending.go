@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/reqctx"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const (
+	pendingPollInterval = 30 * time.Second
+	pendingBaseDelay    = time.Minute
+	pendingMaxDelay     = time.Hour
+	pendingBatchSize    = 10
+	maxPendingAttempts  = 10
+)
+
+// enqueuePending parks a failed message in the retry queue and tells the user about it.
+// It reports false when no queue is configured or the message could not be queued.
+func (b *Bot) enqueuePending(ctx context.Context, msg *tgbotapi.Message, reason string) bool {
+	if b.pending == nil {
+		return false
+	}
+
+	id, err := b.pending.EnqueuePending(ctx, storage.PendingExpense{
+		ChatID:      msg.Chat.ID,
+		UserID:      msg.From.ID,
+		Username:    msg.From.UserName,
+		Text:        msg.Text,
+		Attempts:    1,
+		LastError:   reason,
+		NextAttempt: time.Now().Add(pendingBaseDelay),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "enqueue pending expense", "err", err)
+		return false
+	}
+
+	b.reply(ctx, msg.Chat.ID, locale.From(ctx).T("pending.queued", reason, id))
+	return true
+}
+
+func (b *Bot) runPendingRetries(ctx context.Context) {
+	ticker := time.NewTicker(pendingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b.retryPending(ctx, now)
+		}
+	}
+}
+
+// retryPending re-processes every queued message that is due at now.
+func (b *Bot) retryPending(ctx context.Context, now time.Time) {
+	due, err := b.pending.DuePending(ctx, now, pendingBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "load pending expenses", "err", err)
+		return
+	}
+
+	for _, p := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		b.retryOne(ctx, p, now)
+	}
+}
+
+func (b *Bot) retryOne(ctx context.Context, p storage.PendingExpense, now time.Time) {
+	ctx = reqctx.WithRequest(ctx, reqctx.Request{ID: reqctx.NewRequestID(), ChatID: p.ChatID})
+	ctx = reqctx.WithUser(ctx, reqctx.User{ID: p.UserID, Username: p.Username})
+	ctx = b.withUserSettings(ctx, p.UserID)
+	settings := locale.From(ctx)
+	item, err := b.extract(ctx, p.Text)
+	if err != nil {
+		slog.WarnContext(ctx, "retry pending extract", "pending_id", p.ID, "reason", extractor.Reason(err), "err", err)
+		b.reschedulePending(ctx, p, now, extractor.UserMessage(err, settings), extractor.IsRetryable(err))
+		return
+	}
+
+	id, err := b.store.SaveExpense(ctx, p.UserID, item)
+	if err != nil {
+		slog.WarnContext(ctx, "retry pending store", "pending_id", p.ID, "err", err)
+		b.reschedulePending(ctx, p, now, settings.T("pending.store_failed"), !errors.Is(err, storage.ErrInvalidExpense))
+		return
+	}
+
+	if err := b.pending.DeletePending(ctx, p.ID); err != nil {
+		slog.ErrorContext(ctx, "delete pending", "pending_id", p.ID, "err", err)
+	}
+	b.reply(ctx, p.ChatID, settings.T("pending.recorded", p.ID, p.Text, recordedReply(id, item, settings)))
+}
+
+func (b *Bot) reschedulePending(ctx context.Context, p storage.PendingExpense, now time.Time, reason string, retryable bool) {
+	attempts := p.Attempts + 1
+	if !retryable || attempts >= maxPendingAttempts {
+		if err := b.pending.ReschedulePending(ctx, p.ID, time.Time{}, reason); err != nil {
+			slog.ErrorContext(ctx, "park pending", "pending_id", p.ID, "err", err)
+			return
+		}
+		b.reply(ctx, p.ChatID, locale.From(ctx).T("pending.gave_up", p.ID, p.Text, attempts, reason))
+		return
+	}
+
+	if err := b.pending.ReschedulePending(ctx, p.ID, now.Add(pendingBackoff(attempts)), reason); err != nil {
+		slog.ErrorContext(ctx, "reschedule pending", "pending_id", p.ID, "err", err)
+	}
+}
+
+// pendingBackoff doubles the delay for every attempt, capped at pendingMaxDelay.
+func pendingBackoff(attempts int) time.Duration {
+	delay := pendingBaseDelay
+	for i := 1; i < attempts && delay < pendingMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > pendingMaxDelay {
+		return pendingMaxDelay
+	}
+	return delay
+}
+
+func (b *Bot) handlePending(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.pending == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("pending.disabled"))
+		return
+	}
+
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		b.listPending(ctx, msg.Chat.ID)
+		return
+	}
+	if args[0] != "discard" || len(args) != 2 {
+		b.reply(ctx, msg.Chat.ID, settings.T("pending.usage"))
+		return
+	}
+
+	items, err := b.pending.ListPending(ctx, msg.Chat.ID)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("pending.load_failed", err))
+		return
+	}
+
+	target := strings.TrimPrefix(args[1], "#")
+	discarded := 0
+	for _, p := range items {
+		if target != "all" && target != strconv.FormatInt(p.ID, 10) {
+			continue
+		}
+		if err := b.pending.DeletePending(ctx, p.ID); err != nil {
+			b.reply(ctx, msg.Chat.ID, settings.T("pending.discard_failed", p.ID, err))
+			return
+		}
+		discarded++
+	}
+
+	if discarded == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("pending.no_match", args[1]))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.Plural("pending.discarded", discarded))
+}
+
+func (b *Bot) listPending(ctx context.Context, chatID int64) {
+	settings := locale.From(ctx)
+	items, err := b.pending.ListPending(ctx, chatID)
+	if err != nil {
+		b.reply(ctx, chatID, settings.T("pending.load_failed", err))
+		return
+	}
+	if len(items) == 0 {
+		b.reply(ctx, chatID, settings.T("pending.none"))
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(settings.T("pending.list_heading"))
+	for _, p := range items {
+		status := settings.T("pending.next_retry", settings.DateTime(p.NextAttempt))
+		if p.NextAttempt.IsZero() {
+			status = settings.T("pending.stuck")
+		}
+		builder.WriteString(settings.T("pending.list_item", p.ID, p.Text, settings.Plural("count.attempts", p.Attempts), status, p.LastError))
+	}
+	builder.WriteString(settings.T("pending.list_footer"))
+	b.reply(ctx, chatID, builder.String())
+}
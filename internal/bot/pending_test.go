@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func textUpdate(text string) tgbotapi.Update {
+	return tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 7, UserName: "iamoxyrus"},
+			Chat: &tgbotapi.Chat{ID: 1},
+			Text: text,
+		},
+	}
+}
+
+func commandUpdate(text string) tgbotapi.Update {
+	update := textUpdate(text)
+	command, _, _ := strings.Cut(text, " ")
+	update.Message.Entities = []tgbotapi.MessageEntity{{Offset: 0, Length: len(command), Type: "bot_command"}}
+	return update
+}
+
+func TestProcessExpenseQueuesTransientExtractorFailure(t *testing.T) {
+	api := &fakeAPI{}
+	extract := &fakeExtractor{err: &openai.APIError{HTTPStatusCode: 503, Message: "unavailable"}}
+	store := &fakeStore{}
+	queue := memory.NewStore()
+	b := New(api, allowAllAuthorizer{}, extract, store, WithPendingQueue(queue))
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 3.5"))
+
+	items, _ := queue.ListPending(context.Background(), 1)
+	if len(items) != 1 || items[0].Text != "coffee 3.5" || items[0].UserID != 7 {
+		t.Fatalf("expected message to be queued, got %#v", items)
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "pending #1") {
+		t.Fatalf("expected queue notice, got %#v", api.messages)
+	}
+}
+
+func TestProcessExpenseDoesNotQueuePermanentFailure(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{err: errors.New("bad input")}, &fakeStore{}, WithPendingQueue(queue))
+
+	b.handleUpdate(context.Background(), textUpdate("hello"))
+
+	if items, _ := queue.ListPending(context.Background(), 1); len(items) != 0 {
+		t.Fatalf("expected nothing queued, got %#v", items)
+	}
+}
+
+func TestProcessExpenseQueuesStoreFailure(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	extract := &fakeExtractor{item: expense.Item{Category: "Travel", Amount: 42, Description: "Taxi"}}
+	b := New(api, allowAllAuthorizer{}, extract, &fakeStore{err: errors.New("db locked")}, WithPendingQueue(queue))
+
+	b.handleUpdate(context.Background(), textUpdate("taxi 42"))
+
+	if items, _ := queue.ListPending(context.Background(), 1); len(items) != 1 {
+		t.Fatalf("expected store failure to be queued, got %#v", items)
+	}
+	if strings.Contains(api.messages[0], "db locked") {
+		t.Fatalf("expected raw store error to stay out of the reply, got %q", api.messages[0])
+	}
+}
+
+func TestProcessExpenseDoesNotQueueInvalidExpense(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	extract := &fakeExtractor{item: expense.Item{Category: "Travel", Amount: 42, Description: "Taxi"}}
+	store := &fakeStore{err: fmt.Errorf("sqlite: %w: description cannot be empty", storage.ErrInvalidExpense)}
+	b := New(api, allowAllAuthorizer{}, extract, store, WithPendingQueue(queue))
+
+	b.handleUpdate(context.Background(), textUpdate("taxi 42"))
+
+	if items, _ := queue.ListPending(context.Background(), 1); len(items) != 0 {
+		t.Fatalf("expected an invalid expense not to be queued, got %#v", items)
+	}
+	if len(api.messages) != 1 || strings.Contains(api.messages[0], "pending #") {
+		t.Fatalf("expected a failure reply, got %#v", api.messages)
+	}
+}
+
+func TestRetryPendingSuccessNotifiesUser(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	extract := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"}}
+	store := &fakeStore{}
+	b := New(api, allowAllAuthorizer{}, extract, store, WithPendingQueue(queue))
+
+	ctx := context.Background()
+	now := time.Now()
+	if _, err := queue.EnqueuePending(ctx, pendingFixture("coffee 3.5", now.Add(-time.Second))); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	b.retryPending(ctx, now)
+
+	if len(store.items) != 1 {
+		t.Fatalf("expected retried expense to be stored, got %d", len(store.items))
+	}
+	if items, _ := queue.ListPending(ctx, 1); len(items) != 0 {
+		t.Fatalf("expected queue to be drained, got %#v", items)
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "is now recorded") {
+		t.Fatalf("expected success notification, got %#v", api.messages)
+	}
+}
+
+func TestRetryPendingBacksOffAndGivesUp(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	extract := &fakeExtractor{err: &openai.APIError{HTTPStatusCode: 429, Message: "slow down"}}
+	b := New(api, allowAllAuthorizer{}, extract, &fakeStore{}, WithPendingQueue(queue))
+
+	ctx := context.Background()
+	now := time.Now()
+	fixture := pendingFixture("coffee 3.5", now)
+	fixture.Attempts = maxPendingAttempts - 2
+	id, _ := queue.EnqueuePending(ctx, fixture)
+
+	b.retryPending(ctx, now)
+	items, _ := queue.ListPending(ctx, 1)
+	if len(items) != 1 || !items[0].NextAttempt.After(now) {
+		t.Fatalf("expected entry to be rescheduled into the future, got %#v", items)
+	}
+	if len(api.messages) != 0 {
+		t.Fatalf("expected silent reschedule, got %#v", api.messages)
+	}
+
+	b.retryPending(ctx, items[0].NextAttempt)
+	items, _ = queue.ListPending(ctx, 1)
+	if len(items) != 1 || items[0].ID != id || !items[0].NextAttempt.IsZero() {
+		t.Fatalf("expected entry to be parked after max attempts, got %#v", items)
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "/pending") {
+		t.Fatalf("expected give-up notification, got %#v", api.messages)
+	}
+}
+
+func TestPendingCommandListAndDiscard(t *testing.T) {
+	api := &fakeAPI{}
+	queue := memory.NewStore()
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithPendingQueue(queue))
+
+	ctx := context.Background()
+	first, _ := queue.EnqueuePending(ctx, pendingFixture("coffee 3.5", time.Now()))
+	_, _ = queue.EnqueuePending(ctx, pendingFixture("taxi 20", time.Now()))
+
+	b.handleUpdate(ctx, commandUpdate("/pending"))
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], `"coffee 3.5"`) || !strings.Contains(api.messages[0], `"taxi 20"`) {
+		t.Fatalf("expected both entries listed, got %#v", api.messages)
+	}
+
+	b.handleUpdate(ctx, commandUpdate("/pending discard #1"))
+	items, _ := queue.ListPending(ctx, 1)
+	if len(items) != 1 || items[0].ID == first {
+		t.Fatalf("expected first entry discarded, got %#v", items)
+	}
+
+	b.handleUpdate(ctx, commandUpdate("/pending discard all"))
+	if items, _ := queue.ListPending(ctx, 1); len(items) != 0 {
+		t.Fatalf("expected queue emptied, got %#v", items)
+	}
+}
+
+func TestPendingBackoff(t *testing.T) {
+	if got := pendingBackoff(1); got != pendingBaseDelay {
+		t.Fatalf("expected base delay, got %v", got)
+	}
+	if got := pendingBackoff(3); got != 4*pendingBaseDelay {
+		t.Fatalf("expected doubled delay, got %v", got)
+	}
+	if got := pendingBackoff(50); got != pendingMaxDelay {
+		t.Fatalf("expected capped delay, got %v", got)
+	}
+}
+
+func pendingFixture(text string, next time.Time) storage.PendingExpense {
+	return storage.PendingExpense{ChatID: 1, UserID: 7, Username: "iamoxyrus", Text: text, Attempts: 1, NextAttempt: next}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/query"
+)
+
+// WithQuestions enables /ask and answers free-form messages that read as questions about
+// past spending instead of recording them as expenses.
+func WithQuestions(questions extractor.QueryExtractor) Option {
+	return func(b *Bot) {
+		b.questions = questions
+	}
+}
+
+func (b *Bot) handleAsk(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.questions == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("ask.disabled"))
+		return
+	}
+	text := strings.TrimSpace(msg.CommandArguments())
+	if text == "" {
+		b.reply(ctx, msg.Chat.ID, settings.T("ask.usage"))
+		return
+	}
+	b.answerQuestion(ctx, msg, text)
+}
+
+// answerQuestion turns text into a structured query and answers it from the sender's own
+// expenses.
+func (b *Bot) answerQuestion(ctx context.Context, msg *tgbotapi.Message, text string) {
+	settings := locale.From(ctx)
+	q, err := b.questions.ExtractQuery(ctx, text)
+	if err != nil {
+		slog.WarnContext(ctx, "extract query", "reason", extractor.Reason(err), "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("ask.not_understood"))
+		return
+	}
+	answer, err := query.Run(ctx, b.store, msg.From.ID, q)
+	if err != nil {
+		slog.ErrorContext(ctx, "run query", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("ask.failed"))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, answer.Format(settings))
+}
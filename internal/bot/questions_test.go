@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/query"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+type fakeQueryExtractor struct {
+	query    query.Query
+	err      error
+	requests []string
+}
+
+func (f *fakeQueryExtractor) ExtractQuery(_ context.Context, text string) (query.Query, error) {
+	f.requests = append(f.requests, text)
+	return f.query, f.err
+}
+
+func TestQuestionsAreAnsweredInsteadOfRecorded(t *testing.T) {
+	fake := &fakeAPI{}
+	extractor := &fakeExtractor{}
+	store := &fakeStore{recent: []storage.Expense{
+		{ID: 1, Item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}, CreatedAt: time.Date(2026, 9, 3, 8, 0, 0, 0, time.UTC)},
+		{ID: 2, Item: expense.Item{Category: "Coffee", Amount: 4, Description: "Flat white"}, CreatedAt: time.Date(2026, 9, 10, 8, 0, 0, 0, time.UTC)},
+	}}
+	questions := &fakeQueryExtractor{query: query.Query{
+		Category: "Coffee",
+		Since:    time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		Until:    time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	b := New(fake, allowAllAuthorizer{}, extractor, store, WithQuestions(questions))
+
+	b.handleUpdate(context.Background(), textUpdate("How much did I spend on coffee in September?"))
+
+	if len(extractor.requests) != 0 {
+		t.Fatalf("expected the question not to be extracted as an expense, got %v", extractor.requests)
+	}
+	if len(fake.messages) != 1 || fake.messages[0] != "You spent $7.50 on Coffee in September 2026 (2 expenses)." {
+		t.Fatalf("unexpected reply %#v", fake.messages)
+	}
+	if store.exportFilter.UserID != 7 || store.exportFilter.Category != "Coffee" {
+		t.Fatalf("expected the query to be scoped to the sender, got %+v", store.exportFilter)
+	}
+
+	b.handleUpdate(context.Background(), textUpdate("coffee 3.50"))
+	if len(extractor.requests) != 1 || len(questions.requests) != 1 {
+		t.Fatalf("expected expenses to keep going to the extractor, got %v and %v", extractor.requests, questions.requests)
+	}
+}
+
+func TestAskCommand(t *testing.T) {
+	fake := &fakeAPI{}
+	questions := &fakeQueryExtractor{err: errors.New("unavailable")}
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithQuestions(questions))
+
+	b.handleUpdate(context.Background(), commandUpdate("/ask"))
+	b.handleUpdate(context.Background(), commandUpdate("/ask what did I buy"))
+
+	if len(fake.messages) != 2 || fake.messages[0] != locale.Default().T("ask.usage") || !strings.Contains(fake.messages[1], "couldn't understand") {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	if len(questions.requests) != 1 || questions.requests[0] != "what did I buy" {
+		t.Fatalf("unexpected query requests %v", questions.requests)
+	}
+}
+
+func TestAskCommandWithoutQuestions(t *testing.T) {
+	fake := &fakeAPI{}
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{})
+
+	b.handleUpdate(context.Background(), commandUpdate("/ask how much?"))
+
+	if len(fake.messages) != 1 || fake.messages[0] != "Questions are not enabled." {
+		t.Fatalf("unexpected reply %#v", fake.messages)
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+const maxCurrencyLength = 4
+
+// WithSettings enables the /settings command and makes replies, dates and amounts follow
+// each user's saved language, time zone, currency and number format instead of the
+// defaults.
+func WithSettings(store storage.SettingsStore) Option {
+	return func(b *Bot) {
+		b.settings = store
+	}
+}
+
+// withUserSettings returns a copy of ctx carrying the user's settings, falling back to the
+// defaults when settings are disabled or fail to load.
+func (b *Bot) withUserSettings(ctx context.Context, userID int64) context.Context {
+	if b.settings == nil {
+		return ctx
+	}
+	settings, err := b.settings.UserSettings(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "load settings", "err", err)
+		return ctx
+	}
+	return locale.WithSettings(ctx, settings)
+}
+
+func (b *Bot) handleSettings(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.settings == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("settings.disabled"))
+		return
+	}
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		b.reply(ctx, msg.Chat.ID, formatSettings(settings, time.Now()))
+		return
+	}
+	if len(args) != 2 {
+		b.reply(ctx, msg.Chat.ID, settings.T("settings.usage"))
+		return
+	}
+
+	updated, err := applySetting(settings, strings.ToLower(args[0]), args[1])
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(err), settings.T("settings.usage")))
+		return
+	}
+	if err := b.settings.SaveUserSettings(ctx, msg.From.ID, updated); err != nil {
+		slog.ErrorContext(ctx, "save settings", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("settings.save_failed"))
+		return
+	}
+	// Confirm in the new language when that is what changed.
+	b.reply(ctx, msg.Chat.ID, updated.T("settings.saved")+formatSettings(updated, time.Now()))
+}
+
+// applySetting returns settings with one field changed from a /settings argument. Setting
+// names and values are accepted in English or Spanish.
+func applySetting(settings locale.Settings, name, value string) (locale.Settings, error) {
+	switch locale.StripAccents(name) {
+	case "language", "lang", "idioma":
+		lang, ok := locale.ParseLanguage(value)
+		if !ok {
+			return settings, locale.Errorf("settings.err.language", value)
+		}
+		settings.Language = lang
+	case "timezone", "tz", "zone", "zona":
+		loc, err := locale.LoadLocation(value)
+		if err != nil {
+			return settings, locale.Errorf("settings.err.timezone", value)
+		}
+		settings.Location = loc
+	case "currency", "moneda":
+		if utf8.RuneCountInString(value) > maxCurrencyLength {
+			return settings, locale.Errorf("settings.err.currency", maxCurrencyLength)
+		}
+		settings.Currency = value
+	case "decimal", "decimals", "decimales":
+		switch strings.ToLower(value) {
+		case "comma", "coma", ",":
+			settings.DecimalComma = true
+		case "point", "dot", "punto", ".":
+			settings.DecimalComma = false
+		default:
+			return settings, locale.Errorf("settings.err.decimal", value)
+		}
+	case "week", "semana":
+		day, ok := locale.ParseWeekday(value)
+		if !ok {
+			return settings, locale.Errorf("settings.err.week", value)
+		}
+		settings.WeekStart = day
+	default:
+		return settings, locale.Errorf("settings.err.unknown", name)
+	}
+	return settings, nil
+}
+
+// formatSettings describes settings, with the current local time as a check on the zone.
+func formatSettings(settings locale.Settings, now time.Time) string {
+	separator := settings.T("settings.point")
+	if settings.DecimalComma {
+		separator = settings.T("settings.comma")
+	}
+	return settings.T("settings.summary",
+		settings.T("language."+string(settings.Lang())), settings.Loc(), settings.DateTime(now),
+		settings.Currency, separator, settings.Money(1234.5), settings.Weekday(settings.WeekStart))
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func TestSettingsCommandChangesFormatting(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	extractor := &fakeExtractor{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}}
+	b := New(fake, allowAllAuthorizer{}, extractor, store, WithSettings(store))
+	ctx := context.Background()
+
+	for _, text := range []string{
+		"/settings timezone Mars/Olympus",
+		"/settings timezone America/Bogota",
+		"/settings currency €",
+		"/settings decimal comma",
+		"/settings week sunday",
+	} {
+		b.handleUpdate(ctx, commandUpdate(text))
+	}
+	b.handleUpdate(ctx, textUpdate("latte 3,50"))
+
+	if len(fake.messages) != 6 {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	if !strings.HasPrefix(fake.messages[0], `Sorry, "Mars/Olympus" is not a time zone`) {
+		t.Fatalf("expected an unknown zone to be rejected, got %q", fake.messages[0])
+	}
+	if want := "- Weeks start on Sunday"; !strings.HasSuffix(fake.messages[4], want) || !strings.Contains(fake.messages[4], "€1234,50") {
+		t.Fatalf("expected the saved settings to be echoed, got %q", fake.messages[4])
+	}
+	if !strings.Contains(fake.messages[5], "Amount: €3,50") {
+		t.Fatalf("expected the confirmation in the user's currency, got %q", fake.messages[5])
+	}
+
+	settings, err := store.UserSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("UserSettings error: %v", err)
+	}
+	if settings.Loc().String() != "America/Bogota" || settings.WeekStart != time.Sunday {
+		t.Fatalf("unexpected stored settings %#v", settings)
+	}
+}
+
+func TestSettingsFollowTheUsersCalendar(t *testing.T) {
+	bogota, err := locale.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatalf("LoadLocation error: %v", err)
+	}
+	settings := locale.Settings{Location: bogota, Currency: "€", DecimalComma: true, WeekStart: time.Sunday}
+	ctx := locale.WithSettings(context.Background(), settings)
+
+	// 02:00 UTC on Sunday is still Saturday evening in Bogotá.
+	now := settings.In(time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC))
+	filter, label, err := parsePeriod("month", now)
+	if err != nil || label != "2026-10" || !filter.Since.Equal(time.Date(2026, 10, 1, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected month filter %+v %q (%v)", filter, label, err)
+	}
+
+	store := &fakeStore{recent: []storage.Expense{
+		// Saturday evening in Bogotá belongs to the week that started on Sunday the 11th.
+		chartExpense(time.Date(2026, 8, 9, 12, 0, 0, 0, time.UTC), "Rent", 900),
+		chartExpense(time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC), "Food", 30),
+	}}
+	b := New(&fakeAPI{}, allowAllAuthorizer{}, &fakeExtractor{}, store)
+	report, ok, err := b.buildChartReport(ctx, storage.ExportFilter{}, now)
+	if err != nil || !ok {
+		t.Fatalf("buildChartReport = %v, %v", ok, err)
+	}
+	last := report.Buckets[len(report.Buckets)-1]
+	if report.Buckets[0].Label != "Aug 09" || last.Label != "Oct 11" || last.Value != 30 {
+		t.Fatalf("expected Sunday weeks in Bogotá, got %#v", report.Buckets)
+	}
+	if report.Currency != "€" || !report.DecimalComma {
+		t.Fatalf("expected the chart to use the user's currency, got %+v", report)
+	}
+}
+
+func TestSettingsLanguageSwitchesReplies(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	extractor := &fakeExtractor{item: expense.Item{Category: "Groceries", Amount: 30, Description: "mercado"}}
+	b := New(fake, allowAllAuthorizer{}, extractor, store, WithSettings(store))
+	ctx := context.Background()
+
+	b.handleUpdate(ctx, commandUpdate("/settings idioma es"))
+	b.handleUpdate(ctx, textUpdate("mercado 30"))
+	b.handleUpdate(ctx, commandUpdate("/stats fortnight"))
+
+	if len(fake.messages) != 3 {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	if !strings.HasPrefix(fake.messages[0], "Guardado.\n") || !strings.Contains(fake.messages[0], "- Idioma: español") {
+		t.Fatalf("expected the settings in Spanish, got %q", fake.messages[0])
+	}
+	if !strings.Contains(fake.messages[1], "Categoría: Mercado") {
+		t.Fatalf("expected a Spanish confirmation, got %q", fake.messages[1])
+	}
+	if want := (locale.Settings{Language: locale.Spanish}).T("stats.usage"); fake.messages[2] != want {
+		t.Fatalf("expected the Spanish usage %q, got %q", want, fake.messages[2])
+	}
+
+	settings, err := store.UserSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("UserSettings error: %v", err)
+	}
+	if settings.Lang() != locale.Spanish {
+		t.Fatalf("expected Spanish to be stored, got %q", settings.Language)
+	}
+}
@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/importer"
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+const (
+	maxStatementBytes   = 5 << 20
+	maxImportReplyItems = 10
+)
+
+type statementImport struct {
+	mapping    importer.CSVMapping
+	window     time.Duration
+	categorize bool
+	client     *http.Client
+}
+
+// WithStatementImport lets users send CSV, OFX or QFX bank statements to record missing
+// expenses. When categorize is set, rows without a category go through the extractor.
+func WithStatementImport(mapping importer.CSVMapping, window time.Duration, categorize bool) Option {
+	return func(b *Bot) {
+		b.imports = &statementImport{
+			mapping:    mapping,
+			window:     window,
+			categorize: categorize,
+			client:     &http.Client{Timeout: time.Minute},
+		}
+	}
+}
+
+func (b *Bot) handleDocument(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.imports == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("import.disabled"))
+		return
+	}
+	doc := msg.Document
+	if doc.FileSize > maxStatementBytes {
+		b.reply(ctx, msg.Chat.ID, settings.T("import.too_large", maxStatementBytes>>20))
+		return
+	}
+
+	data, err := b.downloadDocument(ctx, doc.FileID)
+	if err != nil {
+		slog.ErrorContext(ctx, "download statement", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("import.download_failed"))
+		return
+	}
+
+	txns, err := importer.Parse(doc.FileName, bytes.NewReader(data), b.imports.mapping)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("import.unreadable", doc.FileName, err))
+		return
+	}
+
+	opts := importer.Options{
+		UserID:      msg.From.ID,
+		MatchWindow: b.imports.window,
+		DryRun:      isDryRun(msg.Caption),
+	}
+	if b.imports.categorize {
+		opts.Categorizer = b.extractor
+	}
+	result, err := importer.Import(ctx, b.store, txns, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "import statement", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.Plural("import.stopped", result.Imported, err))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, formatImportResult(doc.FileName, result, opts.DryRun, settings))
+}
+
+func (b *Bot) downloadDocument(ctx context.Context, fileID string) ([]byte, error) {
+	url, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("get file url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.imports.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download file: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStatementBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxStatementBytes {
+		return nil, fmt.Errorf("download file: larger than %d bytes", maxStatementBytes)
+	}
+	return data, nil
+}
+
+// isDryRun reports whether a caption like "preview", "dry run" or "vista previa" asks for
+// a preview.
+func isDryRun(caption string) bool {
+	switch strings.ToLower(strings.TrimSpace(caption)) {
+	case "preview", "dry run", "dry-run", "vista previa", "prueba":
+		return true
+	default:
+		return false
+	}
+}
+
+func formatImportResult(name string, result importer.Result, dryRun bool, settings locale.Settings) string {
+	var builder strings.Builder
+	key := "import.imported"
+	if dryRun {
+		key = "import.would_import"
+	}
+	builder.WriteString(settings.Plural(key, result.Imported, name))
+	var notes []string
+	if result.Duplicates > 0 {
+		notes = append(notes, settings.Plural("import.duplicates", result.Duplicates))
+	}
+	if result.Skipped > 0 {
+		notes = append(notes, settings.Plural("import.skipped", result.Skipped))
+	}
+	if len(notes) > 0 {
+		builder.WriteString(" (" + strings.Join(notes, ", ") + ")")
+	}
+	builder.WriteString(".\n")
+
+	for i, item := range result.Items {
+		if i == maxImportReplyItems {
+			builder.WriteString(settings.T("common.and_more", len(result.Items)-i))
+			break
+		}
+		// Statement dates are calendar days at noon UTC, so they are printed as they are.
+		builder.WriteString(fmt.Sprintf("- %s %s %s %s\n", item.Date.Format("2006-01-02"), settings.Category(item.Category), settings.Money(item.Amount), item.Description))
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/importer"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+const testStatement = "Date,Description,Amount\n" +
+	"2026-10-01,BLUE BOTTLE,-4.50\n" +
+	"2026-10-02,GROCER,-30.00\n" +
+	"2026-10-03,PAYROLL,2500.00\n"
+
+func documentUpdate(name, caption string) tgbotapi.Update {
+	update := textUpdate("")
+	update.Message.Caption = caption
+	update.Message.Document = &tgbotapi.Document{FileID: "file-1", FileName: name, FileSize: len(testStatement)}
+	return update
+}
+
+func statementServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file-1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testStatement))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStatementImportRecordsMissingExpenses(t *testing.T) {
+	api := &fakeAPI{fileURL: statementServer(t).URL}
+	store := memory.NewStore()
+	ctx := context.Background()
+	existing := expense.Item{Category: "Coffee", Amount: 4.5, Description: "Latte", Date: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	if _, err := store.SaveExpense(ctx, 7, existing); err != nil {
+		t.Fatal(err)
+	}
+	extract := &fakeExtractor{item: expense.Item{Category: "Groceries", Amount: 30, Description: "x"}}
+	b := New(api, allowAllAuthorizer{}, extract, store, WithStatementImport(importer.DefaultCSVMapping(), 0, true))
+
+	b.handleUpdate(ctx, documentUpdate("october.csv", ""))
+
+	if len(api.messages) != 1 {
+		t.Fatalf("expected one reply, got %#v", api.messages)
+	}
+	reply := api.messages[0]
+	for _, want := range []string{"Imported 1 expense from october.csv (1 already recorded, 1 incoming payment skipped)", "- 2026-10-02 Groceries $30.00 GROCER"} {
+		if !strings.Contains(reply, want) {
+			t.Fatalf("expected reply to contain %q, got:\n%s", want, reply)
+		}
+	}
+	if len(extract.requests) != 1 || extract.requests[0] != "GROCER 30.00" {
+		t.Fatalf("expected one categorization request, got %q", extract.requests)
+	}
+
+	var count int
+	store.ExportExpenses(ctx, storage.ExportFilter{UserID: 7}, func(storage.Expense) error { count++; return nil })
+	if count != 2 {
+		t.Fatalf("expected 2 stored expenses, got %d", count)
+	}
+}
+
+func TestStatementImportPreviewDoesNotSave(t *testing.T) {
+	api := &fakeAPI{fileURL: statementServer(t).URL}
+	store := memory.NewStore()
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store, WithStatementImport(importer.DefaultCSVMapping(), 0, false))
+
+	b.handleUpdate(context.Background(), documentUpdate("october.csv", "preview"))
+
+	if len(api.messages) != 1 || !strings.HasPrefix(api.messages[0], "Would import 2 expenses") {
+		t.Fatalf("unexpected reply %#v", api.messages)
+	}
+	if len(store.Items()) != 0 {
+		t.Fatal("preview must not save expenses")
+	}
+}
+
+func TestDocumentWithoutStatementImport(t *testing.T) {
+	api := &fakeAPI{}
+	extract := &fakeExtractor{}
+	b := New(api, allowAllAuthorizer{}, extract, &fakeStore{})
+
+	b.handleUpdate(context.Background(), documentUpdate("october.csv", ""))
+
+	if len(extract.requests) != 0 || len(api.messages) != 1 || !strings.Contains(api.messages[0], "not enabled") {
+		t.Fatalf("expected import to be refused, got %#v", api.messages)
+	}
+}
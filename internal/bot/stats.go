@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// anomalyHistory is how far back an expense is compared against.
+const anomalyHistory = 365 * 24 * time.Hour
+
+// WithAnalytics enables trend comparisons in /stats and, when detector has a positive
+// threshold, flags unusually large expenses as they are recorded.
+func WithAnalytics(store storage.AggregateStore, detector analytics.Detector) Option {
+	return func(b *Bot) {
+		b.analytics = store
+		b.detector = detector
+	}
+}
+
+// statsPeriod is the window /stats summarizes and the equivalent window before it,
+// optionally narrowed to one tag.
+type statsPeriod struct {
+	heading    string
+	since      time.Time
+	prevSince  time.Time
+	prevUntil  time.Time
+	comparison string
+	empty      string
+	tag        string
+}
+
+// parseStatsArgs splits an optional #tag from the period in /stats arguments.
+func parseStatsArgs(args string, now time.Time, settings locale.Settings) (statsPeriod, error) {
+	var (
+		rest []string
+		tag  string
+	)
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "#") {
+			rest = append(rest, field)
+			continue
+		}
+		normalized, ok := expense.NormalizeTag(field)
+		if !ok || tag != "" {
+			return statsPeriod{}, locale.Errorf("stats.err.tag", field)
+		}
+		tag = normalized
+	}
+	period, err := parseStatsPeriod(strings.Join(rest, " "), now, settings)
+	if err != nil || tag == "" {
+		return period, err
+	}
+	period.tag = tag
+	period.heading += settings.T("stats.heading_tag", tag)
+	period.empty = settings.T(period.empty+"_tag", tag)
+	return period, nil
+}
+
+// parseStatsPeriod reads "week" or "month", in English or Spanish. Both run from the start
+// of the user's current week or month and are compared with the same stretch of the
+// previous one. The period's empty field holds a catalog key, since a tag may still be
+// added to it.
+func parseStatsPeriod(args string, now time.Time, settings locale.Settings) (statsPeriod, error) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "week", "semana":
+		since := settings.StartOfWeek(now)
+		prevSince := since.AddDate(0, 0, -7)
+		return statsPeriod{
+			heading:    settings.T("stats.heading_week", settings.Date(since)),
+			since:      since,
+			prevSince:  prevSince,
+			prevUntil:  prevSince.Add(now.Sub(since)),
+			comparison: settings.T("stats.vs_week"),
+			empty:      "stats.empty_week",
+		}, nil
+	case "month", "mes":
+		since := settings.StartOfMonth(now)
+		prevSince := since.AddDate(0, -1, 0)
+		// Compare month-to-date with the same stretch of last month.
+		prevUntil := prevSince.Add(now.Sub(since))
+		if prevUntil.After(since) {
+			prevUntil = since
+		}
+		return statsPeriod{
+			heading:    settings.T("stats.heading_month", settings.Date(since)),
+			since:      since,
+			prevSince:  prevSince,
+			prevUntil:  prevUntil,
+			comparison: settings.T("stats.vs_month"),
+			empty:      "stats.empty_month",
+		}, nil
+	default:
+		return statsPeriod{}, locale.Errorf("period.err.unknown", args)
+	}
+}
+
+func (b *Bot) handleStats(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	now := settings.In(time.Now())
+	period, err := parseStatsArgs(msg.CommandArguments(), now, settings)
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("stats.usage"))
+		return
+	}
+	summary, err := b.store.Stats(ctx, storage.ExportFilter{UserID: msg.From.ID, Since: period.since, Tag: period.tag})
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("stats.load_failed", err))
+		return
+	}
+
+	var trends []analytics.Trend
+	if b.analytics != nil {
+		// Only the per-category sums over the window are used, so the UTC buckets do not
+		// matter; the window itself follows the user's time zone.
+		previous, err := b.analytics.BucketTotals(ctx, storage.ExportFilter{UserID: msg.From.ID, Since: period.prevSince, Until: period.prevUntil, Tag: period.tag, Kind: expense.KindExpense}, storage.BucketDay)
+		if err != nil {
+			// Trends are a bonus; the summary is still worth sending.
+			slog.WarnContext(ctx, "load previous period", "err", err)
+		} else {
+			trends = analytics.Compare(summary.CategoryTotals, analytics.Totals(previous))
+		}
+	}
+
+	if summary.TotalCount == 0 && summary.IncomeCount == 0 && len(trends) == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T(period.empty))
+		return
+	}
+	text := formatSummary(summary, period, trends, settings)
+	for _, section := range []string{b.accountSection(ctx, msg.From.ID, now), b.goalSection(ctx, msg.From.ID)} {
+		if section != "" {
+			text += "\n" + section
+		}
+	}
+	b.reply(ctx, msg.Chat.ID, text)
+}
+
+func formatSummary(summary storage.Summary, period statsPeriod, trends []analytics.Trend, settings locale.Settings) string {
+	var builder strings.Builder
+	builder.WriteString(period.heading + ":\n")
+	builder.WriteString(settings.Plural("stats.total", summary.TotalCount, settings.Money(summary.TotalAmount)))
+	if rate, ok := summary.SavingsRate(); ok {
+		builder.WriteString(settings.Plural("stats.income", summary.IncomeCount, settings.Money(summary.IncomeAmount)))
+		builder.WriteString(settings.T("stats.net", settings.Money(summary.Net()), rate*100))
+	}
+
+	if len(trends) > 0 {
+		var previousTotal float64
+		for _, t := range trends {
+			previousTotal += t.Previous
+		}
+		total := analytics.Trend{Current: summary.TotalAmount, Previous: previousTotal}
+		builder.WriteString(settings.T("stats.change", trendLabel(total, settings), period.comparison))
+		builder.WriteString(settings.T("stats.by_category"))
+		for _, t := range trends {
+			builder.WriteString(fmt.Sprintf("- %s: %s (%s)\n", settings.Category(t.Category), settings.Money(t.Current), trendLabel(t, settings)))
+		}
+	} else if len(summary.CategoryTotals) > 0 {
+		builder.WriteString(settings.T("stats.by_category"))
+		for _, ct := range sortedTotals(summary.CategoryTotals) {
+			builder.WriteString(fmt.Sprintf("- %s: %s\n", settings.Category(ct.name), settings.Money(ct.value)))
+		}
+	}
+
+	if len(summary.TagTotals) > 0 {
+		builder.WriteString(settings.T("stats.by_tag"))
+		for _, tt := range sortedTotals(summary.TagTotals) {
+			builder.WriteString(fmt.Sprintf("- #%s: %s\n", tt.name, settings.Money(tt.value)))
+		}
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// trendLabel translates the "new" and "gone" labels of a trend; percentages need no
+// translation.
+func trendLabel(t analytics.Trend, settings locale.Settings) string {
+	label := t.Label()
+	switch label {
+	case "new", "gone":
+		return settings.T("stats.trend_" + label)
+	default:
+		return label
+	}
+}
+
+type namedTotal struct {
+	name  string
+	value float64
+}
+
+// sortedTotals orders totals from largest to smallest, breaking ties by name.
+func sortedTotals(totals map[string]float64) []namedTotal {
+	sorted := make([]namedTotal, 0, len(totals))
+	for name, value := range totals {
+		sorted = append(sorted, namedTotal{name: name, value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].value == sorted[j].value {
+			return sorted[i].name < sorted[j].name
+		}
+		return sorted[i].value > sorted[j].value
+	})
+	return sorted
+}
+
+// anomalyNote returns a warning to append to the confirmation when item is far above the
+// user's usual spending in its category, or "" when it is not or detection is disabled.
+func (b *Bot) anomalyNote(ctx context.Context, userID int64, item expense.Item) string {
+	if b.analytics == nil || b.detector.Threshold <= 0 || item.IsIncome() {
+		return ""
+	}
+	filter := storage.ExportFilter{UserID: userID, Category: item.Category, Since: time.Now().Add(-anomalyHistory), Kind: expense.KindExpense}
+	stats, err := b.analytics.CategoryStats(ctx, filter)
+	if err != nil {
+		slog.WarnContext(ctx, "load category stats", "err", err)
+		return ""
+	}
+	if len(stats) == 0 {
+		return ""
+	}
+	anomaly, ok := b.detector.Check(analytics.BaselineFrom(stats[0]), item.Amount)
+	if !ok {
+		return ""
+	}
+	settings := locale.From(ctx)
+	return settings.T("stats.anomaly", settings.Category(item.Category), settings.Money(anomaly.Mean))
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+type fakeAggregates struct {
+	buckets      []storage.BucketTotal
+	stats        []storage.CategoryStat
+	bucketFilter storage.ExportFilter
+	statsFilter  storage.ExportFilter
+}
+
+func (f *fakeAggregates) BucketTotals(_ context.Context, filter storage.ExportFilter, _ storage.Granularity) ([]storage.BucketTotal, error) {
+	f.bucketFilter = filter
+	return f.buckets, nil
+}
+
+func (f *fakeAggregates) CategoryStats(_ context.Context, filter storage.ExportFilter) ([]storage.CategoryStat, error) {
+	f.statsFilter = filter
+	return f.stats, nil
+}
+
+func TestStatsShowsChangeVersusPreviousPeriod(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{stats: storage.Summary{
+		TotalCount:     3,
+		TotalAmount:    162,
+		CategoryTotals: map[string]float64{"Dining": 142, "Coffee": 20},
+	}}
+	aggregates := &fakeAggregates{buckets: []storage.BucketTotal{
+		{Category: "Dining", Total: 60},
+		{Category: "Dining", Total: 40},
+		{Category: "Coffee", Total: 25},
+		{Category: "Travel", Total: 35},
+	}}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store, WithAnalytics(aggregates, analytics.DefaultDetector()))
+
+	b.handleUpdate(context.Background(), commandUpdate("/stats month"))
+
+	if len(api.messages) != 1 {
+		t.Fatalf("expected one reply, got %#v", api.messages)
+	}
+	got := api.messages[0]
+	for _, want := range []string{
+		"This month (since ",
+		"Total: $162.00 across 3 expenses",
+		"Change: +1% vs last month",
+		"- Dining: $142.00 (+42%)",
+		"- Coffee: $20.00 (-20%)",
+		"- Travel: $0.00 (gone)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+
+	f := aggregates.bucketFilter
+	if f.Since.Day() != 1 || !f.Until.After(f.Since) || f.Until.Sub(f.Since) > 31*24*time.Hour {
+		t.Fatalf("expected the previous month-to-date window, got %#v", f)
+	}
+}
+
+func TestStatsOnlyCoversTheSender(t *testing.T) {
+	api := &fakeAPI{}
+	store := memory.NewStore()
+	ctx := context.Background()
+	for userID, amount := range map[int64]float64{7: 10, 8: 500} {
+		if _, err := store.SaveExpense(ctx, userID, expense.Item{Category: "Food", Amount: amount, Description: "Groceries"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	aggregates := &fakeAggregates{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store, WithAnalytics(aggregates, analytics.DefaultDetector()))
+
+	b.handleUpdate(ctx, commandUpdate("/stats month"))
+
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "Total: $10.00 across 1 expense") || strings.Contains(api.messages[0], "500") {
+		t.Fatalf("expected only the sender's expenses, got %#v", api.messages)
+	}
+	if aggregates.bucketFilter.UserID != 7 {
+		t.Fatalf("expected the previous period scoped to the sender, got %#v", aggregates.bucketFilter)
+	}
+}
+
+func TestStatsFiltersAndGroupsByTag(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{stats: storage.Summary{
+		TotalCount:     2,
+		TotalAmount:    340,
+		CategoryTotals: map[string]float64{"Travel": 300, "Food": 40},
+		TagTotals:      map[string]float64{"vacation-2026": 340, "work": 300},
+	}}
+	aggregates := &fakeAggregates{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store, WithAnalytics(aggregates, analytics.DefaultDetector()))
+
+	b.handleUpdate(context.Background(), commandUpdate("/stats #Vacation-2026 month"))
+
+	if store.statsFilter.Tag != "vacation-2026" || aggregates.bucketFilter.Tag != "vacation-2026" {
+		t.Fatalf("expected both periods filtered by tag, got %#v and %#v", store.statsFilter, aggregates.bucketFilter)
+	}
+	got := api.messages[0]
+	for _, want := range []string{"This month (since ", "for #vacation-2026:", "By tag:\n- #vacation-2026: $340.00\n- #work: $300.00"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+
+	b.handleUpdate(context.Background(), commandUpdate("/stats #a #b"))
+	if api.messages[1] != locale.Default().T("stats.usage") {
+		t.Fatalf("expected usage for two tags, got %q", api.messages[1])
+	}
+}
+
+func TestStatsShowsIncomeAndSavingsRate(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{stats: storage.Summary{
+		TotalCount:     1,
+		TotalAmount:    1500,
+		CategoryTotals: map[string]float64{"Rent": 1500},
+		IncomeCount:    1,
+		IncomeAmount:   2000,
+	}}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, store)
+
+	b.handleUpdate(context.Background(), commandUpdate("/stats month"))
+
+	got := api.messages[0]
+	for _, want := range []string{"Income: $2000.00 across 1 entry", "Net: $500.00 (savings rate 25%)", "- Rent: $1500.00"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestParseStatsPeriod(t *testing.T) {
+	now := time.Date(2026, 3, 31, 18, 0, 0, 0, time.UTC)
+
+	// Tuesday March 31: the week started on Monday the 30th by default.
+	week, err := parseStatsPeriod("", now, locale.Default())
+	if err != nil || !week.since.Equal(time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)) || week.since.Sub(week.prevSince) != 7*24*time.Hour {
+		t.Fatalf("unexpected week period %#v (%v)", week, err)
+	}
+	if !week.prevUntil.Equal(time.Date(2026, 3, 24, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the comparison to cover the same days of last week, got %v", week.prevUntil)
+	}
+
+	month, err := parseStatsPeriod("month", now, locale.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !month.since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !month.prevSince.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected month bounds %#v", month)
+	}
+	// March 31 is past the end of February, so the comparison covers all of February.
+	if !month.prevUntil.Equal(month.since) {
+		t.Fatalf("expected previous window capped at the month start, got %v", month.prevUntil)
+	}
+
+	if _, err := parseStatsPeriod("decade", now, locale.Default()); err == nil {
+		t.Fatal("expected unknown period to fail")
+	}
+}
+
+func TestProcessExpenseFlagsAnomalies(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeStore{}
+	// Ten food expenses averaging $30 with a standard deviation of $5.
+	aggregates := &fakeAggregates{stats: []storage.CategoryStat{{Category: "Food", Count: 10, Sum: 300, SumSquares: 10 * (900 + 25)}}}
+	extract := &fakeExtractor{item: expense.Item{Category: "Food", Amount: 250, Description: "Tasting menu"}}
+	b := New(api, allowAllAuthorizer{}, extract, store, WithAnalytics(aggregates, analytics.DefaultDetector()))
+
+	b.handleUpdate(context.Background(), textUpdate("tasting menu 250"))
+
+	if len(store.items) != 1 {
+		t.Fatalf("expected the expense to be stored, got %d", len(store.items))
+	}
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "Heads up: this is unusually high for Food (you usually spend about $30.00).") {
+		t.Fatalf("expected an anomaly warning, got %#v", api.messages)
+	}
+	if f := aggregates.statsFilter; f.UserID != 7 || f.Category != "Food" || f.Since.IsZero() {
+		t.Fatalf("expected the user's own category history, got %#v", f)
+	}
+
+	extract.item.Amount = 35
+	b.handleUpdate(context.Background(), textUpdate("lunch 35"))
+	if len(api.messages) != 2 || strings.Contains(api.messages[1], "Heads up") {
+		t.Fatalf("expected no warning for a usual amount, got %#v", api.messages)
+	}
+}
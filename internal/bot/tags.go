@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// WithTags enables the /tag command for tagging expenses after they were recorded.
+func WithTags(store storage.TagStore) Option {
+	return func(b *Bot) {
+		b.tags = store
+	}
+}
+
+func (b *Bot) handleTag(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.tags == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("tags.disabled"))
+		return
+	}
+	id, add, remove, err := parseTagArgs(msg.CommandArguments())
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(err), settings.T("tags.usage")))
+		return
+	}
+
+	if len(add) > 0 {
+		err = b.tags.TagExpense(ctx, msg.From.ID, id, add)
+	}
+	if err == nil && len(remove) > 0 {
+		err = b.tags.UntagExpense(ctx, msg.From.ID, id, remove)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		b.reply(ctx, msg.Chat.ID, settings.T("expense.not_found", id))
+		return
+	}
+	if errors.Is(err, storage.ErrTooManyTags) {
+		b.reply(ctx, msg.Chat.ID, settings.T("common.sorry", settings.Error(locale.Errorf("tags.err.too_many", expense.MaxTags)), settings.T("tags.usage")))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "tag expense", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("tags.failed"))
+		return
+	}
+
+	e, err := b.store.GetExpense(ctx, msg.From.ID, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get tagged expense", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("tags.updated", id))
+		return
+	}
+	if len(e.Tags) == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("tags.none", id))
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, settings.T("tags.tagged", id, expense.FormatTags(e.Tags)))
+}
+
+// parseTagArgs reads "<id> #add -#remove ..." from /tag arguments.
+func parseTagArgs(args string) (id int64, add, remove []string, err error) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return 0, nil, nil, locale.Errorf("tags.err.missing")
+	}
+	id, err = strconv.ParseInt(strings.TrimPrefix(fields[0], "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, nil, nil, locale.Errorf("tags.err.id", fields[0])
+	}
+	for _, field := range fields[1:] {
+		removing := strings.HasPrefix(field, "-")
+		tag, ok := expense.NormalizeTag(strings.TrimPrefix(field, "-"))
+		if !ok {
+			return 0, nil, nil, locale.Errorf("tags.err.invalid", field, expense.MaxTagLength)
+		}
+		if removing {
+			remove = expense.AddTags(remove, tag)
+		} else {
+			add = expense.AddTags(add, tag)
+		}
+	}
+	if len(add) > expense.MaxTags {
+		return 0, nil, nil, locale.Errorf("tags.err.too_many", expense.MaxTags)
+	}
+	return id, add, remove, nil
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func TestProcessExpenseExtractsHashtags(t *testing.T) {
+	fake := &fakeAPI{}
+	extractor := &fakeExtractor{item: expense.Item{Category: "Travel", Amount: 300, Description: "Hotel"}}
+	store := &fakeStore{}
+	b := New(fake, allowAllAuthorizer{}, extractor, store)
+
+	b.handleUpdate(context.Background(), textUpdate("hotel 300 #Vacation-2026 #work #work"))
+
+	if len(extractor.requests) != 1 || extractor.requests[0] != "hotel 300" {
+		t.Fatalf("expected hashtags to be stripped before extraction, got %v", extractor.requests)
+	}
+	if len(store.items) != 1 || strings.Join(store.items[0].Tags, ",") != "vacation-2026,work" {
+		t.Fatalf("expected normalized tags on the saved item, got %#v", store.items)
+	}
+	if !strings.Contains(fake.messages[0], "Tags: #vacation-2026 #work") {
+		t.Fatalf("expected tags in the confirmation, got %q", fake.messages[0])
+	}
+}
+
+func TestTagCommand(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	ctx := context.Background()
+	id, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 40, Description: "Dinner", Tags: []string{"work"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, store, WithTags(store))
+
+	b.handleUpdate(ctx, commandUpdate("/tag 1 #Vacation-2026 -#work"))
+	b.handleUpdate(ctx, commandUpdate("/tag 1 -vacation-2026"))
+	b.handleUpdate(ctx, commandUpdate("/tag 99 #x"))
+	b.handleUpdate(ctx, commandUpdate("/tag 1 #no/slashes"))
+	b.handleUpdate(ctx, commandUpdate("/tag 1"))
+	b.handleUpdate(ctx, commandUpdate("/tag 1 #a #b #c #d #e #f #g #h #i #j"))
+	b.handleUpdate(ctx, commandUpdate("/tag 1 #k"))
+
+	want := []string{
+		"Expense #1 is tagged #vacation-2026.",
+		"Expense #1 has no tags.",
+		"Expense #99 not found.",
+		`Sorry, "#no/slashes" is not a valid tag`,
+		"Sorry, tell me which expense and which tags.",
+		"Expense #1 is tagged",
+		"Sorry, an expense can have at most 10 tags.",
+	}
+	if len(fake.messages) != len(want) {
+		t.Fatalf("unexpected replies %#v", fake.messages)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(fake.messages[i], w) {
+			t.Errorf("reply %d: expected prefix %q, got %q", i, w, fake.messages[i])
+		}
+	}
+	if e, _ := store.GetExpense(ctx, 7, id); len(e.Tags) != expense.MaxTags {
+		t.Fatalf("expected the tags up to the limit, got %v", e.Tags)
+	}
+}
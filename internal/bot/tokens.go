@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/api"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// WithAPITokens enables the /token command for issuing and revoking REST API tokens.
+func WithAPITokens(store storage.APITokenStore) Option {
+	return func(b *Bot) {
+		b.tokens = store
+	}
+}
+
+func (b *Bot) handleToken(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if b.tokens == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("token.disabled"))
+		return
+	}
+	// Tokens grant full access to the sender's expenses, so keep them out of group chats.
+	if !msg.Chat.IsPrivate() {
+		b.reply(ctx, msg.Chat.ID, settings.T("token.private_only"))
+		return
+	}
+
+	args := strings.Fields(msg.CommandArguments())
+	action := "new"
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+	switch {
+	case action == "new" && len(args) <= 1:
+		b.issueToken(ctx, msg)
+	case action == "list" && len(args) == 1:
+		b.listTokens(ctx, msg)
+	case action == "revoke" && len(args) == 2:
+		b.revokeToken(ctx, msg, args[1])
+	default:
+		b.reply(ctx, msg.Chat.ID, settings.T("token.usage"))
+	}
+}
+
+func (b *Bot) issueToken(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	token, hash, err := api.GenerateToken()
+	if err == nil {
+		var id int64
+		id, err = b.tokens.CreateAPIToken(ctx, storage.APIToken{UserID: msg.From.ID, Username: msg.From.UserName, Hash: hash})
+		if err == nil {
+			b.reply(ctx, msg.Chat.ID, settings.T("token.issued", id, token, id))
+			return
+		}
+	}
+	slog.ErrorContext(ctx, "issue api token", "err", err)
+	b.reply(ctx, msg.Chat.ID, settings.T("token.issue_failed"))
+}
+
+func (b *Bot) listTokens(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	tokens, err := b.tokens.ListAPITokens(ctx, msg.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "list api tokens", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("token.load_failed"))
+		return
+	}
+	if len(tokens) == 0 {
+		b.reply(ctx, msg.Chat.ID, settings.T("token.none"))
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(settings.T("token.list_heading"))
+	for _, token := range tokens {
+		builder.WriteString(settings.T("token.list_item", token.ID, settings.DateTime(token.CreatedAt)))
+	}
+	b.reply(ctx, msg.Chat.ID, strings.TrimRight(builder.String(), "\n"))
+}
+
+func (b *Bot) revokeToken(ctx context.Context, msg *tgbotapi.Message, arg string) {
+	settings := locale.From(ctx)
+	var id int64
+	if !strings.EqualFold(arg, "all") {
+		parsed, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+		if err != nil || parsed <= 0 {
+			b.reply(ctx, msg.Chat.ID, settings.T("token.usage"))
+			return
+		}
+		id = parsed
+	}
+
+	err := b.tokens.RevokeAPIToken(ctx, msg.From.ID, id)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		b.reply(ctx, msg.Chat.ID, settings.T("token.not_found", id))
+	case err != nil:
+		slog.ErrorContext(ctx, "revoke api token", "err", err)
+		b.reply(ctx, msg.Chat.ID, settings.T("token.revoke_failed"))
+	case id == 0:
+		b.reply(ctx, msg.Chat.ID, settings.T("token.revoked_all"))
+	default:
+		b.reply(ctx, msg.Chat.ID, settings.T("token.revoked", id))
+	}
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/api"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func privateCommand(text string) tgbotapi.Update {
+	update := commandUpdate(text)
+	update.Message.Chat.Type = "private"
+	return update
+}
+
+func TestTokenCommandIssuesListsAndRevokes(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithAPITokens(store))
+	ctx := context.Background()
+
+	b.handleUpdate(ctx, privateCommand("/token new"))
+	if len(fake.messages) != 1 || !strings.Contains(fake.messages[0], "API token #1") {
+		t.Fatalf("unexpected reply %#v", fake.messages)
+	}
+	var token string
+	for _, field := range strings.Fields(fake.messages[0]) {
+		if strings.HasPrefix(field, api.TokenPrefix) {
+			token = field
+		}
+	}
+	stored, err := store.APITokenByHash(ctx, api.HashToken(token))
+	if err != nil || stored.UserID != 7 || stored.Username != "iamoxyrus" {
+		t.Fatalf("expected the token to be stored for the sender, got %#v (%v)", stored, err)
+	}
+
+	b.handleUpdate(ctx, privateCommand("/token"))
+	b.handleUpdate(ctx, privateCommand("/token list"))
+	if got := fake.messages[2]; !strings.Contains(got, "#1 created") || !strings.Contains(got, "#2 created") || strings.Contains(got, api.TokenPrefix) {
+		t.Fatalf("expected both tokens listed without secrets, got %q", got)
+	}
+
+	b.handleUpdate(ctx, privateCommand("/token revoke 1"))
+	if got := fake.messages[3]; got != "Revoked API token #1." {
+		t.Fatalf("unexpected revoke reply %q", got)
+	}
+	if _, err := store.APITokenByHash(ctx, api.HashToken(token)); err == nil {
+		t.Fatal("expected revoked token to be gone")
+	}
+	b.handleUpdate(ctx, privateCommand("/token revoke 1"))
+	if got := fake.messages[4]; got != "No API token #1." {
+		t.Fatalf("unexpected reply %q", got)
+	}
+
+	b.handleUpdate(ctx, privateCommand("/token revoke all"))
+	b.handleUpdate(ctx, privateCommand("/token list"))
+	if got := fake.messages[6]; !strings.Contains(got, "no API tokens") {
+		t.Fatalf("expected no tokens left, got %q", got)
+	}
+
+	b.handleUpdate(ctx, privateCommand("/token revoke x"))
+	if got := fake.messages[7]; got != locale.Default().T("token.usage") {
+		t.Fatalf("expected usage, got %q", got)
+	}
+}
+
+func TestTokenCommandRefusesGroupChats(t *testing.T) {
+	fake := &fakeAPI{}
+	store := memory.NewStore()
+	b := New(fake, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{}, WithAPITokens(store))
+
+	update := commandUpdate("/token new")
+	update.Message.Chat.Type = "group"
+	b.handleUpdate(context.Background(), update)
+
+	if tokens, _ := store.ListAPITokens(context.Background(), 7); len(tokens) != 0 {
+		t.Fatalf("expected no token issued, got %#v", tokens)
+	}
+	if len(fake.messages) != 1 || !strings.Contains(fake.messages[0], "private chat") {
+		t.Fatalf("unexpected reply %#v", fake.messages)
+	}
+}
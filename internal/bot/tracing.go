@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Oxyrus/financebot/internal/bot"
+
+// WithTracerProvider records spans for update handling, command dispatch and Telegram
+// sends. Without it the bot records nothing.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(b *Bot) {
+		b.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// send delivers c to Telegram inside a span. The error is left out of the span because
+// Telegram errors embed the bot token in the request URL.
+func (b *Bot) send(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	_, span := b.tracer.Start(ctx, "telegram.Send", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	msg, err := b.api.Send(c)
+	if err != nil {
+		span.SetStatus(codes.Error, "send failed")
+	}
+	return msg, err
+}
+
+// setOutcome records how handling an update ended on the current span.
+func setOutcome(ctx context.Context, outcome string) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("financebot.outcome", outcome))
+}
+
+// updateType classifies an update for its span.
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.Message == nil:
+		return "other"
+	case update.Message.IsCommand():
+		return "command"
+	case update.Message.Document != nil:
+		return "document"
+	default:
+		return "message"
+	}
+}
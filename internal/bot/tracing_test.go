@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestHandleUpdateRecordsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	extract := &fakeExtractor{item: expense.Item{Category: "Food", Amount: 12, Description: "Lunch"}}
+	b := New(&fakeAPI{}, allowAllAuthorizer{}, extract, memory.NewStore(), WithTracerProvider(tp))
+
+	b.handleUpdate(context.Background(), textUpdate("lunch 12"))
+	b.handleUpdate(context.Background(), commandUpdate("/stats month"))
+	b.handleUpdate(context.Background(), commandUpdate("/bogus"))
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	updates, commands, sends := spans["bot.handleUpdate"], spans["bot.command"], spans["telegram.Send"]
+	if len(updates) != 3 || len(commands) != 2 || len(sends) != 3 {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+
+	expenseUpdate, commandUpdate := updates[0], updates[1]
+	if got := spanAttr(expenseUpdate, "financebot.update.type"); got != "message" {
+		t.Fatalf("expected a message update, got %q", got)
+	}
+	if got := spanAttr(expenseUpdate, "financebot.outcome"); got != "recorded" {
+		t.Fatalf("expected the expense to be recorded, got %q", got)
+	}
+	if sends[0].Parent().SpanID() != expenseUpdate.SpanContext().SpanID() {
+		t.Fatal("expected the confirmation to be sent within the update span")
+	}
+
+	command := commands[0]
+	if got := spanAttr(command, "financebot.command"); got != "stats" {
+		t.Fatalf("expected the stats command, got %q", got)
+	}
+	if command.Parent().SpanID() != commandUpdate.SpanContext().SpanID() {
+		t.Fatal("expected command dispatch within the update span")
+	}
+	if got := spanAttr(command, "financebot.outcome"); got != "handled" {
+		t.Fatalf("expected the stats command to be handled, got %q", got)
+	}
+	if sends[1].Parent().SpanID() != command.SpanContext().SpanID() {
+		t.Fatal("expected the stats reply to be sent within the command span")
+	}
+
+	unknown := commands[1]
+	if got := spanAttr(unknown, "financebot.command"); got != "unknown" {
+		t.Fatalf("expected an unknown command to be bucketed, got %q", got)
+	}
+	if got := spanAttr(unknown, "financebot.outcome"); got != "unknown_command" {
+		t.Fatalf("expected an unknown_command outcome, got %q", got)
+	}
+}
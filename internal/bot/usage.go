@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/usage"
+)
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+func (b *Bot) isAdmin(username string) bool {
+	return b.admins != nil && b.admins.IsAdmin(username)
+}
+
+func (b *Bot) handleUsage(ctx context.Context, msg *tgbotapi.Message) {
+	settings := locale.From(ctx)
+	if !b.isAdmin(msg.From.UserName) {
+		b.reply(ctx, msg.Chat.ID, settings.T("usage.admins_only"))
+		return
+	}
+	if b.usage == nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("usage.disabled"))
+		return
+	}
+
+	days := defaultUsageDays
+	if arg := strings.TrimSpace(msg.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxUsageDays {
+			b.reply(ctx, msg.Chat.ID, settings.T("usage.usage", maxUsageDays))
+			return
+		}
+		days = n
+	}
+
+	now := time.Now()
+	since := settings.StartOfDay(now).AddDate(0, 0, -(days - 1))
+	totals, err := b.usage.UsageTotals(ctx, since, settings.Loc())
+	if err != nil {
+		b.reply(ctx, msg.Chat.ID, settings.T("usage.load_failed", err))
+		return
+	}
+
+	var monthTokens int64
+	if b.budget > 0 {
+		monthTokens, err = b.usage.TokensUsedSince(ctx, usage.MonthStart(now))
+		if err != nil {
+			b.reply(ctx, msg.Chat.ID, settings.T("usage.load_failed", err))
+			return
+		}
+	}
+
+	b.reply(ctx, msg.Chat.ID, formatUsage(totals, b.prices, since, b.budget, monthTokens, settings))
+}
+
+type usageBucket struct {
+	label  string
+	calls  int
+	tokens int64
+	cost   float64
+	priced bool
+}
+
+func formatUsage(totals []storage.UsageTotal, prices usage.PriceTable, since time.Time, budget, monthTokens int64, settings locale.Settings) string {
+	var (
+		builder strings.Builder
+		overall usageBucket
+		days    = map[string]*usageBucket{}
+		users   = map[int64]*usageBucket{}
+	)
+
+	for _, t := range totals {
+		tokens := t.PromptTokens + t.CompletionTokens
+		cost, priced := prices.Cost(t.Model, t.PromptTokens, t.CompletionTokens)
+
+		label := t.Username
+		if label == "" {
+			label = settings.T("usage.user_id", t.UserID)
+		} else {
+			label = "@" + label
+		}
+
+		for _, bucket := range []*usageBucket{
+			&overall,
+			bucketFor(days, t.Day, t.Day),
+			bucketFor(users, t.UserID, label),
+		} {
+			bucket.calls += t.Calls
+			bucket.tokens += tokens
+			bucket.cost += cost
+			bucket.priced = bucket.priced || priced
+		}
+	}
+
+	builder.WriteString(settings.T("usage.heading", settings.Date(since), since.Location()) + "\n")
+	builder.WriteString(settings.T("usage.total", overall.describe(settings)) + "\n")
+	if budget > 0 {
+		builder.WriteString(settings.T("usage.budget",
+			formatCount(monthTokens, settings), formatCount(budget, settings), 100*float64(monthTokens)/float64(budget)) + "\n")
+	}
+	if overall.calls == 0 {
+		return strings.TrimRight(builder.String(), "\n")
+	}
+
+	builder.WriteString(settings.T("usage.by_day") + "\n")
+	for _, bucket := range sortedBuckets(days, func(a, b *usageBucket) bool { return a.label > b.label }) {
+		builder.WriteString(settings.T("common.list_amount", bucket.label, bucket.describe(settings)) + "\n")
+	}
+	builder.WriteString(settings.T("usage.by_user") + "\n")
+	for _, bucket := range sortedBuckets(users, func(a, b *usageBucket) bool { return a.tokens > b.tokens }) {
+		builder.WriteString(settings.T("common.list_amount", bucket.label, bucket.describe(settings)) + "\n")
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+func bucketFor[K comparable](buckets map[K]*usageBucket, key K, label string) *usageBucket {
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &usageBucket{label: label}
+		buckets[key] = bucket
+	}
+	return bucket
+}
+
+func sortedBuckets[K comparable](buckets map[K]*usageBucket, less func(a, b *usageBucket) bool) []*usageBucket {
+	sorted := make([]*usageBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sorted = append(sorted, bucket)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted
+}
+
+func (u usageBucket) describe(settings locale.Settings) string {
+	text := settings.T("usage.bucket", formatCount(u.tokens, settings), settings.Plural("count.calls", u.calls))
+	if u.priced {
+		// Prices are in US dollars whatever the user's currency.
+		text += settings.T("usage.cost", settings.Number(u.cost, 4))
+	}
+	return text
+}
+
+// formatCount renders an integer with thousands separators, e.g. 12,345, or 12.345 for
+// settings with a decimal comma.
+func formatCount(n int64, settings locale.Settings) string {
+	raw := strconv.FormatInt(n, 10)
+	if n < 0 {
+		return "-" + formatCount(-n, settings)
+	}
+	separator := ','
+	if settings.DecimalComma {
+		separator = '.'
+	}
+	var out strings.Builder
+	for i, digit := range raw {
+		if i > 0 && (len(raw)-i)%3 == 0 {
+			out.WriteRune(separator)
+		}
+		out.WriteRune(digit)
+	}
+	return out.String()
+}
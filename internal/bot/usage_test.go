@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/usage"
+)
+
+type fakeUsageStore struct {
+	totals []storage.UsageTotal
+	month  int64
+}
+
+func (f *fakeUsageStore) RecordUsage(context.Context, storage.LLMUsage) error { return nil }
+
+func (f *fakeUsageStore) UsageTotals(context.Context, time.Time, *time.Location) ([]storage.UsageTotal, error) {
+	return f.totals, nil
+}
+
+func (f *fakeUsageStore) TokensUsedSince(context.Context, time.Time) (int64, error) {
+	return f.month, nil
+}
+
+type adminSet map[string]bool
+
+func (a adminSet) IsAdmin(username string) bool { return a[username] }
+
+func TestUsageCommandReportsTokensAndCost(t *testing.T) {
+	api := &fakeAPI{}
+	store := &fakeUsageStore{
+		month: 250_000,
+		totals: []storage.UsageTotal{
+			{Day: "2026-10-17", UserID: 7, Username: "iamoxyrus", Model: "gpt-4o-mini", Calls: 3, PromptTokens: 900_000, CompletionTokens: 100_000},
+			{Day: "2026-10-18", UserID: 8, Username: "partner", Model: "gpt-4o-mini", Calls: 1, PromptTokens: 1000, CompletionTokens: 200},
+		},
+	}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{},
+		WithAdmins(adminSet{"iamoxyrus": true}),
+		WithUsageReports(store, usage.DefaultPrices(), 1_000_000),
+	)
+
+	b.handleUpdate(context.Background(), commandUpdate("/usage 7"))
+
+	if len(api.messages) != 1 {
+		t.Fatalf("expected usage report, got %#v", api.messages)
+	}
+	report := api.messages[0]
+	for _, want := range []string{
+		"Total: 1,001,200 tokens in 4 calls, ~$0.1953",
+		"Monthly budget: 250,000 of 1,000,000 tokens used (25%)",
+		"- 2026-10-18: 1,200 tokens in 1 call,",
+		"- @iamoxyrus: 1,000,000 tokens in 3 calls, ~$0.1950",
+		"- @partner:",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "2026-10-18") > strings.Index(report, "2026-10-17") {
+		t.Errorf("expected most recent day first, got:\n%s", report)
+	}
+}
+
+func TestUsageCommandRequiresAdmin(t *testing.T) {
+	api := &fakeAPI{}
+	b := New(api, allowAllAuthorizer{}, &fakeExtractor{}, &fakeStore{},
+		WithAdmins(adminSet{"someoneelse": true}),
+		WithUsageReports(&fakeUsageStore{}, usage.DefaultPrices(), 0),
+	)
+
+	b.handleUpdate(context.Background(), commandUpdate("/usage"))
+
+	if len(api.messages) != 1 || !strings.Contains(api.messages[0], "administrators") {
+		t.Fatalf("expected admin-only notice, got %#v", api.messages)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4200: "-4,200"} {
+		if got := formatCount(n, locale.Default()); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if got := formatCount(1234567, locale.Settings{DecimalComma: true}); got != "1.234.567" {
+		t.Errorf("expected dots with a decimal comma, got %q", got)
+	}
+}
+
+func TestUsageReportFollowsLanguage(t *testing.T) {
+	settings := locale.Default()
+	settings.Language = locale.Spanish
+	settings.DecimalComma = true
+	totals := []storage.UsageTotal{{Day: "2026-10-18", UserID: 8, Model: "gpt-4o-mini", Calls: 1, PromptTokens: 1000, CompletionTokens: 200}}
+
+	report := formatUsage(totals, usage.DefaultPrices(), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), 0, 0, settings)
+
+	for _, want := range []string{"Consumo de LLM desde el 2026-10-12 (UTC):", "Total: 1.200 tokens en 1 llamada", "- usuario 8: 1.200 tokens en 1 llamada, ~US$0,0003"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Package chart renders spending charts as PNG images in pure Go with a built-in bitmap
+// font, so output is deterministic and needs no external services.
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	width        = 800
+	titleHeight  = 56
+	pieHeight    = 380
+	barHeight    = 360
+	margin       = 24
+	maxSlices    = 7
+	otherLabel   = "Other"
+	legendSwatch = 12
+)
+
+// Point is one labeled value: a category in the pie chart or a period in the bar chart.
+type Point struct {
+	Label string
+	Value float64
+}
+
+// Report is the data behind a chart image.
+type Report struct {
+	Title string
+	// Categories are drawn as a pie chart; order does not matter.
+	Categories []Point
+	// BucketTitle describes Buckets, e.g. "Daily totals".
+	BucketTitle string
+	// Buckets are drawn as bars in the given order.
+	Buckets []Point
+	// Currency prefixes amounts, "$" when empty. DecimalComma writes 3,50 instead of 3.50.
+	Currency     string
+	DecimalComma bool
+	// OtherLabel names the slice small categories are folded into, "Other" when empty.
+	OtherLabel string
+}
+
+// money writes v with the report's currency and decimal separator.
+func (r Report) money(v float64, decimals int) string {
+	currency := r.Currency
+	if currency == "" {
+		currency = "$"
+	}
+	out := strconv.FormatFloat(v, 'f', decimals, 64)
+	if r.DecimalComma {
+		out = strings.Replace(out, ".", ",", 1)
+	}
+	return currency + out
+}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	ink        = color.RGBA{0x22, 0x2b, 0x36, 0xff}
+	muted      = color.RGBA{0x6b, 0x75, 0x80, 0xff}
+	grid       = color.RGBA{0xe3, 0xe7, 0xeb, 0xff}
+	barColor   = color.RGBA{0x2f, 0x80, 0xed, 0xff}
+
+	// palette colors pie slices; the last entry is reserved for the "Other" slice.
+	palette = []color.RGBA{
+		{0x2f, 0x80, 0xed, 0xff},
+		{0xeb, 0x57, 0x57, 0xff},
+		{0x27, 0xae, 0x60, 0xff},
+		{0xf2, 0x99, 0x4a, 0xff},
+		{0x9b, 0x51, 0xe0, 0xff},
+		{0x56, 0xcc, 0xf2, 0xff},
+		{0xf2, 0xc9, 0x4c, 0xff},
+		{0xbd, 0xbd, 0xbd, 0xff},
+	}
+)
+
+// Render writes report as a PNG image.
+func Render(w io.Writer, report Report) error {
+	if err := png.Encode(w, Draw(report)); err != nil {
+		return fmt.Errorf("chart: encode png: %w", err)
+	}
+	return nil
+}
+
+// Draw lays out the title, the category pie chart and the bucket bar chart.
+func Draw(report Report) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, titleHeight+pieHeight+barHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	drawText(img, margin, 36, report.Title, ink, 2)
+	drawPie(img, image.Rect(0, titleHeight, width, titleHeight+pieHeight), report)
+	drawBars(img, image.Rect(0, titleHeight+pieHeight, width, titleHeight+pieHeight+barHeight), report)
+	return img
+}
+
+// pieSlices sorts categories by value and folds everything past maxSlices into "Other".
+func pieSlices(categories []Point) []Point {
+	slices := make([]Point, 0, len(categories))
+	for _, c := range categories {
+		if c.Value > 0 {
+			slices = append(slices, c)
+		}
+	}
+	sort.SliceStable(slices, func(i, j int) bool {
+		if slices[i].Value == slices[j].Value {
+			return slices[i].Label < slices[j].Label
+		}
+		return slices[i].Value > slices[j].Value
+	})
+	if len(slices) <= maxSlices {
+		return slices
+	}
+	other := Point{Label: otherLabel}
+	for _, s := range slices[maxSlices-1:] {
+		other.Value += s.Value
+	}
+	return append(slices[:maxSlices-1], other)
+}
+
+func sliceColor(i int, p Point) color.RGBA {
+	if p.Label == otherLabel {
+		return palette[len(palette)-1]
+	}
+	return palette[i%(len(palette)-1)]
+}
+
+func drawPie(img *image.RGBA, area image.Rectangle, report Report) {
+	slices := pieSlices(report.Categories)
+	var total float64
+	for _, s := range slices {
+		total += s.Value
+	}
+	if total == 0 {
+		drawText(img, area.Min.X+margin, area.Min.Y+margin+13, "No expenses in this period.", muted, 1)
+		return
+	}
+
+	radius := (area.Dy() - 2*margin) / 2
+	cx, cy := area.Min.X+margin+radius, area.Min.Y+area.Dy()/2
+
+	// Cumulative end angle of each slice, clockwise from twelve o'clock.
+	ends := make([]float64, len(slices))
+	var acc float64
+	for i, s := range slices {
+		acc += s.Value
+		ends[i] = acc / total * 2 * math.Pi
+	}
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y > radius*radius {
+				continue
+			}
+			angle := math.Atan2(float64(x), float64(-y))
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			i := sort.SearchFloat64s(ends, angle)
+			if i >= len(slices) {
+				i = len(slices) - 1
+			}
+			img.SetRGBA(cx+x, cy+y, sliceColor(i, slices[i]))
+		}
+	}
+
+	legendX := cx + radius + 2*margin
+	legendY := cy - len(slices)*28/2
+	for i, s := range slices {
+		y := legendY + i*28
+		fillRect(img, image.Rect(legendX, y, legendX+legendSwatch, y+legendSwatch), sliceColor(i, s))
+		name := s.Label
+		if name == otherLabel && report.OtherLabel != "" {
+			name = report.OtherLabel
+		}
+		label := fmt.Sprintf("%s  %s (%.0f%%)", name, report.money(s.Value, 2), s.Value/total*100)
+		drawText(img, legendX+legendSwatch+8, y+legendSwatch-1, label, ink, 1)
+	}
+}
+
+func drawBars(img *image.RGBA, area image.Rectangle, report Report) {
+	buckets := report.Buckets
+	drawText(img, area.Min.X+margin, area.Min.Y+13, report.BucketTitle, ink, 1)
+
+	plot := image.Rect(area.Min.X+margin+64, area.Min.Y+margin+8, area.Max.X-margin, area.Max.Y-margin-16)
+	var peak float64
+	for _, b := range buckets {
+		peak = math.Max(peak, b.Value)
+	}
+	top := niceCeiling(peak)
+
+	const gridLines = 4
+	for i := 0; i <= gridLines; i++ {
+		y := plot.Max.Y - i*plot.Dy()/gridLines
+		fillRect(img, image.Rect(plot.Min.X, y, plot.Max.X, y+1), grid)
+		label := formatAxis(report, top*float64(i)/gridLines)
+		drawText(img, plot.Min.X-8-textWidth(label), y+4, label, muted, 1)
+	}
+	if len(buckets) == 0 {
+		return
+	}
+
+	slot := float64(plot.Dx()) / float64(len(buckets))
+	gap := math.Max(1, math.Floor(slot*0.2))
+	// Label at most about a dozen buckets so the axis stays legible.
+	labelEvery := (len(buckets) + 11) / 12
+	for i, b := range buckets {
+		x0 := plot.Min.X + int(math.Round(float64(i)*slot+gap/2))
+		x1 := plot.Min.X + int(math.Round(float64(i+1)*slot-gap/2))
+		if x1 <= x0 {
+			x1 = x0 + 1
+		}
+		if h := int(math.Round(b.Value / top * float64(plot.Dy()))); h > 0 {
+			fillRect(img, image.Rect(x0, plot.Max.Y-h, x1, plot.Max.Y), barColor)
+		}
+		if i%labelEvery == 0 {
+			center := (x0 + x1) / 2
+			drawText(img, center-textWidth(b.Label)/2, plot.Max.Y+18, b.Label, muted, 1)
+		}
+	}
+}
+
+// niceCeiling rounds v up to 1, 2, 2.5 or 5 times a power of ten so axis labels stay round.
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func formatAxis(report Report, v float64) string {
+	if v >= 1000 && math.Mod(v, 1000) == 0 {
+		return report.money(v/1000, 0) + "k"
+	}
+	if v == math.Trunc(v) {
+		return report.money(v, 0)
+	}
+	return report.money(v, 2)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+var face = basicfont.Face7x13
+
+func textWidth(text string) int {
+	return font.MeasureString(face, text).Ceil()
+}
+
+// drawText draws text with its baseline at y, scaled up by an integer factor.
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA, scale int) {
+	if scale <= 1 {
+		d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+		d.DrawString(text)
+		return
+	}
+
+	// Render at native size into a mask and scale it up with nearest-neighbour sampling.
+	metrics := face.Metrics()
+	ascent, descent := metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	mask := image.NewAlpha(image.Rect(0, 0, textWidth(text), ascent+descent))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, ascent)}
+	d.DrawString(text)
+
+	top := y - ascent*scale
+	bounds := img.Bounds()
+	for my := 0; my < mask.Rect.Dy(); my++ {
+		for mx := 0; mx < mask.Rect.Dx(); mx++ {
+			if mask.AlphaAt(mx, my).A == 0 {
+				continue
+			}
+			r := image.Rect(x+mx*scale, top+my*scale, x+(mx+1)*scale, top+(my+1)*scale).Intersect(bounds)
+			fillRect(img, r, c)
+		}
+	}
+}
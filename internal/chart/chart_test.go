@@ -0,0 +1,131 @@
+package chart
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden images in testdata")
+
+func TestRenderGolden(t *testing.T) {
+	cases := map[string]Report{
+		"daily": {
+			Title:       "Spending 2026-10-12 to 2026-10-18",
+			Categories:  []Point{{"Food", 82.5}, {"Transport", 30}, {"Coffee", 12.75}},
+			BucketTitle: "Daily totals",
+			Buckets: []Point{
+				{"10-12", 12.5}, {"10-13", 0}, {"10-14", 40}, {"10-15", 8.25},
+				{"10-16", 30}, {"10-17", 0}, {"10-18", 34.5},
+			},
+		},
+		"weekly_other": {
+			Title: "Spending 2026",
+			Categories: []Point{
+				{"Rent", 3600}, {"Food", 1450}, {"Travel", 900}, {"Transport", 420}, {"Coffee", 180},
+				{"Books", 95}, {"Gifts", 60}, {"Gym", 55}, {"Music", 30},
+			},
+			BucketTitle: "Weekly totals",
+			Buckets:     weeklyBuckets(20),
+		},
+		"empty": {
+			Title:       "Spending last 7 days",
+			BucketTitle: "Daily totals",
+			Buckets:     []Point{{"10-12", 0}, {"10-13", 0}},
+		},
+	}
+
+	for name, report := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, report); err != nil {
+				t.Fatalf("Render error: %v", err)
+			}
+			path := filepath.Join("testdata", name+".png")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			got, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatalf("decode rendered png: %v", err)
+			}
+			want := readGolden(t, path)
+			if diff := countDiff(got, want); diff != 0 {
+				t.Fatalf("%d pixels differ from %s; run go test ./internal/chart -update and inspect the image", diff, path)
+			}
+		})
+	}
+}
+
+func weeklyBuckets(n int) []Point {
+	buckets := make([]Point, n)
+	for i := range buckets {
+		buckets[i] = Point{Label: fmt.Sprintf("W%02d", i+1), Value: float64((i*37)%11) * 45}
+	}
+	return buckets
+}
+
+func readGolden(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open golden image (run with -update to create it): %v", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decode golden image: %v", err)
+	}
+	return img
+}
+
+func countDiff(a, b image.Image) int {
+	if a.Bounds() != b.Bounds() {
+		return a.Bounds().Dx() * a.Bounds().Dy()
+	}
+	diff := 0
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+			r1, g1, b1, a1 := a.At(x, y).RGBA()
+			r2, g2, b2, a2 := b.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				diff++
+			}
+		}
+	}
+	return diff
+}
+
+func TestPieSlicesFoldsSmallCategories(t *testing.T) {
+	var categories []Point
+	for i := 0; i < 10; i++ {
+		categories = append(categories, Point{Label: fmt.Sprintf("c%d", i), Value: float64(10 - i)})
+	}
+	categories = append(categories, Point{Label: "zero", Value: 0})
+
+	slices := pieSlices(categories)
+	if len(slices) != maxSlices {
+		t.Fatalf("expected %d slices, got %#v", maxSlices, slices)
+	}
+	if slices[0].Label != "c0" || slices[len(slices)-1].Label != otherLabel || slices[len(slices)-1].Value != 4+3+2+1 {
+		t.Fatalf("unexpected slices %#v", slices)
+	}
+}
+
+func TestNiceCeiling(t *testing.T) {
+	cases := map[float64]float64{0: 1, 0.3: 0.5, 7: 10, 12: 20, 23: 25, 40: 50, 180: 200, 950: 1000}
+	for in, want := range cases {
+		if got := niceCeiling(in); got != want {
+			t.Fatalf("niceCeiling(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -3,10 +3,18 @@ package config
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/Oxyrus/financebot/internal/analytics"
+	"github.com/Oxyrus/financebot/internal/importer"
+	"github.com/Oxyrus/financebot/internal/tracing"
+	"github.com/Oxyrus/financebot/internal/usage"
 )
 
 // Config captures runtime settings needed by the bot.
@@ -14,31 +22,221 @@ type Config struct {
 	TelegramToken string
 	OpenAIKey     string
 	DatabasePath  string
-	allowedUsers  map[string]struct{}
+	// ExtractorTimeout bounds each individual LLM call.
+	ExtractorTimeout time.Duration
+	// ExtractorRetries is how many times a rate-limited or failing LLM call is retried.
+	ExtractorRetries int
+	// CacheTTL and CacheSize bound the extraction cache; a zero size disables it.
+	CacheTTL  time.Duration
+	CacheSize int
+	// CachePersist stores cached extractions in SQLite so they survive restarts.
+	CachePersist bool
+	// LLMPrices estimates the cost of recorded token usage (USD per 1M tokens).
+	LLMPrices usage.PriceTable
+	// MonthlyTokenBudget stops LLM calls once reached; zero means unlimited.
+	MonthlyTokenBudget int64
+	// ImportMapping maps bank CSV columns for statement imports.
+	ImportMapping importer.CSVMapping
+	// ImportMatchWindow is how far apart in time a statement row may be from an existing
+	// expense with the same amount and still count as a duplicate.
+	ImportMatchWindow time.Duration
+	// ImportCategorize runs uncategorized statement rows through the extractor.
+	ImportCategorize bool
+	// DashboardAddr is the listen address of the web dashboard and the REST API, which
+	// share it; empty disables both.
+	DashboardAddr string
+	// DashboardURL is the public base URL used in dashboard login links.
+	DashboardURL string
+	// DashboardSecret signs login links and sessions; empty uses a random per-process key.
+	DashboardSecret string
+	// AnomalyThreshold is how many standard deviations above a user's usual amount for a
+	// category an expense must be to get flagged; zero disables anomaly alerts.
+	AnomalyThreshold float64
+	// MetricsAddr is the listen address of the Prometheus /metrics endpoint; empty
+	// disables it.
+	MetricsAddr string
+	// HealthAddr is the listen address of the /healthz and /readyz probes; empty disables
+	// them.
+	HealthAddr string
+	// HealthMaxStall is how long the update loop may go without coming round, e.g. while
+	// one update is being handled, before /healthz fails.
+	HealthMaxStall time.Duration
+	// TracingExporter selects where OpenTelemetry spans go; empty disables tracing.
+	TracingExporter tracing.Exporter
+	// LogLevel is the minimum level of log lines written.
+	LogLevel slog.Level
+	// LogContent logs expense descriptions and amounts instead of redacting them.
+	LogContent bool
+	// EncryptionKeys are the keys expense descriptions are encrypted with, as id:base64
+	// entries with the current key first; empty disables encryption. They come from
+	// ENCRYPTION_KEY or the file named by ENCRYPTION_KEY_FILE.
+	EncryptionKeys string
+	// EncryptCategory and EncryptAmount encrypt those expense fields too.
+	EncryptCategory bool
+	EncryptAmount   bool
+	allowedUsers    map[string]struct{}
+	adminUsers      map[string]struct{}
 }
 
-const defaultDatabasePath = "data/financebot.db"
+const (
+	defaultDatabasePath     = "data/financebot.db"
+	defaultExtractorTimeout = 15 * time.Second
+	defaultExtractorRetries = 2
+	defaultCacheTTL         = 24 * time.Hour
+	defaultCacheSize        = 1000
+	defaultHealthMaxStall   = 5 * time.Minute
+)
 
 // Load reads environment variables (optionally via .env) and validates them.
 func Load() (*Config, error) {
+	return load(true)
+}
+
+// LoadLocal is Load for CLI subcommands that only touch the database, so the Telegram
+// token and OpenAI key are not required.
+func LoadLocal() (*Config, error) {
+	return load(false)
+}
+
+func load(requireTokens bool) (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Println("no .env file found, reading environment variables directly")
 	}
 
+	extractorTimeout, err := parseDuration("EXTRACTOR_TIMEOUT", defaultExtractorTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	extractorRetries, err := parseInt("EXTRACTOR_RETRIES", defaultExtractorRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheTTL, err := parseDuration("EXTRACTION_CACHE_TTL", defaultCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	cacheSize, err := parseInt("EXTRACTION_CACHE_SIZE", defaultCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	cachePersist, err := parseBool("EXTRACTION_CACHE_PERSIST", false)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := usage.ParsePriceTable(os.Getenv("LLM_PRICES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LLM_PRICES: %w", err)
+	}
+	tokenBudget, err := parseInt("LLM_MONTHLY_TOKEN_BUDGET", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	importMapping, err := importer.ParseCSVMapping(os.Getenv("IMPORT_CSV_MAPPING"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMPORT_CSV_MAPPING: %w", err)
+	}
+	importWindow, err := parseDuration("IMPORT_MATCH_WINDOW", importer.DefaultMatchWindow)
+	if err != nil {
+		return nil, err
+	}
+	importCategorize, err := parseBool("IMPORT_CATEGORIZE", true)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalyThreshold, err := parseFloat("ANOMALY_THRESHOLD", analytics.DefaultThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	healthMaxStall, err := parseDuration("HEALTH_MAX_STALL", defaultHealthMaxStall)
+	if err != nil {
+		return nil, err
+	}
+
+	tracingExporter, err := tracing.ParseExporter(os.Getenv("TRACING_EXPORTER"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_EXPORTER: %w", err)
+	}
+
+	logLevel, err := parseLevel("LOG_LEVEL", slog.LevelInfo)
+	if err != nil {
+		return nil, err
+	}
+	logContent, err := parseBool("LOG_CONTENT", false)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKeys, err := loadEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	encryptCategory, err := parseBool("ENCRYPT_CATEGORY", false)
+	if err != nil {
+		return nil, err
+	}
+	encryptAmount, err := parseBool("ENCRYPT_AMOUNT", false)
+	if err != nil {
+		return nil, err
+	}
+	if encryptionKeys == "" && (encryptCategory || encryptAmount) {
+		return nil, fmt.Errorf("ENCRYPT_CATEGORY and ENCRYPT_AMOUNT need ENCRYPTION_KEY or ENCRYPTION_KEY_FILE")
+	}
+
 	cfg := &Config{
-		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
-		OpenAIKey:     os.Getenv("OPENAI_API_KEY"),
-		DatabasePath:  firstNonEmpty(os.Getenv("DATABASE_PATH"), defaultDatabasePath),
-		allowedUsers:  parseAllowedUsers(os.Getenv("AUTHORIZED_USERS")),
+		TelegramToken:      os.Getenv("TELEGRAM_TOKEN"),
+		OpenAIKey:          os.Getenv("OPENAI_API_KEY"),
+		DatabasePath:       firstNonEmpty(os.Getenv("DATABASE_PATH"), defaultDatabasePath),
+		ExtractorTimeout:   extractorTimeout,
+		ExtractorRetries:   extractorRetries,
+		CacheTTL:           cacheTTL,
+		CacheSize:          cacheSize,
+		CachePersist:       cachePersist,
+		LLMPrices:          prices,
+		MonthlyTokenBudget: int64(tokenBudget),
+		ImportMapping:      importMapping,
+		ImportMatchWindow:  importWindow,
+		ImportCategorize:   importCategorize,
+		DashboardAddr:      os.Getenv("DASHBOARD_ADDR"),
+		DashboardSecret:    os.Getenv("DASHBOARD_SECRET"),
+		MetricsAddr:        os.Getenv("METRICS_ADDR"),
+		HealthAddr:         os.Getenv("HEALTH_ADDR"),
+		HealthMaxStall:     healthMaxStall,
+		TracingExporter:    tracingExporter,
+		AnomalyThreshold:   anomalyThreshold,
+		LogLevel:           logLevel,
+		LogContent:         logContent,
+		EncryptionKeys:     encryptionKeys,
+		EncryptCategory:    encryptCategory,
+		EncryptAmount:      encryptAmount,
+		allowedUsers:       parseAllowedUsers(os.Getenv("AUTHORIZED_USERS")),
+		adminUsers:         parseAllowedUsers(os.Getenv("ADMIN_USERS")),
 	}
 
-	if cfg.TelegramToken == "" || cfg.OpenAIKey == "" {
+	if requireTokens && (cfg.TelegramToken == "" || cfg.OpenAIKey == "") {
 		return nil, fmt.Errorf("TELEGRAM_TOKEN or OPENAI_API_KEY not set")
 	}
 
+	if cfg.DashboardAddr != "" {
+		// Login links must point at the address users reach, which the listen address
+		// does not tell.
+		cfg.DashboardURL = os.Getenv("DASHBOARD_URL")
+		if requireTokens && cfg.DashboardURL == "" {
+			return nil, fmt.Errorf("DASHBOARD_URL must be set when DASHBOARD_ADDR is")
+		}
+	}
+
 	if len(cfg.allowedUsers) == 0 {
 		cfg.allowedUsers = map[string]struct{}{"iamoxyrus": {}}
 	}
+	if len(cfg.adminUsers) == 0 {
+		cfg.adminUsers = cfg.allowedUsers
+	}
 
 	return cfg, nil
 }
@@ -52,6 +250,13 @@ func (c *Config) IsUserAllowed(username string) bool {
 	return ok
 }
 
+// IsAdmin checks whether the provided Telegram handle may use administrative commands.
+// Without ADMIN_USERS every authorized user is an administrator.
+func (c *Config) IsAdmin(username string) bool {
+	_, ok := c.adminUsers[username]
+	return ok
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
@@ -72,3 +277,84 @@ func parseAllowedUsers(raw string) map[string]struct{} {
 	}
 	return users
 }
+
+func parseDuration(key string, fallback time.Duration) (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+	}
+	return d, nil
+}
+
+func parseInt(key string, fallback int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a non-negative integer", key, raw)
+	}
+	return n, nil
+}
+
+func parseFloat(key string, fallback float64) (float64, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a non-negative number", key, raw)
+	}
+	return f, nil
+}
+
+func parseLevel(key string, fallback slog.Level) (slog.Level, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(raw)); err != nil {
+		return 0, fmt.Errorf("invalid %s %q: expected debug, info, warn or error", key, raw)
+	}
+	return level, nil
+}
+
+// loadEncryptionKeys reads ENCRYPTION_KEY, or the file named by ENCRYPTION_KEY_FILE so
+// the keys can live outside the environment.
+func loadEncryptionKeys() (string, error) {
+	keys := strings.TrimSpace(os.Getenv("ENCRYPTION_KEY"))
+	path := strings.TrimSpace(os.Getenv("ENCRYPTION_KEY_FILE"))
+	switch {
+	case path == "":
+		return keys, nil
+	case keys != "":
+		return "", fmt.Errorf("set ENCRYPTION_KEY or ENCRYPTION_KEY_FILE, not both")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read ENCRYPTION_KEY_FILE: %w", err)
+	}
+	if keys = strings.TrimSpace(string(raw)); keys == "" {
+		return "", fmt.Errorf("ENCRYPTION_KEY_FILE %s is empty", path)
+	}
+	return keys, nil
+}
+
+func parseBool(key string, fallback bool) (bool, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: expected true or false", key, raw)
+	}
+	return b, nil
+}
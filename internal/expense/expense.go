@@ -1,20 +1,102 @@
 package expense
 
-import "fmt"
+import (
+	"strings"
+	"time"
 
-// Item represents a single categorized expense produced by the extractor.
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+const (
+	// MaxAmount caps the amount of a single expense or income.
+	MaxAmount = 1_000_000
+	// MaxCategoryLength caps the length of a category, in characters.
+	MaxCategoryLength = 40
+	// MaxDescriptionLength caps the length of a description, in characters.
+	MaxDescriptionLength = 200
+)
+
+// Kind tells money going out from money coming in.
+type Kind string
+
+const (
+	// KindExpense is money spent. The zero Kind means the same.
+	KindExpense Kind = "expense"
+	// KindIncome is money received, such as a salary or a refund.
+	KindIncome Kind = "income"
+)
+
+// ParseKind accepts "expense", "income" or "" (an expense), ignoring case and spaces.
+func ParseKind(raw string) (Kind, bool) {
+	switch Kind(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", KindExpense:
+		return KindExpense, true
+	case KindIncome:
+		return KindIncome, true
+	default:
+		return "", false
+	}
+}
+
+// Sign is -1 for expenses and +1 for income.
+func (k Kind) Sign() int {
+	if k == KindIncome {
+		return 1
+	}
+	return -1
+}
+
+// KindOf maps a stored sign back to a Kind.
+func KindOf(sign int) Kind {
+	if sign > 0 {
+		return KindIncome
+	}
+	return KindExpense
+}
+
+// Item represents a single categorized expense or income entry produced by the extractor.
 type Item struct {
 	Category    string  `json:"category"`
 	Amount      float64 `json:"amount"`
 	Description string  `json:"description"`
+	// Kind is the direction of the money; Amount is positive either way.
+	Kind Kind `json:"type,omitempty"`
+	// Account names how the entry was paid, such as "cash" or "visa". Stores match it
+	// against the user's accounts and fall back to their default account.
+	Account string `json:"account,omitempty"`
+
+	// Date is the day the expense happened when the text mentions one; zero means "now".
+	Date time.Time `json:"-"`
+	// LowConfidence marks items produced by a best-effort fallback rather than the LLM.
+	LowConfidence bool `json:"-"`
+	// Tags are normalized labels such as "vacation-2026", taken from #hashtags in the text.
+	Tags []string `json:"-"`
+}
+
+// IsIncome reports whether the item records money received.
+func (e Item) IsIncome() bool {
+	return e.Kind == KindIncome
 }
 
-// ReplyMessage formats a Telegram-friendly confirmation string.
-func (e Item) ReplyMessage() string {
-	return fmt.Sprintf(
-		"Recorded\nDescription: %s\nCategory: %s\nAmount: $%.2f",
-		e.Description,
-		e.Category,
-		e.Amount,
-	)
+// ReplyMessage formats a Telegram-friendly confirmation string in the user's language,
+// currency and time zone.
+func (e Item) ReplyMessage(s locale.Settings) string {
+	heading := s.T("expense.recorded")
+	if e.IsIncome() {
+		heading = s.T("expense.recorded_income")
+	}
+	msg := s.T("expense.reply", heading, e.Description, s.Category(e.Category), s.Money(e.Amount))
+	if !e.Date.IsZero() {
+		msg += s.T("expense.reply_date", s.Date(e.Date))
+	}
+	if e.Account != "" {
+		msg += s.T("expense.reply_account", e.Account)
+	}
+	if len(e.Tags) > 0 {
+		msg += s.T("expense.reply_tags", FormatTags(e.Tags))
+	}
+	if e.LowConfidence {
+		msg += s.T("expense.low_confidence")
+	}
+	return msg
 }
@@ -0,0 +1,74 @@
+package expense
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTagLength caps the length of a single tag.
+	MaxTagLength = 32
+	// MaxTags caps how many tags an expense carries.
+	MaxTags = 10
+)
+
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+	tagPattern     = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]*$`)
+)
+
+// NormalizeTag lowercases a tag and strips a leading '#'. It reports false for tags that
+// are empty, too long or contain anything but letters, digits, '-' and '_'.
+func NormalizeTag(raw string) (string, bool) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "#"))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength || !tagPattern.MatchString(tag) {
+		return "", false
+	}
+	return tag, true
+}
+
+// ExtractTags removes #hashtags from text and returns the remaining text together with
+// the normalized, de-duplicated tags in the order they appeared. Hashtags beyond MaxTags
+// or that fail NormalizeTag are left in the text.
+func ExtractTags(text string) (string, []string) {
+	var tags []string
+	rest := hashtagPattern.ReplaceAllStringFunc(text, func(match string) string {
+		tag, ok := NormalizeTag(strings.TrimSpace(match))
+		if !ok || len(tags) >= MaxTags {
+			return match
+		}
+		tags = AddTags(tags, tag)
+		return " "
+	})
+	return strings.Join(strings.Fields(rest), " "), tags
+}
+
+// AddTags appends the tags not already in list.
+func AddTags(list []string, tags ...string) []string {
+	for _, tag := range tags {
+		if !HasTag(list, tag) {
+			list = append(list, tag)
+		}
+	}
+	return list
+}
+
+// HasTag reports whether list contains tag, ignoring case.
+func HasTag(list []string, tag string) bool {
+	for _, t := range list {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatTags renders tags as space-separated hashtags, e.g. "#work #travel".
+func FormatTags(tags []string) string {
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		formatted[i] = "#" + tag
+	}
+	return strings.Join(formatted, " ")
+}
@@ -0,0 +1,166 @@
+// Package export writes stored expenses as CSV or JSON for spreadsheets and other tools.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+// Source streams expenses for an export; storage.ExpenseStore satisfies it.
+type Source interface {
+	ExportExpenses(ctx context.Context, filter storage.ExportFilter, fn func(storage.Expense) error) error
+}
+
+// ParseFormat accepts "csv" or "json" in any case.
+func ParseFormat(raw string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(raw))); f {
+	case CSV, JSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q: expected csv or json", raw)
+	}
+}
+
+// Extension returns the file extension for the format, including the dot.
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Record is the exported shape of one expense.
+type Record struct {
+	ID          int64     `json:"id"`
+	Date        time.Time `json:"date"`
+	UserID      int64     `json:"user_id"`
+	Category    string    `json:"category"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+	// Type is "expense" or "income"; Amount is positive either way.
+	Type string `json:"type"`
+	// Account is the name of the account it was paid with, or "" when unknown.
+	Account string `json:"account"`
+}
+
+var csvHeader = []string{"id", "date", "user_id", "category", "amount", "description", "type", "account"}
+
+func recordOf(e storage.Expense) Record {
+	return Record{
+		ID:          e.ID,
+		Date:        e.CreatedAt.UTC(),
+		UserID:      e.UserID,
+		Category:    e.Category,
+		Amount:      e.Amount,
+		Description: e.Description,
+		Type:        string(expense.KindOf(e.Kind.Sign())),
+		Account:     e.Account,
+	}
+}
+
+// Write streams the expenses matching filter from src to w and returns how many were written.
+func Write(ctx context.Context, src Source, w io.Writer, format Format, filter storage.ExportFilter) (int, error) {
+	switch format {
+	case CSV:
+		return writeCSV(ctx, src, w, filter)
+	case JSON:
+		return writeJSON(ctx, src, w, filter)
+	default:
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func writeCSV(ctx context.Context, src Source, w io.Writer, filter storage.ExportFilter) (int, error) {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return 0, fmt.Errorf("export: write csv header: %w", err)
+	}
+
+	count := 0
+	err := src.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		r := recordOf(e)
+		if err := out.Write([]string{
+			strconv.FormatInt(r.ID, 10),
+			r.Date.Format(time.RFC3339),
+			strconv.FormatInt(r.UserID, 10),
+			spreadsheetSafe(r.Category),
+			strconv.FormatFloat(r.Amount, 'f', -1, 64),
+			spreadsheetSafe(r.Description),
+			r.Type,
+			spreadsheetSafe(r.Account),
+		}); err != nil {
+			return fmt.Errorf("export: write csv row: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return count, fmt.Errorf("export: flush csv: %w", err)
+	}
+	return count, nil
+}
+
+// spreadsheetSafe defuses text that spreadsheet apps would evaluate as a formula, since
+// descriptions come straight from chat messages.
+func spreadsheetSafe(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func writeJSON(ctx context.Context, src Source, w io.Writer, filter storage.ExportFilter) (int, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, fmt.Errorf("export: write json: %w", err)
+	}
+
+	count := 0
+	err := src.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		data, err := json.Marshal(recordOf(e))
+		if err != nil {
+			return fmt.Errorf("export: encode expense %d: %w", e.ID, err)
+		}
+		sep := ",\n  "
+		if count == 0 {
+			sep = "\n  "
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return fmt.Errorf("export: write json: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("export: write json: %w", err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	closing := "]\n"
+	if count > 0 {
+		closing = "\n]\n"
+	}
+	if _, err := io.WriteString(w, closing); err != nil {
+		return count, fmt.Errorf("export: write json: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func seededStore(t *testing.T) *memory.Store {
+	t.Helper()
+	store := memory.NewStore()
+	if _, err := store.CreateAccount(context.Background(), storage.Account{UserID: 7, Name: "Visa", Type: storage.AccountCredit}); err != nil {
+		t.Fatalf("CreateAccount error: %v", err)
+	}
+	items := []struct {
+		user int64
+		item expense.Item
+	}{
+		{7, expense.Item{Category: "Food", Amount: 12.5, Description: "Lunch, with \"friends\"", Date: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)}},
+		{8, expense.Item{Category: "Refund", Amount: 3, Description: "=HYPERLINK(\"x\")", Kind: expense.KindIncome, Date: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)}},
+		{7, expense.Item{Category: "Travel", Amount: 40, Description: "Taxi", Date: time.Date(2026, 9, 20, 8, 0, 0, 0, time.UTC)}},
+	}
+	for _, s := range items {
+		if _, err := store.SaveExpense(context.Background(), s.user, s.item); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	filter := storage.ExportFilter{Since: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+
+	n, err := Write(context.Background(), seededStore(t), &buf, CSV, filter)
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows, got %d", n)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		csvHeader,
+		{"2", "2026-10-01T08:00:00Z", "8", "Refund", "3", "'=HYPERLINK(\"x\")", "income", ""},
+		{"1", "2026-10-02T12:00:00Z", "7", "Food", "12.5", "Lunch, with \"friends\"", "expense", "Visa"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %#v", len(want), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Fatalf("row %d: expected %q, got %q", i, want[i], rows[i])
+			}
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := Write(context.Background(), seededStore(t), &buf, JSON, storage.ExportFilter{UserID: 7})
+	if err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+
+	var records []Record
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if n != 2 || len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %#v", n, records)
+	}
+	if records[0].Description != "Taxi" || records[0].Type != "expense" || records[1].Amount != 12.5 || records[1].UserID != 7 {
+		t.Fatalf("unexpected records %#v", records)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Write(context.Background(), memory.NewStore(), &buf, JSON, storage.ExportFilter{}); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Fatalf("expected empty array, got %q", buf.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWritePropagatesWriterErrors(t *testing.T) {
+	if _, err := Write(context.Background(), seededStore(t), failingWriter{}, JSON, storage.ExportFilter{}); err == nil {
+		t.Fatal("expected writer error")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(" JSON "); err != nil || f != JSON {
+		t.Fatalf("expected JSON, got %q (%v)", f, err)
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
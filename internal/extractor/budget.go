@@ -0,0 +1,79 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/query"
+	"github.com/Oxyrus/financebot/internal/usage"
+)
+
+// ErrBudgetExhausted is returned without calling the LLM once the monthly token budget is spent.
+var ErrBudgetExhausted = errors.New("monthly LLM token budget exhausted")
+
+// TokenCounter reports how many LLM tokens were consumed since a point in time.
+type TokenCounter interface {
+	TokensUsedSince(ctx context.Context, since time.Time) (int64, error)
+}
+
+// Budget stops calling the wrapped Service once the tokens used in the current calendar
+// month (UTC) reach the limit, so a Fallback can take over until the month rolls over.
+type Budget struct {
+	next    Service
+	counter TokenCounter
+	limit   int64
+	now     func() time.Time
+}
+
+var (
+	_ Service        = (*Budget)(nil)
+	_ QueryExtractor = (*Budget)(nil)
+)
+
+// NewBudget wraps next with a monthly token limit; a non-positive limit disables the check.
+func NewBudget(next Service, counter TokenCounter, limit int64) *Budget {
+	return &Budget{
+		next:    next,
+		counter: counter,
+		limit:   limit,
+		now:     time.Now,
+	}
+}
+
+// Extract calls the wrapped Service while budget remains.
+func (b *Budget) Extract(ctx context.Context, text string) (expense.Item, error) {
+	if err := b.check(ctx); err != nil {
+		return expense.Item{}, err
+	}
+	return b.next.Extract(ctx, text)
+}
+
+// ExtractQuery implements QueryExtractor; questions draw on the same budget as expenses.
+func (b *Budget) ExtractQuery(ctx context.Context, text string) (query.Query, error) {
+	next, ok := b.next.(QueryExtractor)
+	if !ok {
+		return query.Query{}, fmt.Errorf("%w: %T", errQueriesUnsupported, b.next)
+	}
+	if err := b.check(ctx); err != nil {
+		return query.Query{}, err
+	}
+	return next.ExtractQuery(ctx, text)
+}
+
+// check fails once the month's tokens reach the limit.
+func (b *Budget) check(ctx context.Context) error {
+	if b.limit <= 0 {
+		return nil
+	}
+	used, err := b.counter.TokensUsedSince(ctx, usage.MonthStart(b.now()))
+	if err != nil {
+		return fmt.Errorf("check token budget: %w", err)
+	}
+	if used >= b.limit {
+		return ErrBudgetExhausted
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+type fixedCounter struct {
+	used  int64
+	since time.Time
+}
+
+func (f *fixedCounter) TokensUsedSince(_ context.Context, since time.Time) (int64, error) {
+	f.since = since
+	return f.used, nil
+}
+
+func TestBudgetAllowsCallsUnderLimit(t *testing.T) {
+	next := &countingService{item: expense.Item{Category: "Food", Amount: 1, Description: "x"}}
+	counter := &fixedCounter{used: 999}
+	budget := NewBudget(next, counter, 1000)
+	budget.now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	if _, err := budget.Extract(context.Background(), "x 1"); err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected call to go through, got %d", next.calls)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !counter.since.Equal(want) {
+		t.Fatalf("expected month start %v, got %v", want, counter.since)
+	}
+}
+
+func TestBudgetStopsCallsWhenExhausted(t *testing.T) {
+	next := &countingService{}
+	budget := NewBudget(next, &fixedCounter{used: 1000}, 1000)
+
+	if _, err := budget.Extract(context.Background(), "x 1"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", err)
+	}
+	if next.calls != 0 {
+		t.Fatalf("expected no LLM call, got %d", next.calls)
+	}
+
+	// An exhausted budget hands over to the rule-based fallback.
+	item, err := NewFallback(budget, NewRules(), 0).Extract(context.Background(), "coffee $3.50")
+	if err != nil || !item.LowConfidence || item.Amount != 3.5 {
+		t.Fatalf("expected fallback item, got %#v (%v)", item, err)
+	}
+}
+
+func TestBudgetDisabled(t *testing.T) {
+	next := &countingService{}
+	if _, err := NewBudget(next, nil, 0).Extract(context.Background(), "x"); err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected call without budget, got %d", next.calls)
+	}
+}
+
+func TestBudgetCoversQueries(t *testing.T) {
+	budget := NewBudget(NewRules(), &fixedCounter{used: 1000}, 1000)
+	if _, err := budget.ExtractQuery(context.Background(), "how much on coffee?"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", err)
+	}
+
+	if _, err := NewBudget(&countingService{}, nil, 0).ExtractQuery(context.Background(), "how much?"); !errors.Is(err, errQueriesUnsupported) {
+		t.Fatalf("expected a Service without queries to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package extractor
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+// CacheStore persists extraction results across restarts. Keys are opaque hashes of the
+// normalized input text.
+type CacheStore interface {
+	GetCachedExtraction(ctx context.Context, key string, notBefore time.Time) (expense.Item, bool, error)
+	PutCachedExtraction(ctx context.Context, key string, item expense.Item, at time.Time) error
+	PruneCachedExtractions(ctx context.Context, olderThan time.Time, keep int) error
+}
+
+// CacheConfig bounds the extraction cache. A zero TTL keeps entries until they are evicted.
+type CacheConfig struct {
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// Cache decorates a Service with an in-memory LRU of extraction results keyed on normalized
+// text, optionally backed by a persistent CacheStore.
+type Cache struct {
+	next       Service
+	cfg        CacheConfig
+	persistent CacheStore
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key      string
+	item     expense.Item
+	storedAt time.Time
+}
+
+var _ Service = (*Cache)(nil)
+
+// NewCache wraps next with a bounded cache. persistent may be nil for memory-only caching.
+func NewCache(next Service, cfg CacheConfig, persistent CacheStore) *Cache {
+	return &Cache{
+		next:       next,
+		cfg:        cfg,
+		persistent: persistent,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Extract returns a cached result for equivalent text, or calls the wrapped Service.
+func (c *Cache) Extract(ctx context.Context, text string) (expense.Item, error) {
+	normalized := normalizeText(text)
+	if normalized == "" {
+		return c.next.Extract(ctx, text)
+	}
+	key := cacheKey(normalized)
+	now := c.now()
+
+	if item, ok := c.lookup(key, now); ok {
+		return item, nil
+	}
+	if c.persistent != nil {
+		item, ok, err := c.persistent.GetCachedExtraction(ctx, key, c.notBefore(now))
+		if err != nil {
+			slog.WarnContext(ctx, "extraction cache lookup", "err", err)
+		} else if ok {
+			c.remember(key, item, now)
+			return item, nil
+		}
+	}
+
+	item, err := c.next.Extract(ctx, text)
+	if err != nil {
+		return item, err
+	}
+	if !cacheable(item) {
+		return item, nil
+	}
+
+	c.remember(key, item, now)
+	if c.persistent != nil {
+		if err := c.persistent.PutCachedExtraction(ctx, key, item, now); err != nil {
+			slog.WarnContext(ctx, "extraction cache store", "err", err)
+		} else if err := c.persistent.PruneCachedExtractions(ctx, c.notBefore(now), c.cfg.MaxEntries); err != nil {
+			slog.WarnContext(ctx, "extraction cache prune", "err", err)
+		}
+	}
+	return item, nil
+}
+
+// notBefore is the oldest storedAt still fresh at now. Without a TTL it is the zero time,
+// so nothing expires.
+func (c *Cache) notBefore(now time.Time) time.Time {
+	if c.cfg.TTL <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-c.cfg.TTL)
+}
+
+// cacheable excludes fallback guesses and results tied to a relative date like "yesterday".
+func cacheable(item expense.Item) bool {
+	return !item.LowConfidence && item.Date.IsZero()
+}
+
+func (c *Cache) lookup(key string, now time.Time) (expense.Item, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return expense.Item{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.cfg.TTL > 0 && now.Sub(entry.storedAt) > c.cfg.TTL {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return expense.Item{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.item, true
+}
+
+func (c *Cache) remember(key string, item expense.Item, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &cacheEntry{key: key, item: item, storedAt: now}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, item: item, storedAt: now})
+
+	for c.cfg.MaxEntries > 0 && c.order.Len() > c.cfg.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// normalizeText lowercases text, collapses whitespace and drops trailing punctuation so
+// trivially different messages ("Coffee 3.5!" and "coffee  3.5") compare equal.
+func normalizeText(text string) string {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	return strings.TrimRight(text, ".!?, ")
+}
+
+func cacheKey(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+type countingService struct {
+	calls int
+	item  expense.Item
+	err   error
+}
+
+func (s *countingService) Extract(context.Context, string) (expense.Item, error) {
+	s.calls++
+	return s.item, s.err
+}
+
+type memoryCacheStore struct {
+	items  map[string]expense.Item
+	stored map[string]time.Time
+}
+
+func (m *memoryCacheStore) GetCachedExtraction(_ context.Context, key string, notBefore time.Time) (expense.Item, bool, error) {
+	item, ok := m.items[key]
+	if ok && m.stored[key].Before(notBefore) {
+		return expense.Item{}, false, nil
+	}
+	return item, ok, nil
+}
+
+func (m *memoryCacheStore) PutCachedExtraction(_ context.Context, key string, item expense.Item, at time.Time) error {
+	m.items[key] = item
+	if m.stored == nil {
+		m.stored = make(map[string]time.Time)
+	}
+	m.stored[key] = at
+	return nil
+}
+
+func (m *memoryCacheStore) PruneCachedExtractions(context.Context, time.Time, int) error { return nil }
+
+func TestCacheHitsOnNormalizedText(t *testing.T) {
+	next := &countingService{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"}}
+	cache := NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 10}, nil)
+
+	for _, text := range []string{"coffee 3.5", "Coffee  3.5!", "  COFFEE 3.5 "} {
+		item, err := cache.Extract(context.Background(), text)
+		if err != nil {
+			t.Fatalf("Extract(%q) error: %v", text, err)
+		}
+		if item.Amount != 3.5 {
+			t.Fatalf("unexpected item %#v", item)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected a single upstream call, got %d", next.calls)
+	}
+}
+
+func TestCacheExpiresAfterTTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &countingService{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"}}
+	cache := NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 10}, nil)
+	cache.now = func() time.Time { return now }
+
+	_, _ = cache.Extract(context.Background(), "coffee 3.5")
+	now = now.Add(2 * time.Hour)
+	_, _ = cache.Extract(context.Background(), "coffee 3.5")
+
+	if next.calls != 2 {
+		t.Fatalf("expected expired entry to be refreshed, got %d calls", next.calls)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	next := &countingService{item: expense.Item{Category: "Food", Amount: 1, Description: "x"}}
+	cache := NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 2}, nil)
+	ctx := context.Background()
+
+	_, _ = cache.Extract(ctx, "a 1")
+	_, _ = cache.Extract(ctx, "b 1")
+	_, _ = cache.Extract(ctx, "a 1") // a is now most recently used
+	_, _ = cache.Extract(ctx, "c 1") // evicts b
+	_, _ = cache.Extract(ctx, "a 1")
+	if next.calls != 3 {
+		t.Fatalf("expected a to stay cached, got %d calls", next.calls)
+	}
+	_, _ = cache.Extract(ctx, "b 1")
+	if next.calls != 4 {
+		t.Fatalf("expected b to be evicted, got %d calls", next.calls)
+	}
+}
+
+func TestCacheSkipsErrorsAndLowConfidence(t *testing.T) {
+	next := &countingService{err: errors.New("boom")}
+	cache := NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 10}, nil)
+	ctx := context.Background()
+
+	_, _ = cache.Extract(ctx, "coffee 3.5")
+	next.err = nil
+	next.item = expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee", LowConfidence: true}
+	_, _ = cache.Extract(ctx, "coffee 3.5")
+	_, _ = cache.Extract(ctx, "coffee 3.5")
+
+	if next.calls != 3 {
+		t.Fatalf("expected failures and low-confidence results to bypass the cache, got %d calls", next.calls)
+	}
+}
+
+func TestCacheUsesPersistentStore(t *testing.T) {
+	persistent := &memoryCacheStore{items: make(map[string]expense.Item)}
+	next := &countingService{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"}}
+
+	_, _ = NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 10}, persistent).Extract(context.Background(), "coffee 3.5")
+
+	// A fresh cache (e.g. after a restart) is warmed from the persistent store.
+	item, err := NewCache(next, CacheConfig{TTL: time.Hour, MaxEntries: 10}, persistent).Extract(context.Background(), "Coffee 3.5")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if next.calls != 1 || item.Category != "Coffee" {
+		t.Fatalf("expected persistent hit, got %d calls and %#v", next.calls, item)
+	}
+}
+
+func TestCacheZeroTTLNeverExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	persistent := &memoryCacheStore{items: make(map[string]expense.Item)}
+	next := &countingService{item: expense.Item{Category: "Coffee", Amount: 3.5, Description: "Coffee"}}
+
+	first := NewCache(next, CacheConfig{MaxEntries: 10}, persistent)
+	first.now = func() time.Time { return now }
+	_, _ = first.Extract(context.Background(), "coffee 3.5")
+
+	now = now.Add(30 * 24 * time.Hour)
+	second := NewCache(next, CacheConfig{MaxEntries: 10}, persistent)
+	second.now = func() time.Time { return now }
+	_, _ = second.Extract(context.Background(), "coffee 3.5")
+
+	if next.calls != 1 {
+		t.Fatalf("expected a cache without TTL to keep hitting the persistent store, got %d calls", next.calls)
+	}
+}
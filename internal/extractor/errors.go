@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+// ErrInvalidResponse is returned when the provider answers with something that is not an expense.
+var ErrInvalidResponse = errors.New("failed to parse GPT response")
+
+// IsRetryable reports whether the same text might extract successfully later, i.e. the
+// failure came from the provider being unavailable rather than from the input itself.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || isTransient(err)
+}
+
+// UserMessage classifies an extraction error into a short, user-facing explanation in the
+// user's language.
+// The raw error is meant for logs; it may contain provider details users should not see.
+func UserMessage(err error, s locale.Settings) string {
+	reason := Reason(err)
+	if reason == "too_long" {
+		return s.T("extract.too_long", MaxInputLength)
+	}
+	return s.T("extract." + reason)
+}
+
+// Reason classifies an extraction error into a short, stable label such as "timeout" or
+// "no_amount", for metrics and message lookups.
+func Reason(err error) string {
+	switch status := httpStatus(err); {
+	case errors.Is(err, ErrInputTooLong):
+		return "too_long"
+	case errors.Is(err, ErrBudgetExhausted):
+		return "budget_exhausted"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case status == http.StatusTooManyRequests:
+		return "rate_limited"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "unauthorized"
+	case status >= http.StatusInternalServerError:
+		return "server_error"
+	case errors.Is(err, ErrNoAmount):
+		return "no_amount"
+	case errors.Is(err, ErrInvalidResponse):
+		return "invalid_response"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,62 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+// Fallback tries a primary Service first and falls back to a secondary one when the
+// primary fails or does not answer within the configured timeout.
+type Fallback struct {
+	primary   Service
+	secondary Service
+	timeout   time.Duration
+}
+
+var _ Service = (*Fallback)(nil)
+
+// DefaultFallbackTimeout bounds the primary extractor when NewFallback is given a zero
+// timeout. It leaves room for the default attempts and backoff of Resilient.
+const DefaultFallbackTimeout = time.Minute
+
+// NewFallback composes two extractors. A zero timeout uses DefaultFallbackTimeout; a
+// negative one leaves the caller's deadline untouched.
+func NewFallback(primary, secondary Service, timeout time.Duration) *Fallback {
+	if timeout == 0 {
+		timeout = DefaultFallbackTimeout
+	}
+	return &Fallback{
+		primary:   primary,
+		secondary: secondary,
+		timeout:   timeout,
+	}
+}
+
+// Extract returns the primary result, or the secondary result marked as low confidence.
+func (f *Fallback) Extract(ctx context.Context, text string) (expense.Item, error) {
+	primaryCtx := ctx
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		primaryCtx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+
+	item, err := f.primary.Extract(primaryCtx, text)
+	if err == nil {
+		return item, nil
+	}
+	if ctx.Err() != nil {
+		// The caller gave up; there is nobody left to answer.
+		return expense.Item{}, err
+	}
+
+	fallbackItem, fallbackErr := f.secondary.Extract(ctx, text)
+	if fallbackErr != nil {
+		return expense.Item{}, fmt.Errorf("%w (fallback: %w)", err, fallbackErr)
+	}
+	fallbackItem.LowConfidence = true
+	return fallbackItem, nil
+}
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+type funcService func(ctx context.Context, text string) (expense.Item, error)
+
+func (f funcService) Extract(ctx context.Context, text string) (expense.Item, error) {
+	return f(ctx, text)
+}
+
+func TestFallbackUsesPrimaryResult(t *testing.T) {
+	primary := funcService(func(context.Context, string) (expense.Item, error) {
+		return expense.Item{Category: "Food", Amount: 5, Description: "Lunch"}, nil
+	})
+	secondary := funcService(func(context.Context, string) (expense.Item, error) {
+		t.Fatal("secondary should not be called")
+		return expense.Item{}, nil
+	})
+
+	item, err := NewFallback(primary, secondary, 0).Extract(context.Background(), "lunch 5")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if item.LowConfidence {
+		t.Fatal("primary result should not be marked low confidence")
+	}
+}
+
+func TestFallbackOnPrimaryError(t *testing.T) {
+	primary := funcService(func(context.Context, string) (expense.Item, error) {
+		return expense.Item{}, errors.New("rate limited")
+	})
+
+	item, err := NewFallback(primary, NewRules(), 0).Extract(context.Background(), "coffee $3.50")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if item.Amount != 3.5 || item.Category != "Coffee" || !item.LowConfidence {
+		t.Fatalf("unexpected fallback item %#v", item)
+	}
+}
+
+func TestFallbackOnPrimaryTimeout(t *testing.T) {
+	primary := funcService(func(ctx context.Context, _ string) (expense.Item, error) {
+		<-ctx.Done()
+		return expense.Item{}, ctx.Err()
+	})
+
+	item, err := NewFallback(primary, NewRules(), 10*time.Millisecond).Extract(context.Background(), "taxi 20")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if item.Amount != 20 || !item.LowConfidence {
+		t.Fatalf("unexpected fallback item %#v", item)
+	}
+}
+
+func TestFallbackZeroTimeoutUsesDefault(t *testing.T) {
+	if f := NewFallback(NewRules(), NewRules(), 0); f.timeout != DefaultFallbackTimeout {
+		t.Fatalf("expected the default timeout, got %v", f.timeout)
+	}
+	if f := NewFallback(NewRules(), NewRules(), -1); f.timeout > 0 {
+		t.Fatalf("expected a negative timeout to disable the deadline, got %v", f.timeout)
+	}
+}
+
+func TestFallbackBothFail(t *testing.T) {
+	primary := funcService(func(context.Context, string) (expense.Item, error) {
+		return expense.Item{}, errors.New("openai down")
+	})
+
+	_, err := NewFallback(primary, NewRules(), 0).Extract(context.Background(), "no numbers here")
+	if err == nil {
+		t.Fatal("expected error when both extractors fail")
+	}
+	if !strings.Contains(err.Error(), "openai down") || !strings.Contains(err.Error(), ErrNoAmount.Error()) {
+		t.Fatalf("expected both failures in error, got %v", err)
+	}
+}
@@ -1,11 +1,18 @@
 package extractor
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"strings"
 	"testing"
 
 	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/Oxyrus/financebot/internal/logging"
+	"github.com/Oxyrus/financebot/internal/reqctx"
+	"github.com/Oxyrus/financebot/internal/storage"
 )
 
 type stubClient struct {
@@ -51,6 +58,44 @@ func TestOpenAIExtractSuccess(t *testing.T) {
 	}
 }
 
+func TestOpenAIExtractIncome(t *testing.T) {
+	client := &stubClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: `{"category":"Salary","amount":3000,"description":"October salary","type":"Income"}`}},
+			},
+		},
+	}
+	extractor := &OpenAI{client: client, model: "test-model"}
+
+	item, err := extractor.Extract(context.Background(), "salary 3000")
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if !item.IsIncome() || item.Amount != 3000 {
+		t.Fatalf("expected income, got %#v", item)
+	}
+}
+
+func TestOpenAIExtractAccount(t *testing.T) {
+	client := &stubClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: `{"category":"Coffee","amount":3.5,"description":"Latte","account":"  Debit   Card "}`}},
+			},
+		},
+	}
+	extractor := &OpenAI{client: client, model: "test-model"}
+
+	item, err := extractor.Extract(context.Background(), "latte 3.50 with my debit card")
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if item.Account != "debit card" {
+		t.Fatalf("expected normalized account, got %q", item.Account)
+	}
+}
+
 func TestOpenAIExtractPropagatesErrors(t *testing.T) {
 	client := &stubClient{err: errors.New("openai error")}
 	extractor := &OpenAI{client: client, model: "test-model"}
@@ -90,3 +135,82 @@ func TestOpenAIExtractInvalidJSON(t *testing.T) {
 		t.Fatal("expected JSON parsing error")
 	}
 }
+
+type recordingUsage struct {
+	records []storage.LLMUsage
+}
+
+func (r *recordingUsage) RecordUsage(_ context.Context, usage storage.LLMUsage) error {
+	r.records = append(r.records, usage)
+	return nil
+}
+
+func TestOpenAIExtractRecordsUsage(t *testing.T) {
+	client := &stubClient{
+		response: openai.ChatCompletionResponse{
+			Model: "gpt-4o-mini-2024-07-18",
+			Usage: openai.Usage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150},
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: `{"category":"Food","amount":12.5,"description":"Lunch"}`}},
+			},
+		},
+	}
+	recorder := &recordingUsage{}
+	extractor := &OpenAI{client: client, model: "test-model", usage: recorder}
+
+	ctx := reqctx.WithUser(context.Background(), reqctx.User{ID: 7, Username: "iamoxyrus"})
+	if _, err := extractor.Extract(ctx, "lunch 12.5"); err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	if len(recorder.records) != 1 {
+		t.Fatalf("expected one usage record, got %d", len(recorder.records))
+	}
+	got := recorder.records[0]
+	if got.Model != "gpt-4o-mini-2024-07-18" || got.PromptTokens != 120 || got.CompletionTokens != 30 {
+		t.Fatalf("unexpected usage record %#v", got)
+	}
+	if got.UserID != 7 || got.Username != "iamoxyrus" || got.CreatedAt.IsZero() {
+		t.Fatalf("expected user attribution, got %#v", got)
+	}
+}
+
+func TestOpenAIExtractSkipsUsageOnError(t *testing.T) {
+	recorder := &recordingUsage{}
+	extractor := &OpenAI{client: &stubClient{err: errors.New("openai error")}, model: "test-model", usage: recorder}
+
+	if _, err := extractor.Extract(context.Background(), "text"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(recorder.records) != 0 {
+		t.Fatalf("expected no usage for failed calls, got %#v", recorder.records)
+	}
+}
+
+func TestInvalidResponseKeepsContentOutOfLogs(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, logging.Config{Level: slog.LevelDebug}))
+	defer slog.SetDefault(previous)
+
+	ctx := context.Background()
+	for _, content := range []string{
+		`{"category":"Health","amount":120,"description":"therapy session"`,
+		`{"category":"Health","amount":987654321,"description":"therapy session"}`,
+	} {
+		_, err := (&OpenAI{client: respondWith(content), model: "test-model"}).Extract(ctx, "therapy session 120")
+		if !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("expected ErrInvalidResponse, got %v", err)
+		}
+		slog.WarnContext(ctx, "extract expense", "reason", Reason(err), "err", err)
+	}
+
+	for _, secret := range []string{"therapy", "987654321"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Fatalf("expected %q to stay out of the logs, got %s", secret, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), `"reason":"invalid_response"`) {
+		t.Fatalf("expected the failure reason to be logged, got %s", buf.String())
+	}
+}
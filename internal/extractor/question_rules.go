@@ -0,0 +1,138 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/query"
+)
+
+// Question patterns accept English and Spanish, like the expense patterns in rules.go.
+var (
+	monthPattern    = regexp.MustCompile(`(?i)\b(?:in\s+|en\s+)?(january|february|march|april|may|june|july|august|september|october|november|december|enero|febrero|marzo|abril|mayo|junio|julio|agosto|septiembre|setiembre|octubre|noviembre|diciembre)(?:\s+(?:de\s+)?(\d{4}))?\b`)
+	yearPattern     = regexp.MustCompile(`\b(?:in\s+|en\s+)?(20\d{2})\b`)
+	lastDaysPattern = regexp.MustCompile(`(?i)(?:^|\s)(?:in\s+|en\s+)?(?:the\s+|los\s+)?(?:last|past|[uú]ltimos|pasados)\s+(\d{1,3})\s+(days?|d[ií]as)(?:$|[^\p{L}])`)
+	periodPattern   = regexp.MustCompile(`(?i)\b(today|yesterday|(?:this|last)\s+(?:week|month|year)|hoy|ayer|esta\s+semana|(?:la\s+)?semana\s+pasada|este\s+(?:mes|a[nñ]o)|(?:el\s+)?(?:mes|a[nñ]o)\s+pasado)\b`)
+	subjectPattern  = regexp.MustCompile(`(?i)\b(?:on|for|at|en)\s+([\p{L}\d][\p{L}\d' -]*)`)
+	noisePattern    = regexp.MustCompile(`(?i)\b(?:did|do|i|we|my|the|a|an|spend|spent|pay|paid|in|total|so far|expenses?|purchases?|mis?|el|la|los|las|un|una|gastos|compras)\b`)
+)
+
+// ExtractQuery reads the aggregation from keywords such as "how many" or "biggest", the
+// period from phrases such as "in September" or "last week", and the subject from category
+// keywords, a #hashtag or an "on ..." phrase. It never calls out to a model. Periods follow
+// the time zone and week start of the locale.Settings in ctx.
+func (r *Rules) ExtractQuery(ctx context.Context, text string) (query.Query, error) {
+	if err := checkInput(text); err != nil {
+		return query.Query{}, err
+	}
+	remaining := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "?"))
+
+	var q query.Query
+	remaining, tags := expense.ExtractTags(remaining)
+	if len(tags) > 0 {
+		q.Tag = tags[0]
+	}
+	q.Aggregation = parseAggregation(remaining)
+	q.Since, q.Until, remaining = r.parsePeriod(remaining, locale.From(ctx))
+
+	if category := r.categorize(remaining); category != defaultCategory {
+		q.Category = category
+	} else if m := subjectPattern.FindStringSubmatch(remaining); m != nil {
+		subject := strings.Join(strings.Fields(noisePattern.ReplaceAllString(m[1], " ")), " ")
+		q.Text = subject
+	}
+
+	q, err := q.Validate()
+	if err != nil {
+		return query.Query{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return q, nil
+}
+
+func parseAggregation(text string) query.Aggregation {
+	lower := locale.StripAccents(strings.ToLower(strings.TrimLeft(text, "¿ ")))
+	switch {
+	case strings.Contains(lower, "how many") || strings.Contains(lower, "how often") ||
+		strings.HasPrefix(lower, "cuantos") || strings.HasPrefix(lower, "cuantas"):
+		return query.Count
+	case strings.Contains(lower, "average") || containsWord(lower, "promedio"):
+		return query.Average
+	case containsWord(lower, "biggest") || containsWord(lower, "largest") || containsWord(lower, "most expensive") ||
+		containsWord(lower, "mas caro") || containsWord(lower, "mas grande") || containsWord(lower, "mayor"):
+		return query.Max
+	case containsWord(lower, "smallest") || containsWord(lower, "cheapest") ||
+		containsWord(lower, "mas barato") || containsWord(lower, "mas pequeno") || containsWord(lower, "menor"):
+		return query.Min
+	case strings.HasPrefix(lower, "show") || strings.HasPrefix(lower, "list") || strings.HasPrefix(lower, "which") ||
+		strings.HasPrefix(lower, "muestrame") || strings.HasPrefix(lower, "lista") || strings.HasPrefix(lower, "cuales"):
+		return query.List
+	default:
+		return query.Sum
+	}
+}
+
+// parsePeriod returns the range named in text, with an exclusive end, and text without it.
+func (r *Rules) parsePeriod(text string, settings locale.Settings) (time.Time, time.Time, string) {
+	today := settings.StartOfDay(r.now())
+
+	if m := lastDaysPattern.FindStringSubmatchIndex(text); m != nil {
+		days, _ := strconv.Atoi(text[m[2]:m[3]])
+		if days > 0 {
+			return today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1), cut(text, m[0], m[5])
+		}
+	}
+
+	if m := periodPattern.FindStringSubmatchIndex(text); m != nil {
+		phrase := strings.Join(strings.Fields(strings.ToLower(text[m[2]:m[3]])), " ")
+		phrase = strings.TrimPrefix(strings.TrimPrefix(phrase, "la "), "el ")
+		rest := cut(text, m[0], m[1])
+		thisWeek := settings.StartOfWeek(today)
+		thisMonth := settings.StartOfMonth(today)
+		thisYear := settings.StartOfYear(today)
+		switch phrase {
+		case "today", "hoy":
+			return today, today.AddDate(0, 0, 1), rest
+		case "yesterday", "ayer":
+			return today.AddDate(0, 0, -1), today, rest
+		case "this week", "esta semana":
+			return thisWeek, today.AddDate(0, 0, 1), rest
+		case "last week", "semana pasada":
+			return thisWeek.AddDate(0, 0, -7), thisWeek, rest
+		case "this month", "este mes":
+			return thisMonth, today.AddDate(0, 0, 1), rest
+		case "last month", "mes pasado":
+			return thisMonth.AddDate(0, -1, 0), thisMonth, rest
+		case "this year", "este año", "este ano":
+			return thisYear, today.AddDate(0, 0, 1), rest
+		case "last year", "año pasado", "ano pasado":
+			return thisYear.AddDate(-1, 0, 0), thisYear, rest
+		}
+	}
+
+	if m := monthPattern.FindStringSubmatchIndex(text); m != nil {
+		month, _ := locale.ParseMonth(text[m[2]:m[3]])
+		year := today.Year()
+		if m[4] >= 0 {
+			year, _ = strconv.Atoi(text[m[4]:m[5]])
+		} else if month > today.Month() {
+			// A month later than the current one means last year's.
+			year--
+		}
+		since := time.Date(year, month, 1, 0, 0, 0, 0, today.Location())
+		return since, since.AddDate(0, 1, 0), cut(text, m[0], m[1])
+	}
+
+	if m := yearPattern.FindStringSubmatchIndex(text); m != nil {
+		year, _ := strconv.Atoi(text[m[2]:m[3]])
+		since := time.Date(year, time.January, 1, 0, 0, 0, 0, today.Location())
+		return since, since.AddDate(1, 0, 0), cut(text, m[0], m[1])
+	}
+
+	return time.Time{}, time.Time{}, text
+}
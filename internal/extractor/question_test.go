@@ -0,0 +1,176 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/query"
+)
+
+func TestClassifyIntent(t *testing.T) {
+	tests := []struct {
+		text string
+		want Intent
+	}{
+		{text: "coffee 3.50", want: IntentExpense},
+		{text: "lunch with Sam 12", want: IntentExpense},
+		{text: "How much did I spend on coffee in September?", want: IntentQuestion},
+		{text: "what's my biggest expense this month", want: IntentQuestion},
+		{text: "Show, groceries last week", want: IntentQuestion},
+		{text: "have lunch 12", want: IntentExpense},
+		{text: "did groceries 45.20", want: IntentExpense},
+		{text: "did I spend 12 on lunch?", want: IntentQuestion},
+		{text: "list my coffee expenses", want: IntentQuestion},
+		{text: "coffee again?", want: IntentQuestion},
+		{text: "¿Cuánto gasté en café en septiembre?", want: IntentQuestion},
+		{text: "almuerzo con Ana 12", want: IntentExpense},
+	}
+	for _, tt := range tests {
+		if got := ClassifyIntent(tt.text); got != tt.want {
+			t.Errorf("ClassifyIntent(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func queryClient(content string) *stubClient {
+	return &stubClient{
+		response: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}},
+		},
+	}
+}
+
+func TestOpenAIExtractQuery(t *testing.T) {
+	client := queryClient(`{"category":"Coffee","text":"","since":"2026-09-01","until":"2026-09-30","aggregation":"SUM"}`)
+	extractor := &OpenAI{client: client, model: "test-model"}
+
+	q, err := extractor.ExtractQuery(context.Background(), "How much did I spend on coffee in September?")
+	if err != nil {
+		t.Fatalf("ExtractQuery error: %v", err)
+	}
+	want := query.Query{
+		Category:    "Coffee",
+		Since:       time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		Until:       time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Aggregation: query.Sum,
+	}
+	if q != want {
+		t.Fatalf("expected %+v, got %+v", want, q)
+	}
+	if got := client.request.Messages[1].Content; !strings.HasPrefix(got, "<question>") {
+		t.Fatalf("expected delimited question, got %q", got)
+	}
+}
+
+func TestOpenAIExtractQueryRejectsInvalidFields(t *testing.T) {
+	tests := []string{
+		`{"aggregation":"DROP TABLE expenses"}`,
+		`{"since":"September"}`,
+		`{"since":"2026-09-30","until":"2026-09-01"}`,
+		`{"category":"` + strings.Repeat("x", 41) + `"}`,
+		`{"category":`,
+	}
+	for _, content := range tests {
+		extractor := &OpenAI{client: queryClient(content), model: "test-model"}
+		if _, err := extractor.ExtractQuery(context.Background(), "how much?"); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("%s: expected ErrInvalidResponse, got %v", content, err)
+		}
+	}
+}
+
+func TestRulesExtractQuery(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		text string
+		want query.Query
+	}{
+		{
+			text: "How much did I spend on coffee in September?",
+			want: query.Query{Category: "Coffee", Since: day(9, 1), Until: day(10, 1), Aggregation: query.Sum},
+		},
+		{
+			text: "how many taxi rides last week",
+			want: query.Query{Category: "Transport", Since: day(10, 5), Until: day(10, 12), Aggregation: query.Count},
+		},
+		{
+			text: "what was my biggest expense this month",
+			want: query.Query{Since: day(10, 1), Until: day(10, 18), Aggregation: query.Max},
+		},
+		{
+			text: "how much at ikea in the last 30 days",
+			want: query.Query{Text: "ikea", Since: day(9, 18), Until: day(10, 18), Aggregation: query.Sum},
+		},
+		{
+			text: "how much for #Vacation-2026 this year?",
+			want: query.Query{Tag: "vacation-2026", Since: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Until: day(10, 18), Aggregation: query.Sum},
+		},
+		{
+			text: "show groceries in november",
+			want: query.Query{Category: "Groceries", Since: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Aggregation: query.List},
+		},
+		{
+			text: "¿Cuánto gasté en café en septiembre?",
+			want: query.Query{Category: "Coffee", Since: day(9, 1), Until: day(10, 1), Aggregation: query.Sum},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			q, err := newTestRules().ExtractQuery(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("ExtractQuery error: %v", err)
+			}
+			if q != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, q)
+			}
+		})
+	}
+}
+
+func TestRulesExtractQueryFollowsSettings(t *testing.T) {
+	bogota, err := locale.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatalf("LoadLocation error: %v", err)
+	}
+	settings := locale.Settings{Location: bogota, Currency: "$", WeekStart: time.Sunday}
+	ctx := locale.WithSettings(context.Background(), settings)
+
+	r := NewRules()
+	// Sunday in UTC, but still Saturday evening in Bogotá.
+	r.now = func() time.Time { return time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC) }
+
+	q, err := r.ExtractQuery(ctx, "how many taxis this week")
+	if err != nil {
+		t.Fatalf("ExtractQuery error: %v", err)
+	}
+	since, until := time.Date(2026, 10, 11, 0, 0, 0, 0, bogota), time.Date(2026, 10, 18, 0, 0, 0, 0, bogota)
+	if !q.Since.Equal(since) || !q.Until.Equal(until) {
+		t.Fatalf("expected %v to %v, got %v to %v", since, until, q.Since, q.Until)
+	}
+
+	item, err := r.Extract(ctx, "lunch 12 yesterday")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if got := item.Date.Format("2006-01-02"); got != "2026-10-16" {
+		t.Fatalf("expected yesterday in Bogotá, got %s", got)
+	}
+}
+
+func TestQueryFallbackOnPrimaryError(t *testing.T) {
+	primary := &OpenAI{client: &stubClient{err: errors.New("unavailable")}, model: "test-model"}
+	fallback := NewQueryFallback(primary, newTestRules())
+
+	q, err := fallback.ExtractQuery(context.Background(), "how much on coffee yesterday?")
+	if err != nil {
+		t.Fatalf("ExtractQuery error: %v", err)
+	}
+	if q.Category != "Coffee" || !q.Since.Equal(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected fallback query %+v", q)
+	}
+}
@@ -0,0 +1,302 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/query"
+)
+
+// ErrCircuitOpen is returned without calling the provider while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("extractor circuit breaker is open")
+
+// ResilienceConfig tunes the retry and circuit breaker behaviour of Resilient.
+type ResilienceConfig struct {
+	// AttemptTimeout bounds each individual call; zero disables the per-call deadline.
+	AttemptTimeout time.Duration
+	// MaxRetries is the number of additional attempts after the first failure.
+	MaxRetries int
+	// BaseBackoff is the delay before the first retry; it doubles on every attempt.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including server-provided Retry-After hints.
+	MaxBackoff time.Duration
+	// FailureThreshold is the number of consecutive failed calls that opens the circuit.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before a trial call is allowed.
+	OpenDuration time.Duration
+}
+
+// DefaultResilienceConfig returns conservative defaults suitable for a chat bot.
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		AttemptTimeout:   15 * time.Second,
+		MaxRetries:       2,
+		BaseBackoff:      500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		FailureThreshold: 5,
+		OpenDuration:     time.Minute,
+	}
+}
+
+// Resilient decorates a Service with per-call timeouts, retries with exponential backoff
+// on transient failures and a circuit breaker that fails fast while the provider is unhealthy.
+type Resilient struct {
+	next    Service
+	cfg     ResilienceConfig
+	breaker *circuitBreaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+var (
+	_ Service        = (*Resilient)(nil)
+	_ QueryExtractor = (*Resilient)(nil)
+)
+
+// errQueriesUnsupported is returned by decorators asked for a query when the Service they
+// wrap cannot extract one.
+var errQueriesUnsupported = errors.New("wrapped extractor does not answer questions")
+
+// NewResilient wraps next with the provided resilience settings.
+func NewResilient(next Service, cfg ResilienceConfig) *Resilient {
+	return &Resilient{
+		next:    next,
+		cfg:     cfg,
+		breaker: newCircuitBreaker(cfg.FailureThreshold, cfg.OpenDuration, time.Now),
+		sleep:   sleepContext,
+	}
+}
+
+// CircuitOpen reports whether calls are currently being rejected by the circuit breaker.
+func (r *Resilient) CircuitOpen() bool {
+	return r.breaker.isOpen()
+}
+
+// Extract calls the wrapped Service, retrying transient failures.
+func (r *Resilient) Extract(ctx context.Context, text string) (expense.Item, error) {
+	return retry(ctx, r, func(ctx context.Context) (expense.Item, error) {
+		return r.next.Extract(ctx, text)
+	})
+}
+
+// ExtractQuery implements QueryExtractor with the same retries and circuit breaker as
+// Extract, so questions and expenses share one view of the provider's health.
+func (r *Resilient) ExtractQuery(ctx context.Context, text string) (query.Query, error) {
+	next, ok := r.next.(QueryExtractor)
+	if !ok {
+		return query.Query{}, fmt.Errorf("%w: %T", errQueriesUnsupported, r.next)
+	}
+	return retry(ctx, r, func(ctx context.Context) (query.Query, error) {
+		return next.ExtractQuery(ctx, text)
+	})
+}
+
+// retry runs call through r's circuit breaker, retrying transient failures.
+func retry[T any](ctx context.Context, r *Resilient, call func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if !r.breaker.allow() {
+		return zero, ErrCircuitOpen
+	}
+
+	var lastErr error
+	for try := 0; try <= r.cfg.MaxRetries; try++ {
+		result, err := attempt(ctx, r.cfg.AttemptTimeout, call)
+		if err == nil {
+			r.breaker.success()
+			return result, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !isTransient(err) {
+			break
+		}
+		if try == r.cfg.MaxRetries {
+			break
+		}
+
+		delay := r.backoff(try)
+		if hint, ok := retryAfter(err); ok {
+			if hint > r.cfg.MaxBackoff {
+				// The provider asked us to stay away longer than we are willing to wait.
+				break
+			}
+			delay = hint
+		}
+		if err := r.sleep(ctx, delay); err != nil {
+			break
+		}
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		// The caller gave up, which says nothing about the provider.
+		r.breaker.abandon()
+	case isTransient(lastErr):
+		r.breaker.failure()
+	default:
+		// Bad input or an unparsable answer still proves the provider is reachable.
+		r.breaker.success()
+	}
+	return zero, lastErr
+}
+
+// attempt makes a single call, bounded by timeout when it is positive.
+func attempt[T any](ctx context.Context, timeout time.Duration, call func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return call(attemptCtx)
+}
+
+func (r *Resilient) backoff(attempt int) time.Duration {
+	delay := r.cfg.BaseBackoff << attempt
+	if delay <= 0 || (r.cfg.MaxBackoff > 0 && delay > r.cfg.MaxBackoff) {
+		return r.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// isTransient reports whether err is worth retrying: rate limits, server errors and timeouts.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	status := httpStatus(err)
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// httpStatus extracts the HTTP status code from OpenAI client errors, or 0 when unknown.
+func httpStatus(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
+
+// RetryAfterError carries the server's Retry-After hint alongside the original error.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string { return e.Err.Error() }
+
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+func retryAfter(err error) (time.Duration, bool) {
+	var hinted *RetryAfterError
+	if errors.As(err, &hinted) && hinted.After > 0 {
+		return hinted.After, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter understands both delta-seconds and HTTP-date Retry-After values.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: now}
+}
+
+// allow reports whether a call may proceed. Once the cooldown elapses a single trial
+// call is let through (half-open); its outcome closes or re-opens the circuit.
+func (c *circuitBreaker) allow() bool {
+	if c.threshold <= 0 {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failures < c.threshold {
+		return true
+	}
+	if c.now().Before(c.openUntil) || c.trial {
+		return false
+	}
+	c.trial = true
+	return true
+}
+
+func (c *circuitBreaker) success() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = 0
+	c.trial = false
+}
+
+func (c *circuitBreaker) failure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+	c.trial = false
+	if c.threshold > 0 && c.failures >= c.threshold {
+		c.openUntil = c.now().Add(c.cooldown)
+	}
+}
+
+// abandon ends a call without a verdict, freeing the half-open trial slot.
+func (c *circuitBreaker) abandon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trial = false
+}
+
+func (c *circuitBreaker) isOpen() bool {
+	if c.threshold <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failures >= c.threshold && c.now().Before(c.openUntil)
+}
@@ -0,0 +1,215 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+type scriptedService struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedService) Extract(context.Context, string) (expense.Item, error) {
+	s.calls++
+	if len(s.errs) >= s.calls && s.errs[s.calls-1] != nil {
+		return expense.Item{}, s.errs[s.calls-1]
+	}
+	return expense.Item{Category: "Food", Amount: 1, Description: "Snack"}, nil
+}
+
+func newTestResilient(next Service, cfg ResilienceConfig) (*Resilient, *[]time.Duration) {
+	r := NewResilient(next, cfg)
+	var slept []time.Duration
+	r.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	return r, &slept
+}
+
+func statusErr(code int) error {
+	return &openai.APIError{HTTPStatusCode: code, Message: http.StatusText(code)}
+}
+
+func TestResilientRetriesTransientErrors(t *testing.T) {
+	next := &scriptedService{errs: []error{statusErr(500), statusErr(429)}}
+	r, slept := newTestResilient(next, ResilienceConfig{MaxRetries: 2, BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	if _, err := r.Extract(context.Background(), "snack 1"); err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", next.calls)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(*slept) != 2 || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Fatalf("unexpected backoff delays %v", *slept)
+	}
+}
+
+func TestResilientDoesNotRetryPermanentErrors(t *testing.T) {
+	next := &scriptedService{errs: []error{statusErr(400)}}
+	r, _ := newTestResilient(next, ResilienceConfig{MaxRetries: 3, BaseBackoff: time.Second})
+
+	if _, err := r.Extract(context.Background(), "x"); err == nil {
+		t.Fatal("expected error")
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected a single call, got %d", next.calls)
+	}
+}
+
+func TestResilientHonorsRetryAfter(t *testing.T) {
+	next := &scriptedService{errs: []error{&RetryAfterError{Err: statusErr(429), After: 3 * time.Second}}}
+	r, slept := newTestResilient(next, ResilienceConfig{MaxRetries: 1, BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	if _, err := r.Extract(context.Background(), "x"); err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] != 3*time.Second {
+		t.Fatalf("expected Retry-After delay, got %v", *slept)
+	}
+
+	next = &scriptedService{errs: []error{&RetryAfterError{Err: statusErr(429), After: time.Minute}}}
+	r, _ = newTestResilient(next, ResilienceConfig{MaxRetries: 1, BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	if _, err := r.Extract(context.Background(), "x"); err == nil {
+		t.Fatal("expected error when Retry-After exceeds the backoff cap")
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected no retry beyond the cap, got %d calls", next.calls)
+	}
+}
+
+func TestResilientAttemptTimeout(t *testing.T) {
+	slow := funcService(func(ctx context.Context, _ string) (expense.Item, error) {
+		<-ctx.Done()
+		return expense.Item{}, ctx.Err()
+	})
+	r, _ := newTestResilient(slow, ResilienceConfig{AttemptTimeout: 5 * time.Millisecond, MaxRetries: 1})
+
+	_, err := r.Extract(context.Background(), "x")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestResilientCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := &scriptedService{errs: []error{statusErr(503), statusErr(503), statusErr(503)}}
+	r, _ := newTestResilient(next, ResilienceConfig{FailureThreshold: 2, OpenDuration: time.Minute})
+	r.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Extract(context.Background(), "x"); err == nil {
+			t.Fatal("expected failure")
+		}
+	}
+	if !r.CircuitOpen() {
+		t.Fatal("expected circuit to be open")
+	}
+	if _, err := r.Extract(context.Background(), "x"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected open circuit to skip the provider, got %d calls", next.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := r.Extract(context.Background(), "x"); err == nil {
+		t.Fatal("expected half-open trial to fail")
+	}
+	if !r.CircuitOpen() {
+		t.Fatal("expected failed trial to re-open the circuit")
+	}
+
+	now = now.Add(2 * time.Minute)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.next = funcService(func(ctx context.Context, _ string) (expense.Item, error) { return expense.Item{}, ctx.Err() })
+	if _, err := r.Extract(canceled, "x"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled trial to fail, got %v", err)
+	}
+	if r.breaker.failures != 3 || r.breaker.trial {
+		t.Fatalf("expected a canceled trial to leave the breaker as it was, got %d failures", r.breaker.failures)
+	}
+	r.next = next
+
+	if _, err := r.Extract(context.Background(), "x"); err != nil {
+		t.Fatalf("expected successful trial, got %v", err)
+	}
+	if r.CircuitOpen() {
+		t.Fatal("expected circuit to close after success")
+	}
+}
+
+func TestHTTPClientCapturesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit"}}`))
+	}))
+	defer server.Close()
+
+	cfg := openai.DefaultConfig("test-key")
+	cfg.BaseURL = server.URL
+	cfg.HTTPClient = NewHTTPClient(server.Client())
+	extractor := &OpenAI{client: openai.NewClientWithConfig(cfg), model: "test-model"}
+
+	_, err := extractor.Extract(context.Background(), "coffee")
+	after, ok := retryAfter(err)
+	if !ok || after != 7*time.Second {
+		t.Fatalf("expected 7s Retry-After, got %v (err %v)", after, err)
+	}
+	if httpStatus(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 status, got %d", httpStatus(err))
+	}
+}
+
+func TestUserMessageClassification(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: ErrCircuitOpen, want: "temporarily unavailable"},
+		{err: statusErr(429), want: "busy"},
+		{err: statusErr(502), want: "having trouble"},
+		{err: statusErr(401), want: "misconfigured"},
+		{err: context.DeadlineExceeded, want: "too long"},
+		{err: ErrNoAmount, want: "amount"},
+		{err: ErrInvalidResponse, want: "rephrasing"},
+		{err: errors.New("boom"), want: "Something went wrong"},
+	}
+
+	for _, tt := range tests {
+		got := UserMessage(tt.err, locale.Default())
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("UserMessage(%v) = %q, want it to mention %q", tt.err, got, tt.want)
+		}
+		if strings.Contains(got, tt.err.Error()) {
+			t.Errorf("UserMessage(%v) leaked the raw error: %q", tt.err, got)
+		}
+	}
+}
+
+func TestResilientQueriesShareCircuitBreaker(t *testing.T) {
+	r, _ := newTestResilient(&scriptedService{errs: []error{statusErr(503)}}, ResilienceConfig{FailureThreshold: 1, OpenDuration: time.Minute})
+	if _, err := r.Extract(context.Background(), "snack 1"); err == nil {
+		t.Fatal("expected the failing call to return an error")
+	}
+
+	r.next = NewRules()
+	if _, err := r.ExtractQuery(context.Background(), "how much on coffee?"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected questions to fail fast while the circuit is open, got %v", err)
+	}
+}
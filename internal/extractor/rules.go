@@ -0,0 +1,324 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+// ErrNoAmount is returned by the rule-based extractor when the text has no recognizable amount.
+var ErrNoAmount = errors.New("no amount found in expense description")
+
+const defaultCategory = "General"
+
+// Patterns accept English and Spanish; Spanish words are matched with or without accents.
+// Go's \b only knows ASCII letters, so words that may end in an accented letter close
+// with (?:$|[^\p{L}]) instead.
+var (
+	// amountPattern matches amounts such as "$3.50", "3,50€", "12k", "1.234,56 EUR" or a bare "42".
+	amountPattern    = regexp.MustCompile(`(?i)(?:^|[\s(])([$€£])?\s?(\d+(?:[.,]\d+)*)(k)?\s?([$€£]|usd|eur|gbp|dollars?|euros?|pesos?|bucks)?(?:$|[\s).,!?;:])`)
+	isoDatePattern   = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	slashDatePattern = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?\b`)
+	relativePattern  = regexp.MustCompile(`(?i)\b(today|yesterday|tonight|this morning|hoy|ayer|anoche|anteayer|antier|esta noche|esta ma[nñ]ana)(?:$|[^\p{L}])`)
+	weekdayPattern   = regexp.MustCompile(`(?i)\b(?:(last|on|el)\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday|lunes|martes|mi[eé]rcoles|jueves|viernes|s[aá]bado|domingo)(\s+pasado)?(?:$|[^\p{L}])`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+	fillerPattern    = regexp.MustCompile(`(?i)^(?:i\s+)?(?:spent|paid|bought|got)\s+|^(?:gast[eé]|pagu[eé]|compr[eé])\s+|^(?:for|on|at|en|de|por|para)\s+|\s+(?:for|on|at|en|de|por|para)$`)
+
+	// accountPattern matches payment hints such as "with visa", "paid by debit card", "cash",
+	// "con tarjeta de crédito" or "en efectivo".
+	accountPattern = regexp.MustCompile(`(?i)\b(?:(?:paid\s+)?(?:with|by|using|via|on)\s+(?:my\s+|the\s+)?(visa|mastercard|amex|paypal|cash|(?:debit|credit)(?:\s+card)?)|(?:in\s+)?(cash)|(?:pagad[oa]\s+)?(?:con|por)\s+(?:mi\s+|la\s+)?(visa|mastercard|amex|paypal|efectivo|(?:tarjeta\s+(?:de\s+)?)?(?:d[eé]bito|cr[eé]dito))|(?:en\s+)?(efectivo))(?:$|[^\p{L}])`)
+)
+
+type categoryRule struct {
+	category string
+	keywords []string
+	// kind is the direction of matching entries; zero means an expense.
+	kind expense.Kind
+}
+
+// defaultCategoryRules maps common English and Spanish keywords to categories, which are
+// always named in English; earlier rules win, so income comes first and "refund for shoes"
+// is not taken for shopping. Spanish keywords are written without accents.
+var defaultCategoryRules = []categoryRule{
+	{category: "Salary", kind: expense.KindIncome, keywords: []string{"salary", "paycheck", "payroll", "wages", "salario", "sueldo", "nomina", "quincena"}},
+	{category: "Refund", kind: expense.KindIncome, keywords: []string{"refund", "refunded", "reimbursement", "reimbursed", "cashback", "reembolso", "devolucion"}},
+	{category: "Income", kind: expense.KindIncome, keywords: []string{"income", "bonus", "dividend", "dividends", "got paid", "received", "ingreso", "ingresos", "bono", "dividendos", "me pagaron", "recibi"}},
+	{category: "Groceries", keywords: []string{"grocery", "groceries", "supermarket", "market", "costco", "walmart", "supermercado", "mercado", "viveres"}},
+	{category: "Coffee", keywords: []string{"coffee", "latte", "espresso", "cappuccino", "starbucks", "cafe", "cafecito", "tinto"}},
+	{category: "Food", keywords: []string{"lunch", "dinner", "breakfast", "restaurant", "pizza", "burger", "sushi", "snack", "food", "takeout", "burrito", "almuerzo", "cena", "desayuno", "restaurante", "hamburguesa", "comida", "empanada", "empanadas"}},
+	{category: "Transport", keywords: []string{"taxi", "uber", "lyft", "bus", "metro", "subway", "train", "fuel", "gas", "parking", "toll", "gasolina", "pasaje", "parqueadero", "estacionamiento", "peaje", "tren"}},
+	{category: "Travel", keywords: []string{"flight", "hotel", "airbnb", "trip", "airline", "vuelo", "viaje", "aerolinea"}},
+	{category: "Utilities", keywords: []string{"electricity", "water bill", "internet", "phone bill", "utility", "utilities", "electricidad", "recibo de luz", "recibo del agua", "factura del celular", "servicios"}},
+	{category: "Housing", keywords: []string{"rent", "mortgage", "arriendo", "alquiler", "hipoteca"}},
+	{category: "Health", keywords: []string{"pharmacy", "doctor", "medicine", "dentist", "gym", "hospital", "farmacia", "medico", "medicina", "medicamentos", "dentista", "gimnasio"}},
+	{category: "Entertainment", keywords: []string{"movie", "cinema", "concert", "netflix", "spotify", "game", "tickets", "pelicula", "cine", "concierto", "entradas", "juego"}},
+	{category: "Shopping", keywords: []string{"clothes", "shoes", "amazon", "shirt", "gift", "ropa", "zapatos", "camisa", "regalo"}},
+}
+
+// Rules implements Service with deterministic regular expressions and keyword matching.
+// It is intended as an offline fallback when the LLM is unavailable.
+type Rules struct {
+	now        func() time.Time
+	categories []categoryRule
+}
+
+var _ Service = (*Rules)(nil)
+
+// NewRules returns a rule-based extractor using the built-in category keywords.
+func NewRules() *Rules {
+	return &Rules{
+		now:        time.Now,
+		categories: defaultCategoryRules,
+	}
+}
+
+// Extract parses an amount, an optional date and a keyword-based category from text. Dates
+// are read in the time zone of the locale.Settings in ctx.
+func (r *Rules) Extract(ctx context.Context, text string) (expense.Item, error) {
+	if err := checkInput(text); err != nil {
+		return expense.Item{}, err
+	}
+	remaining := strings.TrimSpace(text)
+
+	date, remaining := r.parseDate(remaining, locale.From(ctx))
+	account, remaining := parseAccount(remaining)
+
+	amount, remaining, ok := parseAmount(remaining)
+	if !ok {
+		return expense.Item{}, ErrNoAmount
+	}
+
+	description := cleanDescription(remaining)
+	rule := r.classify(text)
+	if description == "" {
+		description = rule.category
+	}
+
+	item, err := validateItem(expense.Item{
+		Category:    rule.category,
+		Amount:      amount,
+		Description: description,
+		Date:        date,
+		Kind:        rule.kind,
+		Account:     account,
+	})
+	if err != nil {
+		return expense.Item{}, err
+	}
+	item.LowConfidence = true
+	return item, nil
+}
+
+// parseAccount returns the payment hint in text, if any, and text without it.
+func parseAccount(text string) (string, string) {
+	m := accountPattern.FindStringSubmatchIndex(text)
+	if m == nil {
+		return "", text
+	}
+	// Each alternative ends with its hint, so cutting up to the hint keeps the character
+	// that closed the word.
+	for i := 2; i < len(m); i += 2 {
+		if m[i] >= 0 {
+			return text[m[i]:m[i+1]], cut(text, m[0], m[i+1])
+		}
+	}
+	return "", text
+}
+
+func (r *Rules) categorize(text string) string {
+	return r.classify(text).category
+}
+
+// classify returns the first rule with a keyword in text, or a General expense.
+func (r *Rules) classify(text string) categoryRule {
+	lower := locale.StripAccents(strings.ToLower(text))
+	for _, rule := range r.categories {
+		for _, keyword := range rule.keywords {
+			if containsWord(lower, keyword) {
+				return rule
+			}
+		}
+	}
+	return categoryRule{category: defaultCategory}
+}
+
+func (r *Rules) parseDate(text string, settings locale.Settings) (time.Time, string) {
+	now := settings.In(r.now())
+
+	if m := isoDatePattern.FindStringSubmatchIndex(text); m != nil {
+		year, _ := strconv.Atoi(text[m[2]:m[3]])
+		month, _ := strconv.Atoi(text[m[4]:m[5]])
+		day, _ := strconv.Atoi(text[m[6]:m[7]])
+		if date, ok := buildDate(now, year, month, day); ok {
+			return date, cut(text, m[0], m[1])
+		}
+	}
+
+	if m := slashDatePattern.FindStringSubmatchIndex(text); m != nil {
+		day, _ := strconv.Atoi(text[m[2]:m[3]])
+		month, _ := strconv.Atoi(text[m[4]:m[5]])
+		year := now.Year()
+		if m[6] >= 0 {
+			year, _ = strconv.Atoi(text[m[6]:m[7]])
+			if year < 100 {
+				year += 2000
+			}
+		}
+		if date, ok := buildDate(now, year, month, day); ok {
+			return date, cut(text, m[0], m[1])
+		}
+	}
+
+	if m := relativePattern.FindStringSubmatchIndex(text); m != nil {
+		date := now
+		switch strings.ToLower(text[m[2]:m[3]]) {
+		case "yesterday", "ayer", "anoche":
+			date = now.AddDate(0, 0, -1)
+		case "anteayer", "antier":
+			date = now.AddDate(0, 0, -2)
+		}
+		return date, cut(text, m[0], m[3])
+	}
+
+	if m := weekdayPattern.FindStringSubmatchIndex(text); m != nil {
+		strict := m[2] >= 0 && strings.EqualFold(text[m[2]:m[3]], "last") || m[6] >= 0
+		target, _ := locale.ParseWeekday(text[m[4]:m[5]])
+		offset := (int(now.Weekday()) - int(target) + 7) % 7
+		if offset == 0 && strict {
+			offset = 7
+		}
+		end := m[5]
+		if m[6] >= 0 {
+			end = m[7]
+		}
+		return now.AddDate(0, 0, -offset), cut(text, m[0], end)
+	}
+
+	return time.Time{}, text
+}
+
+// parseAmount finds the most likely amount in text, preferring tokens with a currency marker.
+func parseAmount(text string) (float64, string, bool) {
+	matches := amountPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return 0, text, false
+	}
+
+	best := -1
+	for i, m := range matches {
+		if m[2] >= 0 || m[8] >= 0 {
+			best = i
+			break
+		}
+		if best < 0 {
+			best = i
+		}
+	}
+
+	m := matches[best]
+	value, err := normalizeNumber(text[m[4]:m[5]])
+	if err != nil || value <= 0 {
+		return 0, text, false
+	}
+	if m[6] >= 0 {
+		value *= 1000
+	}
+
+	start := m[2]
+	if start < 0 {
+		start = m[4]
+	}
+	end := m[5]
+	for _, idx := range []int{m[7], m[9]} {
+		if idx > end {
+			end = idx
+		}
+	}
+	return value, cut(text, start, end), true
+}
+
+// normalizeNumber converts localized number strings ("3,50", "1.234,56", "1,234.56") to float64.
+func normalizeNumber(raw string) (float64, error) {
+	lastDot := strings.LastIndex(raw, ".")
+	lastComma := strings.LastIndex(raw, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			raw = strings.ReplaceAll(raw, ".", "")
+			raw = strings.Replace(raw, ",", ".", 1)
+		} else {
+			raw = strings.ReplaceAll(raw, ",", "")
+		}
+	case lastComma >= 0:
+		raw = normalizeSingleSeparator(raw, ",")
+	case lastDot >= 0:
+		raw = normalizeSingleSeparator(raw, ".")
+	}
+
+	return strconv.ParseFloat(raw, 64)
+}
+
+// normalizeSingleSeparator treats sep as a thousands separator when it repeats or groups
+// exactly three trailing digits, and as the decimal separator otherwise.
+func normalizeSingleSeparator(raw, sep string) string {
+	parts := strings.Split(raw, sep)
+	if len(parts) > 2 || len(parts[len(parts)-1]) == 3 {
+		return strings.Join(parts, "")
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func cleanDescription(text string) string {
+	text = spacePattern.ReplaceAllString(text, " ")
+	text = strings.Trim(text, " \t-–—,.:;!?()")
+	for {
+		cleaned := strings.TrimSpace(fillerPattern.ReplaceAllString(text, ""))
+		if cleaned == text {
+			break
+		}
+		text = cleaned
+	}
+	return text
+}
+
+func buildDate(now time.Time, year, month, day int) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func containsWord(text, word string) bool {
+	idx := 0
+	for {
+		i := strings.Index(text[idx:], word)
+		if i < 0 {
+			return false
+		}
+		start := idx + i
+		end := start + len(word)
+		if (start == 0 || !isLetter(text[start-1])) && (end == len(text) || !isLetter(text[end])) {
+			return true
+		}
+		idx = start + 1
+	}
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func cut(text string, start, end int) string {
+	return text[:start] + " " + text[end:]
+}
@@ -0,0 +1,158 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+func newTestRules() *Rules {
+	r := NewRules()
+	// Saturday, 2026-10-17 at noon.
+	r.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	return r
+}
+
+func TestRulesExtractAmounts(t *testing.T) {
+	tests := []struct {
+		text   string
+		amount float64
+	}{
+		{text: "Coffee $3.50", amount: 3.5},
+		{text: "coffee 3,50€", amount: 3.5},
+		{text: "new laptop 12k", amount: 12000},
+		{text: "rent 1.234,56 EUR", amount: 1234.56},
+		{text: "rent 1,234.56", amount: 1234.56},
+		{text: "groceries 1,200", amount: 1200},
+		{text: "taxi 42", amount: 42},
+		{text: "2 tickets for €15", amount: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			item, err := newTestRules().Extract(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Extract error: %v", err)
+			}
+			if item.Amount != tt.amount {
+				t.Fatalf("expected amount %.2f, got %.2f", tt.amount, item.Amount)
+			}
+			if !item.LowConfidence {
+				t.Fatal("expected rule-based item to be marked low confidence")
+			}
+		})
+	}
+}
+
+func TestRulesExtractDates(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "lunch 12 yesterday", want: "2026-10-16"},
+		{text: "lunch 12 on 2026-10-01", want: "2026-10-01"},
+		{text: "lunch 12 on 3/10", want: "2026-10-03"},
+		{text: "lunch 12 last friday", want: "2026-10-16"},
+		{text: "lunch 12 on saturday", want: "2026-10-17"},
+		{text: "lunch 12 last saturday", want: "2026-10-10"},
+		{text: "almuerzo 12 ayer", want: "2026-10-16"},
+		{text: "almuerzo 12 anteayer", want: "2026-10-15"},
+		{text: "almuerzo 12 el viernes pasado", want: "2026-10-16"},
+		{text: "almuerzo 12 el miércoles", want: "2026-10-14"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			item, err := newTestRules().Extract(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Extract error: %v", err)
+			}
+			if got := item.Date.Format("2006-01-02"); got != tt.want {
+				t.Fatalf("expected date %s, got %s", tt.want, got)
+			}
+			if item.Amount != 12 {
+				t.Fatalf("date parsing leaked into amount: %.2f", item.Amount)
+			}
+		})
+	}
+}
+
+func TestRulesExtractCategoryAndDescription(t *testing.T) {
+	item, err := newTestRules().Extract(context.Background(), "Spent $18 on sushi dinner")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if item.Category != "Food" {
+		t.Fatalf("expected Food category, got %q", item.Category)
+	}
+	if item.Description != "sushi dinner" {
+		t.Fatalf("unexpected description %q", item.Description)
+	}
+	if !item.Date.IsZero() {
+		t.Fatalf("expected no date, got %v", item.Date)
+	}
+
+	item, err = newTestRules().Extract(context.Background(), "mystery 5")
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if item.Category != defaultCategory {
+		t.Fatalf("expected default category, got %q", item.Category)
+	}
+}
+
+func TestRulesExtractIncome(t *testing.T) {
+	tests := []struct {
+		text     string
+		category string
+		kind     expense.Kind
+	}{
+		{text: "salary 3000", category: "Salary", kind: expense.KindIncome},
+		{text: "refund for shoes 20", category: "Refund", kind: expense.KindIncome},
+		{text: "shoes 20", category: "Shopping", kind: expense.KindExpense},
+		{text: "salario 3000", category: "Salary", kind: expense.KindIncome},
+		{text: "almuerzo 12", category: "Food", kind: expense.KindExpense},
+	}
+	for _, tt := range tests {
+		item, err := newTestRules().Extract(context.Background(), tt.text)
+		if err != nil {
+			t.Fatalf("%s: Extract error: %v", tt.text, err)
+		}
+		if item.Category != tt.category || item.Kind != tt.kind {
+			t.Fatalf("%s: expected %s %s, got %s %s", tt.text, tt.kind, tt.category, item.Kind, item.Category)
+		}
+	}
+}
+
+func TestRulesExtractAccount(t *testing.T) {
+	tests := []struct {
+		text        string
+		account     string
+		description string
+	}{
+		{text: "coffee 3.50 with Visa", account: "visa", description: "coffee"},
+		{text: "paid by debit card 40 groceries", account: "debit card", description: "groceries"},
+		{text: "taxi 12 cash", account: "cash", description: "taxi"},
+		{text: "cashback 5", account: "", description: "cashback"},
+		{text: "lunch with Sam 12", account: "", description: "lunch with Sam"},
+		{text: "taxi 12 con efectivo", account: "efectivo", description: "taxi"},
+	}
+	for _, tt := range tests {
+		item, err := newTestRules().Extract(context.Background(), tt.text)
+		if err != nil {
+			t.Fatalf("%s: Extract error: %v", tt.text, err)
+		}
+		if item.Account != tt.account || item.Description != tt.description {
+			t.Fatalf("%s: expected account %q and description %q, got %q and %q", tt.text, tt.account, tt.description, item.Account, item.Description)
+		}
+	}
+}
+
+func TestRulesExtractNoAmount(t *testing.T) {
+	if _, err := newTestRules().Extract(context.Background(), "coffee with friends"); !errors.Is(err, ErrNoAmount) {
+		t.Fatalf("expected ErrNoAmount, got %v", err)
+	}
+}
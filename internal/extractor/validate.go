@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+const (
+	// MaxInputLength is the longest message, in characters, accepted for extraction.
+	MaxInputLength = 500
+
+	maxAccountLength = 40
+)
+
+// ErrInputTooLong is returned before calling any extractor when the text exceeds MaxInputLength.
+var ErrInputTooLong = errors.New("expense text too long")
+
+// delimiterPattern matches the tags that fence user text in the prompt, so a message cannot
+// close the fence early and append instructions of its own.
+var delimiterPattern = regexp.MustCompile(`(?i)<\s*/?\s*(?:expense|question)\s*>`)
+
+func checkInput(text string) error {
+	if n := utf8.RuneCountInString(text); n > MaxInputLength {
+		return fmt.Errorf("%w: %d characters, limit is %d", ErrInputTooLong, n, MaxInputLength)
+	}
+	return nil
+}
+
+// validateItem rejects implausible extractions and normalizes the text fields. Whatever a
+// message manages to make the model say, only a sane expense gets past this point.
+func validateItem(item expense.Item) (expense.Item, error) {
+	if math.IsNaN(item.Amount) || item.Amount <= 0 || item.Amount > expense.MaxAmount {
+		return expense.Item{}, fmt.Errorf("%w: implausible amount", ErrInvalidResponse)
+	}
+
+	kind, ok := expense.ParseKind(string(item.Kind))
+	if !ok {
+		return expense.Item{}, fmt.Errorf("%w: unknown type", ErrInvalidResponse)
+	}
+	item.Kind = kind
+
+	item.Category = strings.Join(strings.Fields(item.Category), " ")
+	if item.Category == "" {
+		return expense.Item{}, fmt.Errorf("%w: missing category", ErrInvalidResponse)
+	}
+	if utf8.RuneCountInString(item.Category) > expense.MaxCategoryLength {
+		return expense.Item{}, fmt.Errorf("%w: category longer than %d characters", ErrInvalidResponse, expense.MaxCategoryLength)
+	}
+
+	item.Description = truncate(strings.Join(strings.Fields(item.Description), " "), expense.MaxDescriptionLength)
+	// Stores require a description; like the rules, fall back to the category.
+	if item.Description == "" {
+		item.Description = item.Category
+	}
+
+	// The account is only a hint for the store, so an implausible one is dropped rather
+	// than failing the whole extraction.
+	item.Account = strings.ToLower(strings.Join(strings.Fields(item.Account), " "))
+	if utf8.RuneCountInString(item.Account) > maxAccountLength {
+		item.Account = ""
+	}
+	return item, nil
+}
+
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
@@ -0,0 +1,115 @@
+// Package health serves the liveness and readiness probes an orchestrator uses to
+// decide whether to restart the bot or send it traffic.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/httpserve"
+)
+
+const checkTimeout = 3 * time.Second
+
+// Check is one named condition a probe verifies.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Handler answers GET /healthz with the liveness checks and GET /readyz with the
+// readiness checks, with 200 when all pass and 503 otherwise. The body only names the
+// failing checks; their errors, which may mention internal details, go to the log.
+type Handler struct {
+	live  []Check
+	ready []Check
+	mux   *http.ServeMux
+}
+
+// NewHandler wires the probe routes.
+func NewHandler(live, ready []Check) *Handler {
+	h := &Handler{live: live, ready: ready, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) { h.probe(w, r, h.live) })
+	h.mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) { h.probe(w, r, h.ready) })
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (h *Handler) probe(w http.ResponseWriter, r *http.Request, checks []Check) {
+	result := report{Status: "ok", Checks: make(map[string]string, len(checks))}
+	status := http.StatusOK
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		err := c.Run(ctx)
+		cancel()
+		if err != nil {
+			slog.WarnContext(r.Context(), "health check failed", "path", r.URL.Path, "check", c.Name, "err", err)
+			result.Checks[c.Name] = "failing"
+			result.Status = "failing"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		result.Checks[c.Name] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.ErrorContext(r.Context(), "encode health report", "err", err)
+	}
+}
+
+// Heartbeat fails when last reports a time older than maxAge, or the zero time.
+func Heartbeat(name string, last func() time.Time, maxAge time.Duration) Check {
+	return Check{Name: name, Run: func(context.Context) error {
+		t := last()
+		if t.IsZero() {
+			return errors.New("not started")
+		}
+		if age := time.Since(t); age > maxAge {
+			return fmt.Errorf("last active %s ago", age.Round(time.Second))
+		}
+		return nil
+	}}
+}
+
+// Cached runs c at most once per ttl and reuses its result in between, for checks that
+// call rate-limited services.
+func Cached(c Check, ttl time.Duration) Check {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		last    error
+	)
+	return Check{Name: c.Name, Run: func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checked.IsZero() && time.Since(checked) < ttl {
+			return last
+		}
+		last = c.Run(ctx)
+		checked = time.Now()
+		return last
+	}}
+}
+
+// Serve serves h on addr until ctx is canceled, then shuts down gracefully.
+func Serve(ctx context.Context, addr string, h http.Handler) error {
+	return httpserve.Serve(ctx, "health", addr, h)
+}
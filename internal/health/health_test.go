@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func probe(t *testing.T, h http.Handler, path string) (int, report, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body := rec.Body.String()
+	var r report
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		t.Fatalf("decode %s: %v (%q)", path, err, body)
+	}
+	return rec.Code, r, body
+}
+
+func TestProbes(t *testing.T) {
+	ok := Check{Name: "loop", Run: func(context.Context) error { return nil }}
+	failing := Check{Name: "telegram", Run: func(context.Context) error {
+		return errors.New(`Post "https://api.telegram.org/botSECRET/getMe": timeout`)
+	}}
+	h := NewHandler([]Check{ok}, []Check{ok, failing})
+
+	code, r, _ := probe(t, h, "/healthz")
+	if code != http.StatusOK || r.Status != "ok" || r.Checks["loop"] != "ok" {
+		t.Fatalf("expected a healthy liveness probe, got %d %+v", code, r)
+	}
+
+	code, r, body := probe(t, h, "/readyz")
+	if code != http.StatusServiceUnavailable || r.Status != "failing" || r.Checks["telegram"] != "failing" || r.Checks["loop"] != "ok" {
+		t.Fatalf("expected a failing readiness probe, got %d %+v", code, r)
+	}
+	if strings.Contains(body, "SECRET") {
+		t.Fatalf("expected check errors to stay out of the response, got %s", body)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var last time.Time
+	check := Heartbeat("loop", func() time.Time { return last }, time.Minute)
+
+	if err := check.Run(context.Background()); err == nil {
+		t.Fatal("expected a loop that never started to fail")
+	}
+	last = time.Now().Add(-30 * time.Second)
+	if err := check.Run(context.Background()); err != nil {
+		t.Fatalf("expected a recent heartbeat to pass, got %v", err)
+	}
+	last = time.Now().Add(-2 * time.Minute)
+	if err := check.Run(context.Background()); err == nil {
+		t.Fatal("expected a stale heartbeat to fail")
+	}
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	check := Cached(Check{Name: "telegram", Run: func(context.Context) error {
+		calls++
+		return nil
+	}}, time.Hour)
+	for range 3 {
+		if err := check.Run(context.Background()); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected one call within the TTL, got %d", calls)
+	}
+}
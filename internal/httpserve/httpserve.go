@@ -0,0 +1,48 @@
+// Package httpserve runs the bot's HTTP listeners: the dashboard, the metrics endpoint
+// and the health probes share one lifecycle.
+package httpserve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Serve serves h on addr until ctx is canceled, then shuts down gracefully. Name
+// identifies the listener in the log and prefixes the returned errors.
+func Serve(ctx context.Context, name, addr string, h http.Handler) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	slog.Info("listening", "server", name, "addr", addr)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("%s: serve: %w", name, err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("%s: shutdown: %w", name, err)
+		}
+		if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("%s: serve: %w", name, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package httpserve
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- Serve(ctx, "test", "127.0.0.1:0", http.NotFoundHandler())
+	}()
+	cancel()
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Fatalf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+}
+
+func TestServeReportsListenErrors(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	err = Serve(context.Background(), "test", taken.Addr().String(), http.NotFoundHandler())
+	if err == nil || !strings.HasPrefix(err.Error(), "test: serve:") {
+		t.Fatalf("expected a prefixed listen error, got %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CSVMapping describes which columns of a bank's CSV export hold which fields. Column
+// names are matched case-insensitively against the header row.
+type CSVMapping struct {
+	Date        string
+	Amount      string
+	Description string
+	Category    string
+	// Debit and Credit are used instead of Amount by banks that split money out and in
+	// into two unsigned columns.
+	Debit  string
+	Credit string
+	// DateFormat is a Go time layout; empty tries a few common layouts.
+	DateFormat string
+	// Delimiter defaults to a comma.
+	Delimiter rune
+	// DecimalComma reads "1.234,56" style amounts.
+	DecimalComma bool
+	// Invert treats positive amounts as money going out, for banks that export spending
+	// as positive numbers.
+	Invert bool
+}
+
+// DefaultCSVMapping recognizes the column names most banks use.
+func DefaultCSVMapping() CSVMapping {
+	return CSVMapping{
+		Date:        "date|posted date|transaction date|booking date",
+		Amount:      "amount|transaction amount",
+		Description: "description|payee|name|memo|details",
+		Category:    "category",
+		Debit:       "debit|withdrawal|money out",
+		Credit:      "credit|deposit|money in",
+	}
+}
+
+// ParseCSVMapping reads overrides formatted as "date=Posted,amount=Amount,...", on top of
+// DefaultCSVMapping. Keys are date, amount, description, category, debit, credit,
+// date_format, delimiter, decimal_comma and invert. Column names may list alternatives
+// separated by "|".
+func ParseCSVMapping(raw string) (CSVMapping, error) {
+	mapping := DefaultCSVMapping()
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return CSVMapping{}, fmt.Errorf("invalid mapping entry %q: expected key=value", entry)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "date":
+			mapping.Date = value
+		case "amount":
+			mapping.Amount = value
+		case "description":
+			mapping.Description = value
+		case "category":
+			mapping.Category = value
+		case "debit":
+			mapping.Debit = value
+		case "credit":
+			mapping.Credit = value
+		case "date_format":
+			mapping.DateFormat = value
+		case "delimiter":
+			r, size := utf8.DecodeRuneInString(value)
+			if value == "tab" {
+				r, size = '\t', len(value)
+			}
+			if size != len(value) || r == utf8.RuneError {
+				return CSVMapping{}, fmt.Errorf("invalid delimiter %q: expected a single character", value)
+			}
+			mapping.Delimiter = r
+		case "decimal_comma", "invert":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return CSVMapping{}, fmt.Errorf("invalid %s %q: expected true or false", key, value)
+			}
+			if key == "invert" {
+				mapping.Invert = b
+			} else {
+				mapping.DecimalComma = b
+			}
+		default:
+			return CSVMapping{}, fmt.Errorf("unknown mapping key %q", key)
+		}
+	}
+	return mapping, nil
+}
+
+var commonDateLayouts = []string{"2006-01-02", "01/02/2006", "02.01.2006", "2006/01/02", "1/2/2006", "Jan 2, 2006", "02 Jan 2006"}
+
+type csvColumns struct {
+	date, amount, description, category, debit, credit int
+}
+
+// ParseCSV reads a statement exported as CSV with a header row.
+func ParseCSV(r io.Reader, mapping CSVMapping) ([]Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != 0 {
+		reader.Comma = mapping.Delimiter
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("import: read csv header: %w", err)
+	}
+	cols := csvColumns{
+		date:        findColumn(header, mapping.Date),
+		amount:      findColumn(header, mapping.Amount),
+		description: findColumn(header, mapping.Description),
+		category:    findColumn(header, mapping.Category),
+		debit:       findColumn(header, mapping.Debit),
+		credit:      findColumn(header, mapping.Credit),
+	}
+	if cols.date < 0 {
+		return nil, fmt.Errorf("import: no date column matching %q in header %q", mapping.Date, header)
+	}
+	if cols.amount < 0 && cols.debit < 0 {
+		return nil, fmt.Errorf("import: no amount or debit column in header %q", header)
+	}
+
+	var txns []Transaction
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("import: read csv: %w", err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		txn, err := parseCSVRecord(record, cols, mapping)
+		if err != nil {
+			return nil, fmt.Errorf("import: csv line %d: %w", line, err)
+		}
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+func parseCSVRecord(record []string, cols csvColumns, mapping CSVMapping) (Transaction, error) {
+	var txn Transaction
+
+	date, err := parseDate(field(record, cols.date), mapping.DateFormat)
+	if err != nil {
+		return txn, err
+	}
+	txn.Date = date
+	txn.Description = field(record, cols.description)
+	txn.Category = field(record, cols.category)
+
+	if cols.amount >= 0 && field(record, cols.amount) != "" {
+		amount, err := parseAmount(field(record, cols.amount), mapping.DecimalComma)
+		if err != nil {
+			return txn, err
+		}
+		if mapping.Invert {
+			amount = -amount
+		}
+		txn.Amount = amount
+		return txn, nil
+	}
+
+	// Split columns are unsigned: debits go out, credits come in.
+	if raw := field(record, cols.debit); raw != "" {
+		amount, err := parseAmount(raw, mapping.DecimalComma)
+		if err != nil {
+			return txn, err
+		}
+		txn.Amount = -abs(amount)
+	} else if raw := field(record, cols.credit); raw != "" {
+		amount, err := parseAmount(raw, mapping.DecimalComma)
+		if err != nil {
+			return txn, err
+		}
+		txn.Amount = abs(amount)
+	}
+	return txn, nil
+}
+
+func findColumn(header []string, names string) int {
+	if names == "" {
+		return -1
+	}
+	for _, name := range strings.Split(names, "|") {
+		name = strings.TrimSpace(name)
+		for i, h := range header {
+			// Some exports start with a UTF-8 byte order mark.
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func isBlank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDate(raw, layout string) (time.Time, error) {
+	layouts := commonDateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, raw); err == nil {
+			return statementDate(t.Year(), t.Month(), t.Day()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", raw)
+}
+
+// statementDate pins a calendar day to noon UTC so it stays on the same day in any
+// timezone a report might use.
+func statementDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+// parseAmount reads amounts like "-1,234.56", "(12.00)", "$3.50" or, with decimalComma,
+// "1.234,56 €".
+func parseAmount(raw string, decimalComma bool) (float64, error) {
+	text := strings.TrimSpace(raw)
+	negative := false
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		negative = true
+		text = text[1 : len(text)-1]
+	}
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',', r == '-', r == '+':
+			return r
+		default:
+			return -1
+		}
+	}, text)
+	if decimalComma {
+		text = strings.ReplaceAll(text, ".", "")
+		text = strings.ReplaceAll(text, ",", ".")
+	} else {
+		text = strings.ReplaceAll(text, ",", "")
+	}
+	amount, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unrecognized amount %q", raw)
+	}
+	if negative {
+		amount = -abs(amount)
+	}
+	return amount, nil
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVDefaultMapping(t *testing.T) {
+	input := "\ufeffDate,Description,Amount,Category\n" +
+		"2026-10-01,Blue Bottle Coffee,-4.50,\n" +
+		"2026-10-02,\"Payroll, ACME\",2500.00,Income\n" +
+		",,,\n" +
+		"2026-10-03,Grocer,\"(1,234.56)\",Groceries\n"
+
+	txns, err := ParseCSV(strings.NewReader(input), DefaultCSVMapping())
+	if err != nil {
+		t.Fatalf("ParseCSV error: %v", err)
+	}
+	if len(txns) != 3 {
+		t.Fatalf("expected 3 transactions, got %#v", txns)
+	}
+	if txns[0].Amount != -4.5 || txns[0].Description != "Blue Bottle Coffee" || !txns[0].Date.Equal(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected first transaction %#v", txns[0])
+	}
+	if txns[1].Amount != 2500 || txns[1].Description != "Payroll, ACME" || txns[1].Category != "Income" {
+		t.Fatalf("unexpected second transaction %#v", txns[1])
+	}
+	if txns[2].Amount != -1234.56 || txns[2].Category != "Groceries" {
+		t.Fatalf("unexpected third transaction %#v", txns[2])
+	}
+}
+
+func TestParseCSVCustomMapping(t *testing.T) {
+	mapping, err := ParseCSVMapping("date=Buchungstag, description=Empfänger, debit=Soll, credit=Haben, date_format=02.01.2006, delimiter=;, decimal_comma=true")
+	if err != nil {
+		t.Fatalf("ParseCSVMapping error: %v", err)
+	}
+	input := "Buchungstag;Empfänger;Soll;Haben\n" +
+		"15.10.2026;Bäckerei;1.234,50;\n" +
+		"16.10.2026;Gehalt;;3.000,00\n"
+
+	txns, err := ParseCSV(strings.NewReader(input), mapping)
+	if err != nil {
+		t.Fatalf("ParseCSV error: %v", err)
+	}
+	if len(txns) != 2 || txns[0].Amount != -1234.5 || txns[1].Amount != 3000 {
+		t.Fatalf("unexpected transactions %#v", txns)
+	}
+	if txns[0].Date.Day() != 15 || txns[0].Description != "Bäckerei" {
+		t.Fatalf("unexpected first transaction %#v", txns[0])
+	}
+}
+
+func TestParseCSVInvertedSign(t *testing.T) {
+	mapping, err := ParseCSVMapping("invert=true")
+	if err != nil {
+		t.Fatalf("ParseCSVMapping error: %v", err)
+	}
+	txns, err := ParseCSV(strings.NewReader("Posted Date,Payee,Amount\n10/05/2026,Cinema,$12.00\n"), mapping)
+	if err != nil {
+		t.Fatalf("ParseCSV error: %v", err)
+	}
+	if len(txns) != 1 || txns[0].Amount != -12 || txns[0].Date.Month() != time.October {
+		t.Fatalf("unexpected transactions %#v", txns)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing date column":   "Description,Amount\nx,1\n",
+		"missing amount column": "Date,Description\n2026-10-01,x\n",
+		"bad date":              "Date,Amount\nyesterday,1\n",
+		"bad amount":            "Date,Amount\n2026-10-01,lots\n",
+	}
+	for name, input := range cases {
+		if _, err := ParseCSV(strings.NewReader(input), DefaultCSVMapping()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	for _, raw := range []string{"nope", "color=red", "delimiter=;;", "invert=maybe"} {
+		if _, err := ParseCSVMapping(raw); err == nil {
+			t.Errorf("ParseCSVMapping(%q): expected error", raw)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package importer reads bank statements (CSV, OFX and QFX) and records the transactions
+// that are missing from the expense store.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/logging"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// DefaultCategory is used for rows without a category when categorization is off or fails.
+const DefaultCategory = "Uncategorized"
+
+// DefaultMatchWindow is how far apart a statement row and a bot-entered expense may be
+// dated and still be considered the same purchase; banks often post a few days late.
+const DefaultMatchWindow = 3 * 24 * time.Hour
+
+// Transaction is one statement row. Amount is signed like a bank statement: negative for
+// money going out, positive for money coming in.
+type Transaction struct {
+	Date        time.Time
+	Amount      float64
+	Description string
+	// Category is only set when the statement itself provides one.
+	Category string
+}
+
+// Parse reads a statement, choosing the parser from the file name and falling back to
+// sniffing the content for an OFX header.
+func Parse(name string, r io.Reader, mapping CSVMapping) ([]Transaction, error) {
+	br := bufio.NewReader(r)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ofx", ".qfx":
+		return ParseOFX(br)
+	case ".csv":
+		return ParseCSV(br, mapping)
+	}
+	head, _ := br.Peek(512)
+	if bytes.Contains(bytes.ToUpper(head), []byte("OFXHEADER")) || bytes.Contains(bytes.ToUpper(head), []byte("<OFX>")) {
+		return ParseOFX(br)
+	}
+	return ParseCSV(br, mapping)
+}
+
+// Options control how transactions are recorded.
+type Options struct {
+	// UserID owns the imported expenses and scopes duplicate matching.
+	UserID int64
+	// MatchWindow defaults to DefaultMatchWindow.
+	MatchWindow time.Duration
+	// Categorizer, when set, assigns categories to rows the statement left uncategorized.
+	Categorizer extractor.Service
+	// DryRun reports what would be imported without saving anything.
+	DryRun bool
+}
+
+// Result summarizes an import.
+type Result struct {
+	Imported int
+	// Duplicates matched an expense that was already recorded.
+	Duplicates int
+	// Skipped are incoming payments and zero-amount rows, which are not expenses.
+	Skipped int
+	// Items are the expenses that were (or, in a dry run, would be) recorded.
+	Items []expense.Item
+}
+
+// Import records the outgoing transactions that have no matching expense in store.
+// An expense matches when the amount is equal and the dates are within the match window;
+// each existing expense absorbs at most one statement row.
+func Import(ctx context.Context, store storage.ExpenseStore, txns []Transaction, opts Options) (Result, error) {
+	var result Result
+	window := opts.MatchWindow
+	if window <= 0 {
+		window = DefaultMatchWindow
+	}
+
+	var outgoing []Transaction
+	for _, txn := range txns {
+		if txn.Amount >= 0 {
+			result.Skipped++
+			continue
+		}
+		outgoing = append(outgoing, txn)
+	}
+	if len(outgoing) == 0 {
+		return result, nil
+	}
+
+	existing, err := loadExisting(ctx, store, outgoing, opts.UserID, window)
+	if err != nil {
+		return result, err
+	}
+
+	for _, txn := range outgoing {
+		amount := -txn.Amount
+		if match := findMatch(existing, amount, txn.Date, window); match >= 0 {
+			existing[match].used = true
+			result.Duplicates++
+			continue
+		}
+
+		item := expense.Item{
+			Category:    txn.Category,
+			Amount:      amount,
+			Description: strings.TrimSpace(txn.Description),
+			Date:        txn.Date,
+		}
+		if item.Category == "" {
+			item.Category = categorize(ctx, opts.Categorizer, item)
+		}
+		if item.Description == "" {
+			item.Description = item.Category
+		}
+
+		if !opts.DryRun {
+			if _, err := store.SaveExpense(ctx, opts.UserID, item); err != nil {
+				return result, fmt.Errorf("import: save %s %.2f: %w", item.Date.Format("2006-01-02"), amount, err)
+			}
+		}
+		result.Imported++
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+type candidate struct {
+	storage.Expense
+	used bool
+}
+
+func loadExisting(ctx context.Context, store storage.ExpenseStore, txns []Transaction, userID int64, window time.Duration) ([]candidate, error) {
+	first, last := txns[0].Date, txns[0].Date
+	for _, txn := range txns[1:] {
+		if txn.Date.Before(first) {
+			first = txn.Date
+		}
+		if txn.Date.After(last) {
+			last = txn.Date
+		}
+	}
+
+	var existing []candidate
+	filter := storage.ExportFilter{UserID: userID, Since: first.Add(-window), Until: last.Add(window), Kind: expense.KindExpense}
+	err := store.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		existing = append(existing, candidate{Expense: e})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import: load existing expenses: %w", err)
+	}
+	return existing, nil
+}
+
+// findMatch returns the unused expense with the same amount closest in time, or -1.
+func findMatch(existing []candidate, amount float64, date time.Time, window time.Duration) int {
+	best, bestGap := -1, window+1
+	for i, c := range existing {
+		if c.used || math.Abs(c.Amount-amount) >= 0.005 {
+			continue
+		}
+		gap := c.CreatedAt.Sub(date)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= window && gap < bestGap {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+func categorize(ctx context.Context, categorizer extractor.Service, item expense.Item) string {
+	if categorizer == nil || item.Description == "" {
+		return DefaultCategory
+	}
+	extracted, err := categorizer.Extract(ctx, fmt.Sprintf("%s %.2f", item.Description, item.Amount))
+	if err != nil {
+		slog.WarnContext(ctx, "categorize imported row", logging.Text("description", item.Description), "err", err)
+		return DefaultCategory
+	}
+	if extracted.Category == "" {
+		return DefaultCategory
+	}
+	return extracted.Category
+}
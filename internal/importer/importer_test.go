@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+type categoryStub struct {
+	calls []string
+	err   error
+}
+
+func (c *categoryStub) Extract(_ context.Context, text string) (expense.Item, error) {
+	c.calls = append(c.calls, text)
+	if c.err != nil {
+		return expense.Item{}, c.err
+	}
+	return expense.Item{Category: "Coffee", Amount: 1, Description: "ignored"}, nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC)
+}
+
+func storedItems(t *testing.T, store *memory.Store) []storage.Expense {
+	t.Helper()
+	var items []storage.Expense
+	if err := store.ExportExpenses(context.Background(), storage.ExportFilter{}, func(e storage.Expense) error {
+		items = append(items, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("ExportExpenses error: %v", err)
+	}
+	return items
+}
+
+func TestImportReconcilesAgainstExistingExpenses(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	// Entered through the bot on the day of purchase; the bank posts it two days later.
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: 4.5, Description: "Latte", Date: day(1).Add(-3 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// Another user's expense must not absorb this user's statement rows.
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 20, Description: "Lunch", Date: day(5)}); err != nil {
+		t.Fatal(err)
+	}
+
+	txns := []Transaction{
+		{Date: day(3), Amount: -4.5, Description: "BLUE BOTTLE"},
+		{Date: day(3), Amount: -4.5, Description: "BLUE BOTTLE"},
+		{Date: day(5), Amount: -20, Description: "DINER", Category: "Food"},
+		{Date: day(6), Amount: 1500, Description: "PAYROLL"},
+		{Date: day(20), Amount: -4.5, Description: "BLUE BOTTLE"},
+	}
+	categorizer := &categoryStub{}
+
+	result, err := Import(ctx, store, txns, Options{UserID: 7, Categorizer: categorizer})
+	if err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	if result.Imported != 3 || result.Duplicates != 1 || result.Skipped != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(categorizer.calls) != 2 || categorizer.calls[0] != "BLUE BOTTLE 4.50" {
+		t.Fatalf("expected only uncategorized rows to be categorized, got %q", categorizer.calls)
+	}
+
+	items := storedItems(t, store)
+	if len(items) != 5 {
+		t.Fatalf("expected 3 new expenses, store has %d", len(items))
+	}
+	owned := 0
+	for _, e := range items {
+		if e.UserID == 7 {
+			owned++
+		}
+	}
+	if owned != 4 {
+		t.Fatalf("expected imported expenses to belong to user 7, got %#v", items)
+	}
+	if result.Items[0].Category != "Coffee" || result.Items[0].Description != "BLUE BOTTLE" || result.Items[0].Amount != 4.5 {
+		t.Fatalf("expected statement fields with extracted category, got %#v", result.Items[0])
+	}
+	if result.Items[1].Category != "Food" {
+		t.Fatalf("expected the statement category to be kept, got %#v", result.Items[1])
+	}
+
+	// Importing the same statement again only finds duplicates.
+	again, err := Import(ctx, store, txns, Options{UserID: 7})
+	if err != nil {
+		t.Fatalf("second Import error: %v", err)
+	}
+	if again.Imported != 0 || again.Duplicates != 4 {
+		t.Fatalf("expected a re-import to be a no-op, got %+v", again)
+	}
+}
+
+func TestImportDryRunAndCategorizerFailure(t *testing.T) {
+	store := memory.NewStore()
+	txns := []Transaction{{Date: day(2), Amount: -12, Description: "ACME"}}
+
+	result, err := Import(context.Background(), store, txns, Options{UserID: 7, DryRun: true, Categorizer: &categoryStub{err: errors.New("offline")}})
+	if err != nil {
+		t.Fatalf("Import error: %v", err)
+	}
+	if result.Imported != 1 || result.Items[0].Category != DefaultCategory {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(storedItems(t, store)) != 0 {
+		t.Fatal("dry run must not save expenses")
+	}
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagPattern matches OFX tags. OFX 1.x is SGML where leaf elements have no closing tag;
+// OFX 2.x is XML. Reading each tag's text up to the next "<" handles both.
+var tagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+// ParseOFX reads the bank transactions of an OFX or QFX (Quicken's OFX variant) file.
+func ParseOFX(r io.Reader) ([]Transaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("import: read ofx: %w", err)
+	}
+	body := string(data)
+	if !strings.Contains(strings.ToUpper(body), "<OFX>") {
+		return nil, fmt.Errorf("import: not an OFX document")
+	}
+
+	var (
+		txns    []Transaction
+		current map[string]string
+	)
+	for _, m := range tagPattern.FindAllStringSubmatch(body, -1) {
+		closing, tag, value := m[1] == "/", strings.ToUpper(m[2]), strings.TrimSpace(m[3])
+		switch {
+		case tag == "STMTTRN" && !closing:
+			current = map[string]string{}
+		case tag == "STMTTRN" && closing:
+			if current == nil {
+				continue
+			}
+			txn, err := ofxTransaction(current)
+			if err != nil {
+				return nil, fmt.Errorf("import: ofx transaction %s: %w", current["FITID"], err)
+			}
+			txns = append(txns, txn)
+			current = nil
+		case current != nil && !closing && value != "":
+			current[tag] = decodeEntities(value)
+		}
+	}
+	return txns, nil
+}
+
+func ofxTransaction(fields map[string]string) (Transaction, error) {
+	var txn Transaction
+
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return txn, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+	year, errY := strconv.Atoi(posted[0:4])
+	month, errM := strconv.Atoi(posted[4:6])
+	day, errD := strconv.Atoi(posted[6:8])
+	if errY != nil || errM != nil || errD != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return txn, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+	txn.Date = statementDate(year, time.Month(month), day)
+
+	amount, err := parseAmount(fields["TRNAMT"], false)
+	if err != nil {
+		return txn, err
+	}
+	txn.Amount = amount
+
+	// NAME is the payee; MEMO often repeats it with extra reference numbers.
+	txn.Description = fields["NAME"]
+	if txn.Description == "" {
+		txn.Description = fields["MEMO"]
+	}
+	return txn, nil
+}
+
+var entityReplacer = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'")
+
+func decodeEntities(value string) string {
+	return entityReplacer.Replace(value)
+}
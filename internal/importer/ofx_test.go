@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKTRANLIST>
+<DTSTART>20261001
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20261004120000.000[-5:EST]
+<TRNAMT>-23.45
+<FITID>1001
+<NAME>TRADER JOE&amp;S #552
+<MEMO>POS PURCHASE
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20261005
+<TRNAMT>1500.00
+<FITID>1002
+<MEMO>DIRECT DEPOSIT
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20261012</DTPOSTED><TRNAMT>-9.99</TRNAMT><FITID>a1</FITID><NAME>NETFLIX.COM</NAME></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+
+func TestParseOFXSGML(t *testing.T) {
+	txns, err := ParseOFX(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatalf("ParseOFX error: %v", err)
+	}
+	if len(txns) != 2 {
+		t.Fatalf("expected 2 transactions, got %#v", txns)
+	}
+	if txns[0].Amount != -23.45 || txns[0].Description != "TRADER JOE&S #552" || !txns[0].Date.Equal(time.Date(2026, 10, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected first transaction %#v", txns[0])
+	}
+	if txns[1].Amount != 1500 || txns[1].Description != "DIRECT DEPOSIT" {
+		t.Fatalf("unexpected second transaction %#v", txns[1])
+	}
+}
+
+func TestParseOFXXML(t *testing.T) {
+	txns, err := ParseOFX(strings.NewReader(xmlStatement))
+	if err != nil {
+		t.Fatalf("ParseOFX error: %v", err)
+	}
+	if len(txns) != 1 || txns[0].Amount != -9.99 || txns[0].Description != "NETFLIX.COM" || txns[0].Date.Day() != 12 {
+		t.Fatalf("unexpected transactions %#v", txns)
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("Date,Amount\n")); err == nil {
+		t.Fatal("expected error for non-OFX input")
+	}
+	bad := "<OFX><STMTTRN><DTPOSTED>2026<TRNAMT>-1</STMTTRN></OFX>"
+	if _, err := ParseOFX(strings.NewReader(bad)); err == nil {
+		t.Fatal("expected error for invalid date")
+	}
+}
+
+func TestParseDetectsFormat(t *testing.T) {
+	// QFX files are OFX with Quicken extensions and go through the same parser.
+	for _, name := range []string{"statement.qfx", "statement.OFX", "download"} {
+		txns, err := Parse(name, strings.NewReader(sgmlStatement), DefaultCSVMapping())
+		if err != nil || len(txns) != 2 {
+			t.Fatalf("Parse(%q) = %d transactions, %v", name, len(txns), err)
+		}
+	}
+	txns, err := Parse("statement.csv", strings.NewReader("Date,Amount\n2026-10-01,-3\n"), DefaultCSVMapping())
+	if err != nil || len(txns) != 1 {
+		t.Fatalf("Parse csv = %#v, %v", txns, err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package locale holds a user's display and calendar settings: the language replies are
+// written in, the time zone that decides where days, weeks and months begin, the week's
+// first day, and how money is written.
+package locale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the zone database so time zones load on hosts without one.
+	_ "time/tzdata"
+)
+
+// Settings are a user's preferences. Start from Default; the zero value has no sensible
+// week start.
+type Settings struct {
+	// Language picks the message catalog; empty means English.
+	Language Language
+	// Location decides where days, weeks and months begin; nil means UTC.
+	Location *time.Location
+	// Currency is the symbol written before amounts, such as "$" or "€".
+	Currency string
+	// DecimalComma writes amounts as 3,50 instead of 3.50.
+	DecimalComma bool
+	// WeekStart is the first day of a calendar week.
+	WeekStart time.Weekday
+}
+
+// Default returns the settings of users who never changed them: English, UTC, dollars
+// with a decimal point, and weeks starting on Monday.
+func Default() Settings {
+	return Settings{Language: English, Location: time.UTC, Currency: "$", WeekStart: time.Monday}
+}
+
+// LoadLocation resolves an IANA time zone name such as "America/Bogota". Unlike
+// time.LoadLocation it rejects the empty name and "Local", which depend on the host.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// spanishWeekdays are the Spanish weekday names without accents, Sunday first.
+var spanishWeekdays = [...]string{"domingo", "lunes", "martes", "miercoles", "jueves", "viernes", "sabado"}
+
+// ParseWeekday accepts an English or Spanish weekday name or its three-letter
+// abbreviation, ignoring case and accents.
+func ParseWeekday(raw string) (time.Weekday, bool) {
+	raw = StripAccents(strings.ToLower(strings.TrimSpace(raw)))
+	if len(raw) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		for _, name := range []string{strings.ToLower(d.String()), spanishWeekdays[d]} {
+			if raw == name || raw == name[:3] {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// spanishMonths are the Spanish month names without accents, January first.
+var spanishMonths = [...]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}
+
+// ParseMonth accepts an English or Spanish month name, ignoring case and accents.
+// "setiembre" is accepted for September.
+func ParseMonth(raw string) (time.Month, bool) {
+	raw = StripAccents(strings.ToLower(strings.TrimSpace(raw)))
+	if raw == "setiembre" {
+		return time.September, true
+	}
+	for m := time.January; m <= time.December; m++ {
+		if raw == strings.ToLower(m.String()) || raw == spanishMonths[m-1] {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// accents maps the accented letters of Spanish to their plain forms.
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U")
+
+// StripAccents removes the accents from Spanish vowels, so "miércoles" matches
+// "miercoles". The ñ is kept.
+func StripAccents(s string) string {
+	return accents.Replace(s)
+}
+
+// Loc returns the settings' location, UTC when none is set.
+func (s Settings) Loc() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+// In returns t in the settings' location.
+func (s Settings) In(t time.Time) time.Time {
+	return t.In(s.Loc())
+}
+
+// StartOfDay returns midnight of the local day containing t.
+func (s Settings) StartOfDay(t time.Time) time.Time {
+	t = s.In(t)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns midnight of the first day of the local week containing t.
+func (s Settings) StartOfWeek(t time.Time) time.Time {
+	day := s.StartOfDay(t)
+	offset := (int(day.Weekday()) - int(s.WeekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// StartOfMonth returns midnight of the first day of the local month containing t.
+func (s Settings) StartOfMonth(t time.Time) time.Time {
+	t = s.In(t)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfYear returns midnight of January 1st of the local year containing t.
+func (s Settings) StartOfYear(t time.Time) time.Time {
+	t = s.In(t)
+	return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+}
+
+// Date writes the local calendar date of t as YYYY-MM-DD.
+func (s Settings) Date(t time.Time) string {
+	return s.In(t).Format("2006-01-02")
+}
+
+// DateTime writes the local date and time of t, to the minute, with the zone abbreviation.
+func (s Settings) DateTime(t time.Time) string {
+	return s.In(t).Format("2006-01-02 15:04 MST")
+}
+
+// Money writes an amount with two decimals and the currency symbol, such as "$12.50" or
+// "-€3,00". Thousands are not grouped.
+func (s Settings) Money(amount float64) string {
+	return s.moneySign(amount) + s.Currency + s.Number(math.Abs(amount), 2)
+}
+
+func (s Settings) moneySign(amount float64) string {
+	if math.Round(amount*100) < 0 {
+		return "-"
+	}
+	return ""
+}
+
+// Number writes v with the given number of decimals and the user's decimal separator.
+func (s Settings) Number(v float64, decimals int) string {
+	out := strconv.FormatFloat(v, 'f', decimals, 64)
+	if s.DecimalComma {
+		out = strings.Replace(out, ".", ",", 1)
+	}
+	return out
+}
+
+// maxTypedAmount bounds amounts typed by users in either direction. It leaves room for
+// large goals and balances while keeping cents exact in a float64.
+const maxTypedAmount = 1e12
+
+// ParseAmount reads a plain number typed by the user, accepting a decimal comma when the
+// settings use one. NaN, infinities and amounts beyond a trillion are rejected.
+func (s Settings) ParseAmount(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if s.DecimalComma {
+		raw = strings.Replace(raw, ",", ".", 1)
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.Abs(v) > maxTypedAmount {
+		return 0, fmt.Errorf("locale: amount %q out of range", raw)
+	}
+	return v, nil
+}
+
+type settingsKey struct{}
+
+// WithSettings returns a copy of ctx carrying the settings of the user it acts for.
+func WithSettings(ctx context.Context, s Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, s)
+}
+
+// From returns the settings stored in ctx, or Default when there are none.
+func From(ctx context.Context) Settings {
+	if s, ok := ctx.Value(settingsKey{}).(Settings); ok {
+		return s
+	}
+	return Default()
+}
@@ -0,0 +1,95 @@
+package locale
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		settings Settings
+		amount   float64
+		want     string
+	}{
+		{settings: Default(), amount: 12.5, want: "$12.50"},
+		{settings: Default(), amount: -4, want: "-$4.00"},
+		{settings: Default(), amount: -0.001, want: "$0.00"},
+		{settings: Settings{Currency: "€", DecimalComma: true}, amount: 1234.567, want: "€1234,57"},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.Money(tt.amount); got != tt.want {
+			t.Errorf("Money(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	comma := Settings{DecimalComma: true}
+	if v, err := comma.ParseAmount("12,50"); err != nil || v != 12.5 {
+		t.Fatalf("expected 12.5, got %v (%v)", v, err)
+	}
+	if _, err := Default().ParseAmount("12,50"); err == nil {
+		t.Fatal("expected a decimal comma to be rejected by default")
+	}
+	for _, raw := range []string{"NaN", "inf", "-Inf", "1e308", "-2e12"} {
+		if v, err := Default().ParseAmount(raw); err == nil {
+			t.Errorf("ParseAmount(%q) = %v, expected an error", raw, v)
+		}
+	}
+}
+
+func TestCalendarBoundaries(t *testing.T) {
+	bogota, err := LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatalf("LoadLocation error: %v", err)
+	}
+	s := Default()
+	s.Location = bogota
+
+	// Sunday 2026-10-18 at 02:00 UTC is still Saturday evening in Bogotá.
+	now := time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)
+	if got := s.Date(now); got != "2026-10-17" {
+		t.Fatalf("expected the local date, got %s", got)
+	}
+	if got := s.StartOfDay(now); !got.Equal(time.Date(2026, 10, 17, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected start of day %v", got)
+	}
+	if got := s.StartOfWeek(now); s.Date(got) != "2026-10-12" {
+		t.Fatalf("expected the week to start on Monday 2026-10-12, got %v", got)
+	}
+	s.WeekStart = time.Sunday
+	if got := s.StartOfWeek(now); s.Date(got) != "2026-10-11" {
+		t.Fatalf("expected the week to start on Sunday 2026-10-11, got %v", got)
+	}
+	if got := s.StartOfMonth(now); s.Date(got) != "2026-10-01" || got.Hour() != 0 {
+		t.Fatalf("unexpected start of month %v", got)
+	}
+}
+
+func TestLoadLocationRejectsHostZones(t *testing.T) {
+	for _, name := range []string{"", "Local", "Mars/Olympus"} {
+		if _, err := LoadLocation(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	if d, ok := ParseWeekday("Sun"); !ok || d != time.Sunday {
+		t.Fatalf("expected Sunday, got %v %v", d, ok)
+	}
+	if _, ok := ParseWeekday("mo"); ok {
+		t.Fatal("expected too short a name to be rejected")
+	}
+}
+
+func TestFromDefaults(t *testing.T) {
+	if got := From(context.Background()); got != Default() {
+		t.Fatalf("expected defaults, got %#v", got)
+	}
+	s := Settings{Currency: "€"}
+	if got := From(WithSettings(context.Background(), s)); got != s {
+		t.Fatalf("expected the stored settings, got %#v", got)
+	}
+}
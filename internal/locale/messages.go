@@ -0,0 +1,124 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Language selects the message catalog replies are written from.
+type Language string
+
+const (
+	English Language = "en"
+	Spanish Language = "es"
+)
+
+// Languages lists the languages with a complete catalog.
+var Languages = []Language{English, Spanish}
+
+// catalogs maps each language to its messages. Keys are dotted names such as
+// "stats.total"; plural messages come in ".one" and ".other" pairs.
+var catalogs = map[Language]map[string]string{
+	English: english,
+	Spanish: spanish,
+}
+
+// ParseLanguage accepts a language code or name in English or Spanish, ignoring case.
+func ParseLanguage(raw string) (Language, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "en", "english", "inglés", "ingles":
+		return English, true
+	case "es", "spanish", "español", "espanol":
+		return Spanish, true
+	default:
+		return "", false
+	}
+}
+
+// Lang returns the settings' language, English when none or an unknown one is set.
+func (s Settings) Lang() Language {
+	if _, ok := catalogs[s.Language]; ok {
+		return s.Language
+	}
+	return English
+}
+
+// message looks key up in the user's language, then in English. A missing key comes back
+// as itself so it is easy to spot.
+func (s Settings) message(key string) string {
+	if msg, ok := catalogs[s.Lang()][key]; ok {
+		return msg
+	}
+	if msg, ok := english[key]; ok {
+		return msg
+	}
+	return key
+}
+
+// T formats the catalog message key with args.
+func (s Settings) T(key string, args ...any) string {
+	msg := s.message(key)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Plural formats the ".one" or ".other" form of key for n, passing n as the first
+// argument, e.g. Plural("count.expenses", 3) gives "3 expenses". Both languages use the
+// singular for exactly one.
+func (s Settings) Plural(key string, n int, args ...any) string {
+	form := ".other"
+	if n == 1 {
+		form = ".one"
+	}
+	return s.T(key+form, append([]any{n}, args...)...)
+}
+
+// Category translates one of the built-in category names, which are stored in English.
+// Other categories are returned unchanged.
+func (s Settings) Category(name string) string {
+	if msg, ok := catalogs[s.Lang()]["category."+name]; ok {
+		return msg
+	}
+	return name
+}
+
+// Weekday names d in the user's language.
+func (s Settings) Weekday(d time.Weekday) string {
+	return s.T("weekday." + d.String())
+}
+
+// Month names m in the user's language.
+func (s Settings) Month(m time.Month) string {
+	return s.T("month." + m.String())
+}
+
+// Error describes err in the user's language when it is a *Message, and falls back to its
+// English text otherwise.
+func (s Settings) Error(err error) string {
+	var m *Message
+	if errors.As(err, &m) {
+		return s.T(m.Key, m.Args...)
+	}
+	return err.Error()
+}
+
+// Message is a catalog message waiting for the user's settings. It doubles as an error so
+// that parsers can report problems without knowing who they are talking to.
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// Errorf returns a *Message error for key and args.
+func Errorf(key string, args ...any) error {
+	return &Message{Key: key, Args: args}
+}
+
+// Error writes the message in English.
+func (m *Message) Error() string {
+	return Default().T(m.Key, m.Args...)
+}
@@ -0,0 +1,319 @@
+package locale
+
+// english is the reference catalog; every other language has the same keys.
+var english = map[string]string{
+	"account_type.cash":   "cash",
+	"account_type.credit": "credit",
+	"account_type.debit":  "debit",
+
+	"accounts.add_failed":      "Failed to add the account, please try again.",
+	"accounts.added":           "Added %s (%s). Mention it when recording an expense, e.g. \"coffee 3.50 with %s\".",
+	"accounts.card_spent":      " (%s card, %s this month)",
+	"accounts.default_failed":  "Failed to change your default account, please try again.",
+	"accounts.default_set":     "Expenses that name no account now go to %s.",
+	"accounts.disabled":        "Accounts are not enabled.",
+	"accounts.err.balance":     "%q is not an opening balance",
+	"accounts.err.name_length": "account names can be at most %d characters",
+	"accounts.err.single_word": "account names are a single word",
+	"accounts.err.type":        "tell me whether %s is cash, debit or credit",
+	"accounts.exists":          "You already have an account named %s.",
+	"accounts.list_default":    " — default",
+	"accounts.list_heading":    "Your accounts:\n",
+	"accounts.list_item":       "- %s (%s)",
+	"accounts.load_failed":     "Failed to load your accounts.",
+	"accounts.none":            "You have no accounts yet. Add one with /accounts add <name> <cash|debit|credit>.",
+	"accounts.not_found":       "You have no account named %s.",
+	"accounts.section_heading": "Accounts:\n",
+	"accounts.usage":           "Usage: /accounts [list] | /accounts add <name> <cash|debit|credit> [opening balance] | /accounts default <name>, e.g. `/accounts add Visa credit`",
+
+	"add.usage": "Send an expense description after /add, e.g. `/add Coffee $3.50`.",
+
+	"ago.just_now":      "just now",
+	"ago.on_date":       "on %s",
+	"ago.minutes.one":   "%d minute ago",
+	"ago.minutes.other": "%d minutes ago",
+
+	"ask.disabled":       "Questions are not enabled.",
+	"ask.failed":         "Failed to answer the question, please try again.",
+	"ask.not_understood": "Sorry, I couldn't understand that question. Try something like \"how much did I spend on coffee last month?\"",
+	"ask.usage":          "Ask about your spending after /ask, e.g. `/ask how much did I spend on coffee in September?`",
+
+	"category.Coffee":        "Coffee",
+	"category.Entertainment": "Entertainment",
+	"category.Food":          "Food",
+	"category.General":       "General",
+	"category.Groceries":     "Groceries",
+	"category.Health":        "Health",
+	"category.Housing":       "Housing",
+	"category.Income":        "Income",
+	"category.Refund":        "Refund",
+	"category.Salary":        "Salary",
+	"category.Shopping":      "Shopping",
+	"category.Transport":     "Transport",
+	"category.Travel":        "Travel",
+	"category.Uncategorized": "Uncategorized",
+	"category.Utilities":     "Utilities",
+
+	"chart.daily":         "Daily totals",
+	"chart.empty":         "No expenses recorded for %s.",
+	"chart.invalid":       "Invalid chart: %s.\n%s",
+	"chart.load_failed":   "Failed to load expenses: %v",
+	"chart.other":         "Other",
+	"chart.render_failed": "Failed to draw the chart.",
+	"chart.send_failed":   "Failed to send the chart: %v",
+	"chart.title":         "Spending %s to %s",
+	"chart.usage":         "Usage: /chart [week|month|year|all|<N>d|YYYY-MM], e.g. `/chart month`.",
+	"chart.week_label":    "%s %02d",
+	"chart.weekly":        "Weekly totals",
+
+	"command.unknown": "Unknown command: /%s",
+
+	"common.and_more":    "…and %d more\n",
+	"common.list_amount": "- %s: %s",
+	"common.sorry":       "Sorry, %s.\n%s",
+
+	"count.attempts.one":   "%d attempt",
+	"count.attempts.other": "%d attempts",
+	"count.calls.one":      "%d call",
+	"count.calls.other":    "%d calls",
+	"count.expenses.one":   "%d expense",
+	"count.expenses.other": "%d expenses",
+	"count.hours.one":      "%d hour",
+	"count.hours.other":    "%d hours",
+	"count.minutes.one":    "%d minute",
+	"count.minutes.other":  "%d minutes",
+
+	"dashboard.disabled":     "The dashboard is not enabled.",
+	"dashboard.failed":       "Failed to create a dashboard link. Please try again.",
+	"dashboard.link":         "Open your dashboard (single use, valid for %s):\n%s",
+	"dashboard.private_only": "Send /dashboard in a private chat with me to get a login link.",
+
+	"duplicate.discard_button":  "Discard",
+	"duplicate.discarded":       "Discarded the duplicate.",
+	"duplicate.discarded_short": "Discarded",
+	"duplicate.expired":         "This confirmation has expired. Send the expense again if you still want it.",
+	"duplicate.expired_short":   "Expired",
+	"duplicate.failed_short":    "Failed",
+	"duplicate.prompt":          "Looks like a duplicate of #%d recorded %s — save anyway?\n%s: %s (%s)",
+	"duplicate.save_button":     "Save anyway",
+	"duplicate.saved_short":     "Saved",
+
+	"expense.low_confidence":  "\n(Parsed offline with basic rules; please double-check.)",
+	"expense.not_found":       "Expense #%d not found.",
+	"expense.recorded":        "Recorded",
+	"expense.recorded_id":     "%s\nID: #%d",
+	"expense.recorded_income": "Recorded income",
+	"expense.reply":           "%s\nDescription: %s\nCategory: %s\nAmount: %s",
+	"expense.reply_account":   "\nAccount: %s",
+	"expense.reply_date":      "\nDate: %s",
+	"expense.reply_tags":      "\nTags: %s",
+	"expense.store_failed":    "Failed to store expense: %v",
+
+	"export.caption": "Expenses (%s)",
+	"export.failed":  "Failed to export expenses: %v",
+	"export.invalid": "Invalid export: %s.\n%s",
+	"export.usage":   "Usage: /export [week|month|year|all|<N>d|YYYY-MM] [csv|json], e.g. `/export month json`.",
+
+	"extract.budget_exhausted": "The monthly AI budget is used up, so only simple messages like \"Coffee $3.50\" work until next month.",
+	"extract.circuit_open":     "The expense parser is temporarily unavailable. Please try again in a few minutes.",
+	"extract.invalid_response": "I couldn't understand that expense. Try rephrasing it, e.g. \"Lunch $12.50\".",
+	"extract.no_amount":        "I couldn't find an amount in that message. Try something like \"Coffee $3.50\".",
+	"extract.rate_limited":     "The expense parser is busy right now. Please try again shortly.",
+	"extract.server_error":     "The expense parser is having trouble right now. Please try again later.",
+	"extract.timeout":          "The expense parser took too long to respond. Please try again.",
+	"extract.too_long":         "That message is too long to read as an expense. Please keep it under %d characters.",
+	"extract.unauthorized":     "The expense parser is misconfigured. Please let the bot administrator know.",
+	"extract.unknown":          "Something went wrong while reading that expense. Please try again.",
+
+	"goals.add_failed":         "Failed to add the goal, please try again.",
+	"goals.added":              "Added goal %q: save %s",
+	"goals.added_by":           " by %s",
+	"goals.added_hint":         ". Log savings with /goal save %q <amount>.",
+	"goals.contribute_failed":  "Failed to log the contribution, please try again.",
+	"goals.delete_failed":      "Failed to delete the goal, please try again.",
+	"goals.deleted":            "Deleted goal %q.",
+	"goals.disabled":           "Savings goals are not enabled.",
+	"goals.due":                ", due %s",
+	"goals.err.amount":         "%q is not an amount",
+	"goals.err.date":           "%q is not a date like 2027-04 or 2027-04-15",
+	"goals.err.name_length":    "goal names must be 1 to %d characters",
+	"goals.err.past":           "the deadline has already passed",
+	"goals.err.quotes":         "put multi-word goal names in quotes",
+	"goals.err.target":         "%q is not a target amount",
+	"goals.err.unclosed_quote": "a quote is missing its closing mark",
+	"goals.exists":             "You already have a goal named %q.",
+	"goals.list_heading":       "Your goals:\n",
+	"goals.load_failed":        "Failed to load your goals.",
+	"goals.logged":             "Logged %s toward %q.",
+	"goals.logged_progress":    "Logged %s toward %s.\n%s",
+	"goals.no_savings":         "\n  No savings logged yet.",
+	"goals.none":               "You have no savings goals. Add one with /goal add \"<name>\" <target> [by YYYY-MM].",
+	"goals.not_found":          "You have no goal named %q.",
+	"goals.pace":               "\n  On pace for %s",
+	"goals.pace_behind":        "\n  On pace for %s (behind schedule)",
+	"goals.pace_on_track":      "\n  On pace for %s (on track)",
+	"goals.progress":           "- %s: %s of %s (%.0f%%)",
+	"goals.reached":            "\n  Reached!",
+	"goals.section_heading":    "Goals:\n",
+	"goals.section_item":       "- %s: %.0f%% (%s of %s)\n",
+	"goals.usage":              "Usage: /goals | /goal add \"<name>\" <target> [by YYYY-MM] | /goal save \"<name>\" <amount> | /goal delete \"<name>\", e.g. `/goal add \"Japan trip\" 3000 by 2027-04`",
+
+	"import.disabled":           "Statement import is not enabled.",
+	"import.download_failed":    "Failed to download that file. Please try again.",
+	"import.duplicates.one":     "%d already recorded",
+	"import.duplicates.other":   "%d already recorded",
+	"import.imported.one":       "Imported %d expense from %s",
+	"import.imported.other":     "Imported %d expenses from %s",
+	"import.skipped.one":        "%d incoming payment skipped",
+	"import.skipped.other":      "%d incoming payments skipped",
+	"import.stopped.one":        "Import stopped after %d expense: %v",
+	"import.stopped.other":      "Import stopped after %d expenses: %v",
+	"import.too_large":          "That file is too large to import (limit %d MB).",
+	"import.unreadable":         "Couldn't read %s as a bank statement: %v",
+	"import.would_import.one":   "Would import %d expense from %s",
+	"import.would_import.other": "Would import %d expenses from %s",
+
+	"language.en": "English",
+	"language.es": "Spanish",
+
+	"month.April":     "April",
+	"month.August":    "August",
+	"month.December":  "December",
+	"month.February":  "February",
+	"month.January":   "January",
+	"month.July":      "July",
+	"month.June":      "June",
+	"month.March":     "March",
+	"month.May":       "May",
+	"month.November":  "November",
+	"month.October":   "October",
+	"month.September": "September",
+
+	"pending.disabled":        "The pending queue is not enabled.",
+	"pending.discard_failed":  "Failed to discard pending #%d: %v",
+	"pending.discarded.one":   "Discarded %d pending expense.",
+	"pending.discarded.other": "Discarded %d pending expenses.",
+	"pending.gave_up":         "I still couldn't record pending #%d (%q) after %d attempts: %s\nIt stays in /pending until you discard it or resend the expense.",
+	"pending.list_footer":     "Use /pending discard <id> or /pending discard all.",
+	"pending.list_heading":    "Pending expenses:\n",
+	"pending.list_item":       "#%d %q: %s, %s\n  %s\n",
+	"pending.load_failed":     "Failed to load pending expenses: %v",
+	"pending.next_retry":      "next retry %s",
+	"pending.no_match":        "No pending expense matches %q.",
+	"pending.none":            "No pending expenses.",
+	"pending.queued":          "%s\nI saved your message as pending #%d and will retry automatically. Use /pending to review it.",
+	"pending.recorded":        "Pending #%d (%q) is now recorded.\n%s",
+	"pending.store_failed":    "I couldn't save that expense right now.",
+	"pending.stuck":           "stuck, no more retries",
+	"pending.usage":           "Usage: /pending, /pending discard <id> or /pending discard all",
+
+	"period.before":         "before %s",
+	"period.day":            "on %s",
+	"period.err.days":       "period %q must cover at least one day",
+	"period.err.month":      "unknown month %q",
+	"period.err.unexpected": "unexpected argument %q",
+	"period.err.unknown":    "unknown period %q",
+	"period.month":          "in %s %d",
+	"period.overall":        "overall",
+	"period.range":          "from %s to %s",
+	"period.since":          "since %s",
+	"period.year":           "in %d",
+
+	"query.all_categories": "in all categories",
+	"query.average":        "On average you spent %s per expense %s %s (%s).",
+	"query.count":          "%s %s %s.",
+	"query.list":           "%s %s %s, %s in total",
+	"query.list_latest":    "; the latest %d",
+	"query.matching":       "matching %q",
+	"query.max":            "Your biggest expense %s %s was %s for %s on %s (#%d).",
+	"query.min":            "Your smallest expense %s %s was %s for %s on %s (#%d).",
+	"query.none":           "No expenses %s %s.",
+	"query.on":             "on %s",
+	"query.on_matching":    "on %s matching %q",
+	"query.sum":            "You spent %s %s %s (%s).",
+	"query.tagged":         " tagged #%s",
+
+	"settings.comma":        "comma",
+	"settings.disabled":     "Settings are not enabled.",
+	"settings.err.currency": "currency symbols can be at most %d characters",
+	"settings.err.decimal":  "the decimal separator is either comma or point, not %q",
+	"settings.err.language": "%q is not a language I speak; choose en or es",
+	"settings.err.timezone": "%q is not a time zone like America/Bogota or Europe/Madrid",
+	"settings.err.unknown":  "there is no %q setting",
+	"settings.err.week":     "%q is not a day of the week",
+	"settings.point":        "point",
+	"settings.save_failed":  "Failed to save your settings, please try again.",
+	"settings.saved":        "Saved.\n",
+	"settings.summary":      "Your settings:\n- Language: %s\n- Time zone: %s (now %s)\n- Currency: %s\n- Decimal separator: %s (%s)\n- Weeks start on %s",
+	"settings.usage":        "Usage: /settings | /settings language en|es | /settings timezone <Area/City> | /settings currency <symbol> | /settings decimal comma|point | /settings week <day>, e.g. `/settings timezone America/Bogota`",
+
+	"stats.anomaly":         "\nHeads up: this is unusually high for %s (you usually spend about %s).",
+	"stats.by_category":     "By category:\n",
+	"stats.by_tag":          "By tag:\n",
+	"stats.change":          "Change: %s %s\n",
+	"stats.empty_month":     "No expenses recorded this month.",
+	"stats.empty_month_tag": "No expenses tagged #%s recorded this month.",
+	"stats.empty_week":      "No expenses recorded this week.",
+	"stats.empty_week_tag":  "No expenses tagged #%s recorded this week.",
+	"stats.err.tag":         "invalid tag %q",
+	"stats.heading_month":   "This month (since %s)",
+	"stats.heading_tag":     " for #%s",
+	"stats.heading_week":    "This week (since %s)",
+	"stats.income.one":      "Income: %[2]s across %[1]d entry\n",
+	"stats.income.other":    "Income: %[2]s across %[1]d entries\n",
+	"stats.load_failed":     "Failed to load stats: %v",
+	"stats.net":             "Net: %s (savings rate %.0f%%)\n",
+	"stats.total.one":       "Total: %[2]s across %[1]d expense\n",
+	"stats.total.other":     "Total: %[2]s across %[1]d expenses\n",
+	"stats.trend_gone":      "gone",
+	"stats.trend_new":       "new",
+	"stats.usage":           "Usage: /stats [week|month] [#tag]",
+	"stats.vs_month":        "vs last month",
+	"stats.vs_week":         "vs the same days last week",
+
+	"tags.disabled":     "Tags are not enabled.",
+	"tags.err.id":       "%q is not an expense ID",
+	"tags.err.invalid":  "%q is not a valid tag; use letters, digits, '-' and '_' (up to %d characters)",
+	"tags.err.missing":  "tell me which expense and which tags",
+	"tags.err.too_many": "an expense can have at most %d tags",
+	"tags.failed":       "Failed to update tags, please try again.",
+	"tags.none":         "Expense #%d has no tags.",
+	"tags.tagged":       "Expense #%d is tagged %s.",
+	"tags.updated":      "Updated the tags of expense #%d.",
+	"tags.usage":        "Usage: /tag <id> #tag [#more] [-#remove], e.g. `/tag 42 #vacation-2026 -#work`",
+
+	"token.disabled":      "The API is not enabled.",
+	"token.issue_failed":  "Failed to create an API token. Please try again.",
+	"token.issued":        "API token #%d (shown only once, keep it secret):\n%s\n\nSend it as \"Authorization: Bearer <token>\". Revoke it with /token revoke %d.",
+	"token.list_heading":  "Your API tokens:\n",
+	"token.list_item":     "#%d created %s\n",
+	"token.load_failed":   "Failed to load your API tokens.",
+	"token.none":          "You have no API tokens. Create one with /token new.",
+	"token.not_found":     "No API token #%d.",
+	"token.private_only":  "Send /token in a private chat with me to manage API tokens.",
+	"token.revoke_failed": "Failed to revoke the token. Please try again.",
+	"token.revoked":       "Revoked API token #%d.",
+	"token.revoked_all":   "Revoked all your API tokens.",
+	"token.usage":         "Usage: /token new | /token list | /token revoke <id|all>",
+
+	"usage.admins_only": "Only bot administrators can view usage.",
+	"usage.bucket":      "%s tokens in %s",
+	"usage.budget":      "Monthly budget: %s of %s tokens used (%.0f%%)",
+	"usage.by_day":      "By day:",
+	"usage.by_user":     "By user:",
+	"usage.cost":        ", ~$%s",
+	"usage.disabled":    "Usage tracking is not enabled.",
+	"usage.heading":     "LLM usage since %s (%s):",
+	"usage.load_failed": "Failed to load usage: %v",
+	"usage.total":       "Total: %s",
+	"usage.usage":       "Usage: /usage [days], with days between 1 and %d.",
+	"usage.user_id":     "user %d",
+
+	"weekday.Friday":    "Friday",
+	"weekday.Monday":    "Monday",
+	"weekday.Saturday":  "Saturday",
+	"weekday.Sunday":    "Sunday",
+	"weekday.Thursday":  "Thursday",
+	"weekday.Tuesday":   "Tuesday",
+	"weekday.Wednesday": "Wednesday",
+}
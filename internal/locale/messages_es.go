@@ -0,0 +1,319 @@
+package locale
+
+// spanish is the Spanish catalog, addressing the user as tú.
+var spanish = map[string]string{
+	"account_type.cash":   "efectivo",
+	"account_type.credit": "crédito",
+	"account_type.debit":  "débito",
+
+	"accounts.add_failed":      "No pude agregar la cuenta, inténtalo de nuevo.",
+	"accounts.added":           "Agregué %s (%s). Menciónala al registrar un gasto, p. ej. \"café 3,50 con %s\".",
+	"accounts.card_spent":      " (tarjeta de %s, %s este mes)",
+	"accounts.default_failed":  "No pude cambiar tu cuenta predeterminada, inténtalo de nuevo.",
+	"accounts.default_set":     "Los gastos que no nombran una cuenta ahora van a %s.",
+	"accounts.disabled":        "Las cuentas no están habilitadas.",
+	"accounts.err.balance":     "%q no es un saldo inicial",
+	"accounts.err.name_length": "los nombres de cuenta pueden tener como máximo %d caracteres",
+	"accounts.err.single_word": "los nombres de cuenta son una sola palabra",
+	"accounts.err.type":        "dime si %s es de efectivo, débito o crédito",
+	"accounts.exists":          "Ya tienes una cuenta llamada %s.",
+	"accounts.list_default":    " — predeterminada",
+	"accounts.list_heading":    "Tus cuentas:\n",
+	"accounts.list_item":       "- %s (%s)",
+	"accounts.load_failed":     "No pude cargar tus cuentas.",
+	"accounts.none":            "Todavía no tienes cuentas. Agrega una con /accounts add <nombre> <efectivo|débito|crédito>.",
+	"accounts.not_found":       "No tienes ninguna cuenta llamada %s.",
+	"accounts.section_heading": "Cuentas:\n",
+	"accounts.usage":           "Uso: /accounts [list] | /accounts add <nombre> <efectivo|débito|crédito> [saldo inicial] | /accounts default <nombre>, p. ej. `/accounts add Visa crédito`",
+
+	"add.usage": "Envía la descripción de un gasto después de /add, p. ej. `/add Café $3,50`.",
+
+	"ago.just_now":      "hace un momento",
+	"ago.on_date":       "el %s",
+	"ago.minutes.one":   "hace %d minuto",
+	"ago.minutes.other": "hace %d minutos",
+
+	"ask.disabled":       "Las preguntas no están habilitadas.",
+	"ask.failed":         "No pude responder la pregunta, inténtalo de nuevo.",
+	"ask.not_understood": "Lo siento, no entendí esa pregunta. Prueba algo como \"¿cuánto gasté en café el mes pasado?\"",
+	"ask.usage":          "Pregunta sobre tus gastos después de /ask, p. ej. `/ask ¿cuánto gasté en café en septiembre?`",
+
+	"category.Coffee":        "Café",
+	"category.Entertainment": "Entretenimiento",
+	"category.Food":          "Comida",
+	"category.General":       "General",
+	"category.Groceries":     "Mercado",
+	"category.Health":        "Salud",
+	"category.Housing":       "Vivienda",
+	"category.Income":        "Ingreso",
+	"category.Refund":        "Reembolso",
+	"category.Salary":        "Salario",
+	"category.Shopping":      "Compras",
+	"category.Transport":     "Transporte",
+	"category.Travel":        "Viajes",
+	"category.Uncategorized": "Sin categoría",
+	"category.Utilities":     "Servicios",
+
+	"chart.daily":         "Totales diarios",
+	"chart.empty":         "No hay gastos registrados para %s.",
+	"chart.invalid":       "Gráfico no válido: %s.\n%s",
+	"chart.load_failed":   "No pude cargar los gastos: %v",
+	"chart.other":         "Otros",
+	"chart.render_failed": "No pude dibujar el gráfico.",
+	"chart.send_failed":   "No pude enviar el gráfico: %v",
+	"chart.title":         "Gastos del %s al %s",
+	"chart.usage":         "Uso: /chart [week|month|year|all|<N>d|AAAA-MM], p. ej. `/chart month`.",
+	"chart.week_label":    "%s %02d",
+	"chart.weekly":        "Totales semanales",
+
+	"command.unknown": "Comando desconocido: /%s",
+
+	"common.and_more":    "…y %d más\n",
+	"common.list_amount": "- %s: %s",
+	"common.sorry":       "Lo siento, %s.\n%s",
+
+	"count.attempts.one":   "%d intento",
+	"count.attempts.other": "%d intentos",
+	"count.calls.one":      "%d llamada",
+	"count.calls.other":    "%d llamadas",
+	"count.expenses.one":   "%d gasto",
+	"count.expenses.other": "%d gastos",
+	"count.hours.one":      "%d hora",
+	"count.hours.other":    "%d horas",
+	"count.minutes.one":    "%d minuto",
+	"count.minutes.other":  "%d minutos",
+
+	"dashboard.disabled":     "El panel no está habilitado.",
+	"dashboard.failed":       "No pude crear un enlace al panel. Inténtalo de nuevo.",
+	"dashboard.link":         "Abre tu panel (un solo uso, válido por %s):\n%s",
+	"dashboard.private_only": "Envíame /dashboard en un chat privado para recibir un enlace de acceso.",
+
+	"duplicate.discard_button":  "Descartar",
+	"duplicate.discarded":       "Descarté el duplicado.",
+	"duplicate.discarded_short": "Descartado",
+	"duplicate.expired":         "Esta confirmación venció. Vuelve a enviar el gasto si todavía lo quieres.",
+	"duplicate.expired_short":   "Vencido",
+	"duplicate.failed_short":    "Error",
+	"duplicate.prompt":          "Parece un duplicado de #%d, registrado %s. ¿Guardarlo de todos modos?\n%s: %s (%s)",
+	"duplicate.save_button":     "Guardar igual",
+	"duplicate.saved_short":     "Guardado",
+
+	"expense.low_confidence":  "\n(Leído sin conexión con reglas básicas; revísalo por favor.)",
+	"expense.not_found":       "No encontré el gasto #%d.",
+	"expense.recorded":        "Registrado",
+	"expense.recorded_id":     "%s\nID: #%d",
+	"expense.recorded_income": "Ingreso registrado",
+	"expense.reply":           "%s\nDescripción: %s\nCategoría: %s\nMonto: %s",
+	"expense.reply_account":   "\nCuenta: %s",
+	"expense.reply_date":      "\nFecha: %s",
+	"expense.reply_tags":      "\nEtiquetas: %s",
+	"expense.store_failed":    "No pude guardar el gasto: %v",
+
+	"export.caption": "Gastos (%s)",
+	"export.failed":  "No pude exportar los gastos: %v",
+	"export.invalid": "Exportación no válida: %s.\n%s",
+	"export.usage":   "Uso: /export [week|month|year|all|<N>d|AAAA-MM] [csv|json], p. ej. `/export month json`.",
+
+	"extract.budget_exhausted": "Se agotó el presupuesto mensual de IA, así que hasta el próximo mes solo funcionan mensajes simples como \"Café $3,50\".",
+	"extract.circuit_open":     "El lector de gastos no está disponible temporalmente. Inténtalo de nuevo en unos minutos.",
+	"extract.invalid_response": "No entendí ese gasto. Intenta escribirlo de otra forma, p. ej. \"Almuerzo $12,50\".",
+	"extract.no_amount":        "No encontré un monto en ese mensaje. Prueba algo como \"Café $3,50\".",
+	"extract.rate_limited":     "El lector de gastos está ocupado en este momento. Inténtalo de nuevo en un rato.",
+	"extract.server_error":     "El lector de gastos tiene problemas en este momento. Inténtalo más tarde.",
+	"extract.timeout":          "El lector de gastos tardó demasiado en responder. Inténtalo de nuevo.",
+	"extract.too_long":         "Ese mensaje es demasiado largo para leerlo como un gasto. Mantenlo por debajo de %d caracteres.",
+	"extract.unauthorized":     "El lector de gastos está mal configurado. Avísale al administrador del bot.",
+	"extract.unknown":          "Algo salió mal al leer ese gasto. Inténtalo de nuevo.",
+
+	"goals.add_failed":         "No pude agregar la meta, inténtalo de nuevo.",
+	"goals.added":              "Agregué la meta %q: ahorrar %s",
+	"goals.added_by":           " para el %s",
+	"goals.added_hint":         ". Registra tus ahorros con /goal save %q <monto>.",
+	"goals.contribute_failed":  "No pude registrar el aporte, inténtalo de nuevo.",
+	"goals.delete_failed":      "No pude eliminar la meta, inténtalo de nuevo.",
+	"goals.deleted":            "Eliminé la meta %q.",
+	"goals.disabled":           "Las metas de ahorro no están habilitadas.",
+	"goals.due":                ", vence el %s",
+	"goals.err.amount":         "%q no es un monto",
+	"goals.err.date":           "%q no es una fecha como 2027-04 o 2027-04-15",
+	"goals.err.name_length":    "los nombres de meta deben tener de 1 a %d caracteres",
+	"goals.err.past":           "la fecha límite ya pasó",
+	"goals.err.quotes":         "pon entre comillas los nombres de meta de varias palabras",
+	"goals.err.target":         "%q no es un monto objetivo",
+	"goals.err.unclosed_quote": "falta cerrar unas comillas",
+	"goals.exists":             "Ya tienes una meta llamada %q.",
+	"goals.list_heading":       "Tus metas:\n",
+	"goals.load_failed":        "No pude cargar tus metas.",
+	"goals.logged":             "Registré %s para %q.",
+	"goals.logged_progress":    "Registré %s para %s.\n%s",
+	"goals.no_savings":         "\n  Todavía no hay ahorros registrados.",
+	"goals.none":               "No tienes metas de ahorro. Agrega una con /goal add \"<nombre>\" <objetivo> [hasta AAAA-MM].",
+	"goals.not_found":          "No tienes ninguna meta llamada %q.",
+	"goals.pace":               "\n  A este ritmo, para el %s",
+	"goals.pace_behind":        "\n  A este ritmo, para el %s (atrasada)",
+	"goals.pace_on_track":      "\n  A este ritmo, para el %s (a tiempo)",
+	"goals.progress":           "- %s: %s de %s (%.0f%%)",
+	"goals.reached":            "\n  ¡Lograda!",
+	"goals.section_heading":    "Metas:\n",
+	"goals.section_item":       "- %s: %.0f%% (%s de %s)\n",
+	"goals.usage":              "Uso: /goals | /goal add \"<nombre>\" <objetivo> [hasta AAAA-MM] | /goal save \"<nombre>\" <monto> | /goal delete \"<nombre>\", p. ej. `/goal add \"Viaje a Japón\" 3000 hasta 2027-04`",
+
+	"import.disabled":           "La importación de extractos no está habilitada.",
+	"import.download_failed":    "No pude descargar ese archivo. Inténtalo de nuevo.",
+	"import.duplicates.one":     "%d ya registrado",
+	"import.duplicates.other":   "%d ya registrados",
+	"import.imported.one":       "Importé %d gasto de %s",
+	"import.imported.other":     "Importé %d gastos de %s",
+	"import.skipped.one":        "omití %d pago entrante",
+	"import.skipped.other":      "omití %d pagos entrantes",
+	"import.stopped.one":        "La importación se detuvo después de %d gasto: %v",
+	"import.stopped.other":      "La importación se detuvo después de %d gastos: %v",
+	"import.too_large":          "Ese archivo es demasiado grande para importarlo (límite de %d MB).",
+	"import.unreadable":         "No pude leer %s como un extracto bancario: %v",
+	"import.would_import.one":   "Importaría %d gasto de %s",
+	"import.would_import.other": "Importaría %d gastos de %s",
+
+	"language.en": "inglés",
+	"language.es": "español",
+
+	"month.April":     "abril",
+	"month.August":    "agosto",
+	"month.December":  "diciembre",
+	"month.February":  "febrero",
+	"month.January":   "enero",
+	"month.July":      "julio",
+	"month.June":      "junio",
+	"month.March":     "marzo",
+	"month.May":       "mayo",
+	"month.November":  "noviembre",
+	"month.October":   "octubre",
+	"month.September": "septiembre",
+
+	"pending.disabled":        "La cola de pendientes no está habilitada.",
+	"pending.discard_failed":  "No pude descartar el pendiente #%d: %v",
+	"pending.discarded.one":   "Descarté %d gasto pendiente.",
+	"pending.discarded.other": "Descarté %d gastos pendientes.",
+	"pending.gave_up":         "Todavía no pude registrar el pendiente #%d (%q) después de %d intentos: %s\nSeguirá en /pending hasta que lo descartes o vuelvas a enviar el gasto.",
+	"pending.list_footer":     "Usa /pending discard <id> o /pending discard all.",
+	"pending.list_heading":    "Gastos pendientes:\n",
+	"pending.list_item":       "#%d %q: %s, %s\n  %s\n",
+	"pending.load_failed":     "No pude cargar los gastos pendientes: %v",
+	"pending.next_retry":      "próximo intento %s",
+	"pending.no_match":        "Ningún gasto pendiente coincide con %q.",
+	"pending.none":            "No hay gastos pendientes.",
+	"pending.queued":          "%s\nGuardé tu mensaje como pendiente #%d y lo volveré a intentar automáticamente. Usa /pending para revisarlo.",
+	"pending.recorded":        "El pendiente #%d (%q) ya está registrado.\n%s",
+	"pending.store_failed":    "No pude guardar ese gasto en este momento.",
+	"pending.stuck":           "atascado, sin más intentos",
+	"pending.usage":           "Uso: /pending, /pending discard <id> o /pending discard all",
+
+	"period.before":         "antes del %s",
+	"period.day":            "el %s",
+	"period.err.days":       "el periodo %q debe cubrir al menos un día",
+	"period.err.month":      "mes desconocido %q",
+	"period.err.unexpected": "argumento inesperado %q",
+	"period.err.unknown":    "periodo desconocido %q",
+	"period.month":          "en %s de %d",
+	"period.overall":        "en total",
+	"period.range":          "del %s al %s",
+	"period.since":          "desde el %s",
+	"period.year":           "en %d",
+
+	"query.all_categories": "en todas las categorías",
+	"query.average":        "En promedio gastaste %s por gasto %s %s (%s).",
+	"query.count":          "%s %s %s.",
+	"query.list":           "%s %s %s, %s en total",
+	"query.list_latest":    "; los últimos %d",
+	"query.matching":       "que coinciden con %q",
+	"query.max":            "Tu gasto más grande %s %s fue de %s en %s el %s (#%d).",
+	"query.min":            "Tu gasto más pequeño %s %s fue de %s en %s el %s (#%d).",
+	"query.none":           "No hay gastos %s %s.",
+	"query.on":             "en %s",
+	"query.on_matching":    "en %s que coinciden con %q",
+	"query.sum":            "Gastaste %s %s %s (%s).",
+	"query.tagged":         " con la etiqueta #%s",
+
+	"settings.comma":        "coma",
+	"settings.disabled":     "La configuración no está habilitada.",
+	"settings.err.currency": "los símbolos de moneda pueden tener como máximo %d caracteres",
+	"settings.err.decimal":  "el separador decimal es coma o punto, no %q",
+	"settings.err.language": "%q no es un idioma que hable; elige en o es",
+	"settings.err.timezone": "%q no es una zona horaria como America/Bogota o Europe/Madrid",
+	"settings.err.unknown":  "no existe la configuración %q",
+	"settings.err.week":     "%q no es un día de la semana",
+	"settings.point":        "punto",
+	"settings.save_failed":  "No pude guardar tu configuración, inténtalo de nuevo.",
+	"settings.saved":        "Guardado.\n",
+	"settings.summary":      "Tu configuración:\n- Idioma: %s\n- Zona horaria: %s (ahora %s)\n- Moneda: %s\n- Separador decimal: %s (%s)\n- Las semanas empiezan el %s",
+	"settings.usage":        "Uso: /settings | /settings idioma en|es | /settings zona <Área/Ciudad> | /settings moneda <símbolo> | /settings decimales coma|punto | /settings semana <día>, p. ej. `/settings zona America/Bogota`",
+
+	"stats.anomaly":         "\nOjo: esto es inusualmente alto para %s (sueles gastar alrededor de %s).",
+	"stats.by_category":     "Por categoría:\n",
+	"stats.by_tag":          "Por etiqueta:\n",
+	"stats.change":          "Cambio: %s %s\n",
+	"stats.empty_month":     "No hay gastos registrados este mes.",
+	"stats.empty_month_tag": "No hay gastos con la etiqueta #%s registrados este mes.",
+	"stats.empty_week":      "No hay gastos registrados esta semana.",
+	"stats.empty_week_tag":  "No hay gastos con la etiqueta #%s registrados esta semana.",
+	"stats.err.tag":         "etiqueta no válida %q",
+	"stats.heading_month":   "Este mes (desde el %s)",
+	"stats.heading_tag":     " para #%s",
+	"stats.heading_week":    "Esta semana (desde el %s)",
+	"stats.income.one":      "Ingresos: %[2]s en %[1]d entrada\n",
+	"stats.income.other":    "Ingresos: %[2]s en %[1]d entradas\n",
+	"stats.load_failed":     "No pude cargar las estadísticas: %v",
+	"stats.net":             "Neto: %s (tasa de ahorro %.0f%%)\n",
+	"stats.total.one":       "Total: %[2]s en %[1]d gasto\n",
+	"stats.total.other":     "Total: %[2]s en %[1]d gastos\n",
+	"stats.trend_gone":      "sin gastos",
+	"stats.trend_new":       "nuevo",
+	"stats.usage":           "Uso: /stats [semana|mes] [#etiqueta]",
+	"stats.vs_month":        "frente al mes pasado",
+	"stats.vs_week":         "frente a los mismos días de la semana pasada",
+
+	"tags.disabled":     "Las etiquetas no están habilitadas.",
+	"tags.err.id":       "%q no es un ID de gasto",
+	"tags.err.invalid":  "%q no es una etiqueta válida; usa letras, dígitos, '-' y '_' (hasta %d caracteres)",
+	"tags.err.missing":  "dime qué gasto y qué etiquetas",
+	"tags.err.too_many": "un gasto puede tener como máximo %d etiquetas",
+	"tags.failed":       "No pude actualizar las etiquetas, inténtalo de nuevo.",
+	"tags.none":         "El gasto #%d no tiene etiquetas.",
+	"tags.tagged":       "El gasto #%d tiene las etiquetas %s.",
+	"tags.updated":      "Actualicé las etiquetas del gasto #%d.",
+	"tags.usage":        "Uso: /tag <id> #etiqueta [#otra] [-#quitar], p. ej. `/tag 42 #vacaciones-2026 -#trabajo`",
+
+	"token.disabled":      "La API no está habilitada.",
+	"token.issue_failed":  "No pude crear un token de API. Inténtalo de nuevo.",
+	"token.issued":        "Token de API #%d (se muestra una sola vez, mantenlo en secreto):\n%s\n\nEnvíalo como \"Authorization: Bearer <token>\". Revócalo con /token revoke %d.",
+	"token.list_heading":  "Tus tokens de API:\n",
+	"token.list_item":     "#%d creado el %s\n",
+	"token.load_failed":   "No pude cargar tus tokens de API.",
+	"token.none":          "No tienes tokens de API. Crea uno con /token new.",
+	"token.not_found":     "No existe el token de API #%d.",
+	"token.private_only":  "Envíame /token en un chat privado para gestionar tus tokens de API.",
+	"token.revoke_failed": "No pude revocar el token. Inténtalo de nuevo.",
+	"token.revoked":       "Revoqué el token de API #%d.",
+	"token.revoked_all":   "Revoqué todos tus tokens de API.",
+	"token.usage":         "Uso: /token new | /token list | /token revoke <id|all>",
+
+	"usage.admins_only": "Solo los administradores del bot pueden ver el consumo.",
+	"usage.bucket":      "%s tokens en %s",
+	"usage.budget":      "Presupuesto mensual: %s de %s tokens usados (%.0f%%)",
+	"usage.by_day":      "Por día:",
+	"usage.by_user":     "Por usuario:",
+	"usage.cost":        ", ~US$%s",
+	"usage.disabled":    "El seguimiento de consumo no está habilitado.",
+	"usage.heading":     "Consumo de LLM desde el %s (%s):",
+	"usage.load_failed": "No pude cargar el consumo: %v",
+	"usage.total":       "Total: %s",
+	"usage.usage":       "Uso: /usage [días], con días entre 1 y %d.",
+	"usage.user_id":     "usuario %d",
+
+	"weekday.Friday":    "viernes",
+	"weekday.Monday":    "lunes",
+	"weekday.Saturday":  "sábado",
+	"weekday.Sunday":    "domingo",
+	"weekday.Thursday":  "jueves",
+	"weekday.Tuesday":   "martes",
+	"weekday.Wednesday": "miércoles",
+}
@@ -0,0 +1,100 @@
+package locale
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var verbPattern = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?[a-zA-Z%]`)
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	for _, lang := range Languages {
+		catalog := catalogs[lang]
+		for key, msg := range english {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing %q", lang, key)
+				continue
+			}
+			want, got := verbPattern.FindAllString(msg, -1), verbPattern.FindAllString(translated, -1)
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(want, got) {
+				t.Errorf("%s: %q has verbs %v, want %v", lang, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s: %q is not in the English catalog", lang, key)
+			}
+			if base, ok := strings.CutSuffix(key, ".one"); ok {
+				if _, ok := catalog[base+".other"]; !ok {
+					t.Errorf("%s: %q has no .other form", lang, base)
+				}
+			}
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	spanish := Settings{Language: Spanish}
+	tests := []struct {
+		settings Settings
+		n        int
+		want     string
+	}{
+		{settings: Default(), n: 1, want: "1 expense"},
+		{settings: Default(), n: 0, want: "0 expenses"},
+		{settings: spanish, n: 1, want: "1 gasto"},
+		{settings: spanish, n: 3, want: "3 gastos"},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.Plural("count.expenses", tt.n); got != tt.want {
+			t.Errorf("Plural(%d) in %s = %q, want %q", tt.n, tt.settings.Lang(), got, tt.want)
+		}
+	}
+}
+
+func TestErrorTranslatesMessages(t *testing.T) {
+	err := fmt.Errorf("parse: %w", Errorf("period.err.unknown", "fortnight"))
+	if got := err.Error(); got != `parse: unknown period "fortnight"` {
+		t.Fatalf("unexpected English error %q", got)
+	}
+	if got := (Settings{Language: Spanish}).Error(err); got != `periodo desconocido "fortnight"` {
+		t.Fatalf("unexpected Spanish error %q", got)
+	}
+	if got := (Settings{Language: Spanish}).Error(errors.New("boom")); got != "boom" {
+		t.Fatalf("expected plain errors to pass through, got %q", got)
+	}
+}
+
+func TestCategory(t *testing.T) {
+	spanish := Settings{Language: Spanish}
+	if got := spanish.Category("Groceries"); got != "Mercado" {
+		t.Fatalf("expected Mercado, got %q", got)
+	}
+	if got := spanish.Category("Pets"); got != "Pets" {
+		t.Fatalf("expected custom categories unchanged, got %q", got)
+	}
+	if got := Default().Category("Groceries"); got != "Groceries" {
+		t.Fatalf("expected English names unchanged, got %q", got)
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	for raw, want := range map[string]Language{"es": Spanish, "Español": Spanish, "english": English} {
+		if got, ok := ParseLanguage(raw); !ok || got != want {
+			t.Errorf("ParseLanguage(%q) = %q, %v", raw, got, ok)
+		}
+	}
+	if _, ok := ParseLanguage("fr"); ok {
+		t.Fatal("expected French to be rejected")
+	}
+	if got := (Settings{Language: "fr"}).Lang(); got != English {
+		t.Fatalf("expected unknown languages to fall back to English, got %q", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Package logging sets up structured JSON logs. Every line carries the request, user,
+// chat and trace it belongs to, user-written content is redacted unless explicitly
+// enabled, and configured secrets are scrubbed from everything that is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Oxyrus/financebot/internal/reqctx"
+)
+
+// Redacted replaces sensitive values and secrets in log output.
+const Redacted = "[redacted]"
+
+// Config controls what gets logged.
+type Config struct {
+	// Level is the minimum level written.
+	Level slog.Level
+	// Content logs expense descriptions and amounts instead of redacting them.
+	Content bool
+	// Secrets are scrubbed from messages and attributes wherever they appear, including
+	// inside errors such as the request URLs of failed Telegram calls.
+	Secrets []string
+}
+
+// New returns a logger writing JSON lines to w.
+func New(w io.Writer, cfg Config) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: cfg.Level}), cfg))
+}
+
+// Text marks user-written text, such as an expense description, as sensitive.
+func Text(key, value string) slog.Attr {
+	return slog.Any(key, sensitive{slog.StringValue(value)})
+}
+
+// Amount marks a money amount as sensitive.
+func Amount(key string, value float64) slog.Attr {
+	return slog.Any(key, sensitive{slog.Float64Value(value)})
+}
+
+// sensitive wraps a value that is only logged when content logging is enabled. Handlers
+// other than ours see it redacted.
+type sensitive struct {
+	value slog.Value
+}
+
+func (s sensitive) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// Handler adds request metadata from the context, redacts sensitive values and scrubs
+// secrets before passing records on.
+type Handler struct {
+	next    slog.Handler
+	content bool
+	secrets *strings.Replacer
+}
+
+// NewHandler wraps next with the metadata, redaction and scrubbing described by cfg.
+func NewHandler(next slog.Handler, cfg Config) *Handler {
+	var pairs []string
+	for _, secret := range cfg.Secrets {
+		if secret == "" {
+			continue
+		}
+		// Secrets also end up escaped inside URLs.
+		for _, form := range []string{secret, url.PathEscape(secret), url.QueryEscape(secret)} {
+			pairs = append(pairs, form, Redacted)
+		}
+	}
+	return &Handler{next: next, content: cfg.Content, secrets: strings.NewReplacer(pairs...)}
+}
+
+// Enabled reports whether the wrapped handler writes records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle cleans the record and passes it on with the context's request metadata.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.secrets.Replace(r.Message), r.PC)
+	if request, ok := reqctx.RequestFrom(ctx); ok {
+		out.AddAttrs(slog.String("request_id", request.ID))
+		if request.ChatID != 0 {
+			out.AddAttrs(slog.Int64("chat_id", request.ChatID))
+		}
+	}
+	if user, ok := reqctx.UserFrom(ctx); ok {
+		out.AddAttrs(slog.Int64("user_id", user.ID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		out.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.clean(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs cleans attrs once, up front.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	cleaned := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		cleaned[i] = h.clean(a)
+	}
+	return &Handler{next: h.next.WithAttrs(cleaned), content: h.content, secrets: h.secrets}
+}
+
+// WithGroup nests later attributes under name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), content: h.content, secrets: h.secrets}
+}
+
+// clean redacts or unwraps sensitive values and scrubs secrets from a. Values the JSON
+// handler would marshal as they are, such as errors and structs, are written as text so
+// that no field escapes scrubbing.
+func (h *Handler) clean(a slog.Attr) slog.Attr {
+	if s, ok := a.Value.Any().(sensitive); ok && a.Value.Kind() == slog.KindLogValuer {
+		if !h.content {
+			return slog.String(a.Key, Redacted)
+		}
+		a.Value = s.value
+	}
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.secrets.Replace(a.Value.String()))
+	case slog.KindAny:
+		a.Value = slog.StringValue(h.secrets.Replace(fmt.Sprint(a.Value.Any())))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		cleaned := make([]slog.Attr, len(group))
+		for i, g := range group {
+			cleaned[i] = h.clean(g)
+		}
+		a.Value = slog.GroupValue(cleaned...)
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Oxyrus/financebot/internal/reqctx"
+)
+
+const (
+	telegramToken = "123456:ABC-telegram-secret"
+	openAIKey     = "sk-openai-secret"
+)
+
+func logLine(t *testing.T, cfg Config, log func(*slog.Logger)) (string, map[string]any) {
+	t.Helper()
+	var buf bytes.Buffer
+	log(New(&buf, cfg))
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	return buf.String(), line
+}
+
+func TestHandlerAddsRequestMetadata(t *testing.T) {
+	ctx := reqctx.WithRequest(context.Background(), reqctx.Request{ID: "abc", ChatID: 42})
+	ctx = reqctx.WithUser(ctx, reqctx.User{ID: 7, Username: "sam"})
+
+	_, line := logLine(t, Config{}, func(l *slog.Logger) { l.InfoContext(ctx, "hello") })
+	if line["request_id"] != "abc" || line["chat_id"] != float64(42) || line["user_id"] != float64(7) {
+		t.Fatalf("expected request metadata, got %v", line)
+	}
+	if _, ok := line["username"]; ok {
+		t.Fatalf("expected no username, got %v", line)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	_, line = logLine(t, Config{}, func(l *slog.Logger) { l.InfoContext(ctx, "hello") })
+	if line["trace_id"] != traceID.String() || line["span_id"] != spanID.String() {
+		t.Fatalf("expected trace metadata, got %v", line)
+	}
+}
+
+func TestHandlerRedactsContentByDefault(t *testing.T) {
+	log := func(l *slog.Logger) {
+		l.Info("expense", Text("description", "therapy session"), Amount("amount", 120))
+	}
+
+	raw, line := logLine(t, Config{}, log)
+	if strings.Contains(raw, "therapy") || strings.Contains(raw, "120") {
+		t.Fatalf("expected content to be redacted, got %s", raw)
+	}
+	if line["description"] != Redacted || line["amount"] != Redacted {
+		t.Fatalf("expected redaction markers, got %v", line)
+	}
+
+	_, line = logLine(t, Config{Content: true}, log)
+	if line["description"] != "therapy session" || line["amount"] != float64(120) {
+		t.Fatalf("expected content when enabled, got %v", line)
+	}
+}
+
+func TestHandlerScrubsSecrets(t *testing.T) {
+	cfg := Config{Secrets: []string{telegramToken, openAIKey, ""}}
+	err := errors.New(`Post "https://api.telegram.org/bot123456%3AABC-telegram-secret/getUpdates": timeout`)
+
+	raw, _ := logLine(t, cfg, func(l *slog.Logger) {
+		l.With("token", telegramToken).WithGroup("openai").Error("call failed with "+openAIKey,
+			"err", err,
+			slog.Group("config", "key", openAIKey),
+			"struct", struct{ Token string }{telegramToken},
+		)
+	})
+	for _, secret := range []string{telegramToken, openAIKey, "ABC-telegram-secret"} {
+		if strings.Contains(raw, secret) {
+			t.Fatalf("expected %q to be scrubbed, got %s", secret, raw)
+		}
+	}
+	if !strings.Contains(raw, "/getUpdates") {
+		t.Fatalf("expected the rest of the error to survive, got %s", raw)
+	}
+}
+
+func TestHandlerRespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelWarn})
+	logger.Info("quiet")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be dropped, got %q", buf.String())
+	}
+}
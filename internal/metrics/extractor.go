@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+)
+
+// Extractor counts extractions by outcome and times them.
+type Extractor struct {
+	next    extractor.Service
+	metrics *Metrics
+}
+
+// NewExtractor instruments next.
+func NewExtractor(next extractor.Service, m *Metrics) *Extractor {
+	return &Extractor{next: next, metrics: m}
+}
+
+// Extract delegates to the wrapped service.
+func (e *Extractor) Extract(ctx context.Context, text string) (expense.Item, error) {
+	start := time.Now()
+	item, err := e.next.Extract(ctx, text)
+	e.metrics.extractDuration.Observe(time.Since(start).Seconds())
+
+	outcome := "success"
+	if err != nil {
+		outcome = extractor.Reason(err)
+	}
+	e.metrics.extractions.WithLabelValues(outcome).Inc()
+	return item, err
+}
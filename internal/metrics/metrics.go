@@ -0,0 +1,97 @@
+// Package metrics exposes Prometheus metrics for the bot. The instrumentation lives in
+// decorators around the extractor, the expense store and the Telegram API, so the code
+// they wrap stays unaware of it.
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Oxyrus/financebot/internal/httpserve"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "financebot"
+
+// Metrics holds the collectors shared by the decorators.
+type Metrics struct {
+	registry         *prometheus.Registry
+	updates          *prometheus.CounterVec
+	commands         *prometheus.CounterVec
+	extractions      *prometheus.CounterVec
+	extractDuration  prometheus.Histogram
+	storeErrors      *prometheus.CounterVec
+	storeDuration    *prometheus.HistogramVec
+	telegramFailures *prometheus.CounterVec
+}
+
+// New registers the bot's metrics, along with the Go runtime and process collectors, on
+// a fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		updates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "updates_received_total",
+			Help:      "Telegram updates received, by type.",
+		}, []string{"type"}),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "commands_total",
+			Help:      "Bot commands received, by command.",
+		}, []string{"command"}),
+		extractions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "extractions_total",
+			Help:      "Expense extractions, by outcome: success or the failure reason.",
+		}, []string{"outcome"}),
+		extractDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "extractor_duration_seconds",
+			Help:      "Time taken to extract an expense, including retries and fallbacks.",
+			Buckets:   []float64{.005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}),
+		storeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "store_errors_total",
+			Help:      "Expense store operations that failed, by operation.",
+		}, []string{"operation"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_duration_seconds",
+			Help:      "Time taken by expense store operations, by operation.",
+			Buckets:   []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation"}),
+		telegramFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "telegram_send_failures_total",
+			Help:      "Telegram requests that failed, by kind, such as Message or Document.",
+		}, []string{"kind"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.updates,
+		m.commands,
+		m.extractions,
+		m.extractDuration,
+		m.storeErrors,
+		m.storeDuration,
+		m.telegramFailures,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Serve serves /metrics on addr until ctx is canceled, then shuts down gracefully.
+func Serve(ctx context.Context, addr string, m *Metrics) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	return httpserve.Serve(ctx, "metrics", addr, mux)
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+type stubExtractor struct {
+	err error
+}
+
+func (s stubExtractor) Extract(context.Context, string) (expense.Item, error) {
+	if s.err != nil {
+		return expense.Item{}, s.err
+	}
+	return expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}, nil
+}
+
+func TestExtractorCountsOutcomes(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+	_, _ = NewExtractor(stubExtractor{}, m).Extract(ctx, "latte 3.50")
+	_, _ = NewExtractor(stubExtractor{err: extractor.ErrNoAmount}, m).Extract(ctx, "latte")
+	_, _ = NewExtractor(stubExtractor{err: context.DeadlineExceeded}, m).Extract(ctx, "latte 3.50")
+
+	for outcome, want := range map[string]float64{"success": 1, "no_amount": 1, "timeout": 1} {
+		if got := testutil.ToFloat64(m.extractions.WithLabelValues(outcome)); got != want {
+			t.Errorf("extractions{outcome=%q} = %v, want %v", outcome, got, want)
+		}
+	}
+	if got := testutil.CollectAndCount(m.extractDuration); got != 1 {
+		t.Fatalf("expected one duration histogram, got %d", got)
+	}
+}
+
+func TestExpenseStoreCountsFailures(t *testing.T) {
+	m := New()
+	store := NewExpenseStore(memory.NewStore(), m)
+	ctx := context.Background()
+
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: 3.5}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	if _, err := store.GetExpense(ctx, 7, 999); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_ = store.ExportExpenses(ctx, storage.ExportFilter{UserID: 7}, func(storage.Expense) error {
+		return errors.New("write failed")
+	})
+
+	if got := testutil.ToFloat64(m.storeErrors.WithLabelValues("get_expense")); got != 0 {
+		t.Fatalf("expected a missing expense not to count as an error, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.storeErrors.WithLabelValues("export_expenses")); got != 1 {
+		t.Fatalf("expected the failed export to be counted, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.storeDuration); got != 3 {
+		t.Fatalf("expected durations for three operations, got %d", got)
+	}
+}
+
+type stubTelegram struct {
+	updates chan tgbotapi.Update
+	sendErr error
+}
+
+func (s *stubTelegram) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return s.updates
+}
+
+func (s *stubTelegram) StopReceivingUpdates() {}
+
+func (s *stubTelegram) Send(tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return tgbotapi.Message{}, s.sendErr
+}
+
+func (s *stubTelegram) Request(tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (s *stubTelegram) GetFileDirectURL(string) (string, error) {
+	return "", nil
+}
+
+func command(text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: strings.IndexByte(text+" ", ' ')}},
+	}}
+}
+
+func TestTelegramCountsUpdatesAndFailures(t *testing.T) {
+	m := New()
+	stub := &stubTelegram{updates: make(chan tgbotapi.Update, 4), sendErr: errors.New("blocked")}
+	label := func(command string) string {
+		if command == "stats" {
+			return command
+		}
+		return "unknown"
+	}
+	api := NewTelegram(stub, m, label)
+
+	stub.updates <- command("/stats month")
+	stub.updates <- command("/bogus")
+	stub.updates <- tgbotapi.Update{Message: &tgbotapi.Message{Text: "latte 3.50"}}
+	stub.updates <- tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{}}
+	close(stub.updates)
+	for range api.GetUpdatesChan(tgbotapi.UpdateConfig{}) {
+	}
+
+	for label, want := range map[string]float64{"command": 2, "message": 1, "callback_query": 1} {
+		if got := testutil.ToFloat64(m.updates.WithLabelValues(label)); got != want {
+			t.Errorf("updates{type=%q} = %v, want %v", label, got, want)
+		}
+	}
+	for label, want := range map[string]float64{"stats": 1, "unknown": 1} {
+		if got := testutil.ToFloat64(m.commands.WithLabelValues(label)); got != want {
+			t.Errorf("commands{command=%q} = %v, want %v", label, got, want)
+		}
+	}
+
+	if _, err := api.Send(tgbotapi.NewMessage(1, "hi")); err == nil {
+		t.Fatal("expected the send error to pass through")
+	}
+	if got := testutil.ToFloat64(m.telegramFailures.WithLabelValues("Message")); got != 1 {
+		t.Fatalf("expected one failed Message, got %v", got)
+	}
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	m := New()
+	m.updates.WithLabelValues("message").Inc()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{`financebot_updates_received_total{type="message"} 1`, "go_goroutines"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in metrics output:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// ExpenseStore times expense store operations and counts their failures. A missing
+// expense is an answer rather than a failure and is not counted.
+type ExpenseStore struct {
+	next    storage.ExpenseStore
+	metrics *Metrics
+}
+
+// NewExpenseStore instruments next.
+func NewExpenseStore(next storage.ExpenseStore, m *Metrics) *ExpenseStore {
+	return &ExpenseStore{next: next, metrics: m}
+}
+
+func (s *ExpenseStore) observe(operation string, start time.Time, err error) {
+	s.metrics.storeDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.metrics.storeErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// SaveExpense delegates to the wrapped store.
+func (s *ExpenseStore) SaveExpense(ctx context.Context, userID int64, item expense.Item) (int64, error) {
+	start := time.Now()
+	id, err := s.next.SaveExpense(ctx, userID, item)
+	s.observe("save_expense", start, err)
+	return id, err
+}
+
+// Close delegates to the wrapped store.
+func (s *ExpenseStore) Close() error {
+	return s.next.Close()
+}
+
+// Stats delegates to the wrapped store.
+func (s *ExpenseStore) Stats(ctx context.Context, filter storage.ExportFilter) (storage.Summary, error) {
+	start := time.Now()
+	summary, err := s.next.Stats(ctx, filter)
+	s.observe("stats", start, err)
+	return summary, err
+}
+
+// RecentExpenses delegates to the wrapped store.
+func (s *ExpenseStore) RecentExpenses(ctx context.Context, userID int64, since time.Time) ([]storage.Expense, error) {
+	start := time.Now()
+	expenses, err := s.next.RecentExpenses(ctx, userID, since)
+	s.observe("recent_expenses", start, err)
+	return expenses, err
+}
+
+// ExportExpenses delegates to the wrapped store. The time includes fn's.
+func (s *ExpenseStore) ExportExpenses(ctx context.Context, filter storage.ExportFilter, fn func(storage.Expense) error) error {
+	start := time.Now()
+	err := s.next.ExportExpenses(ctx, filter, fn)
+	s.observe("export_expenses", start, err)
+	return err
+}
+
+// ListExpenses delegates to the wrapped store.
+func (s *ExpenseStore) ListExpenses(ctx context.Context, filter storage.ExportFilter, limit, offset int) ([]storage.Expense, error) {
+	start := time.Now()
+	expenses, err := s.next.ListExpenses(ctx, filter, limit, offset)
+	s.observe("list_expenses", start, err)
+	return expenses, err
+}
+
+// GetExpense delegates to the wrapped store.
+func (s *ExpenseStore) GetExpense(ctx context.Context, userID, id int64) (storage.Expense, error) {
+	start := time.Now()
+	e, err := s.next.GetExpense(ctx, userID, id)
+	s.observe("get_expense", start, err)
+	return e, err
+}
+
+// UpdateExpense delegates to the wrapped store.
+func (s *ExpenseStore) UpdateExpense(ctx context.Context, userID, id int64, item expense.Item) error {
+	start := time.Now()
+	err := s.next.UpdateExpense(ctx, userID, id, item)
+	s.observe("update_expense", start, err)
+	return err
+}
+
+// DeleteExpense delegates to the wrapped store.
+func (s *ExpenseStore) DeleteExpense(ctx context.Context, userID, id int64) error {
+	start := time.Now()
+	err := s.next.DeleteExpense(ctx, userID, id)
+	s.observe("delete_expense", start, err)
+	return err
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TelegramAPI is the part of the Telegram client the bot uses; it matches
+// bot.TelegramAPI.
+type TelegramAPI interface {
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// Telegram counts incoming updates and commands and failed outgoing requests.
+type Telegram struct {
+	next    TelegramAPI
+	metrics *Metrics
+	label   func(command string) string
+}
+
+// NewTelegram instruments next. Commands are counted under label(command), which should
+// map the ones the bot does not know to a single value, so users cannot create a time
+// series per typo.
+func NewTelegram(next TelegramAPI, m *Metrics, label func(command string) string) *Telegram {
+	return &Telegram{next: next, metrics: m, label: label}
+}
+
+// GetUpdatesChan relays the wrapped client's updates, counting each one.
+func (t *Telegram) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	in := t.next.GetUpdatesChan(config)
+	out := make(chan tgbotapi.Update, cap(in))
+	go func() {
+		defer close(out)
+		for update := range in {
+			t.observe(update)
+			out <- update
+		}
+	}()
+	return out
+}
+
+func (t *Telegram) observe(update tgbotapi.Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		t.metrics.updates.WithLabelValues("callback_query").Inc()
+	case update.Message == nil:
+		t.metrics.updates.WithLabelValues("other").Inc()
+	case update.Message.IsCommand():
+		t.metrics.updates.WithLabelValues("command").Inc()
+		t.metrics.commands.WithLabelValues(t.label(update.Message.Command())).Inc()
+	case update.Message.Document != nil:
+		t.metrics.updates.WithLabelValues("document").Inc()
+	default:
+		t.metrics.updates.WithLabelValues("message").Inc()
+	}
+}
+
+// StopReceivingUpdates delegates to the wrapped client.
+func (t *Telegram) StopReceivingUpdates() {
+	t.next.StopReceivingUpdates()
+}
+
+// Send delegates to the wrapped client.
+func (t *Telegram) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg, err := t.next.Send(c)
+	if err != nil {
+		t.metrics.telegramFailures.WithLabelValues(chattableKind(c)).Inc()
+	}
+	return msg, err
+}
+
+// Request delegates to the wrapped client.
+func (t *Telegram) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	resp, err := t.next.Request(c)
+	if err != nil {
+		t.metrics.telegramFailures.WithLabelValues(chattableKind(c)).Inc()
+	}
+	return resp, err
+}
+
+// GetFileDirectURL delegates to the wrapped client.
+func (t *Telegram) GetFileDirectURL(fileID string) (string, error) {
+	url, err := t.next.GetFileDirectURL(fileID)
+	if err != nil {
+		t.metrics.telegramFailures.WithLabelValues("File").Inc()
+	}
+	return url, err
+}
+
+// chattableKind names a request after its config type, e.g. "Message" for a
+// tgbotapi.MessageConfig.
+func chattableKind(c tgbotapi.Chattable) string {
+	name := fmt.Sprintf("%T", c)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "Config")
+}
@@ -0,0 +1,243 @@
+// Package query describes structured questions about spending and answers them through
+// the expense store's filters, so free-form questions never turn into hand-written SQL.
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// Aggregation is what a question asks for over the matching expenses.
+type Aggregation string
+
+const (
+	Sum     Aggregation = "sum"
+	Count   Aggregation = "count"
+	Average Aggregation = "average"
+	Max     Aggregation = "max"
+	Min     Aggregation = "min"
+	List    Aggregation = "list"
+)
+
+const (
+	maxCategoryLength = 40
+	maxTextLength     = 100
+	// ListLimit caps how many expenses a list answer includes.
+	ListLimit = 10
+)
+
+// ErrInvalid is returned for queries that fail validation.
+var ErrInvalid = errors.New("invalid query")
+
+// Query is a validated, structured question. Zero values leave that part open.
+type Query struct {
+	// Category matches the expense category, ignoring case.
+	Category string
+	// Text matches a substring of the description, ignoring case.
+	Text string
+	// Tag matches expenses carrying the tag.
+	Tag   string
+	Since time.Time
+	// Until is exclusive.
+	Until       time.Time
+	Aggregation Aggregation
+}
+
+// Validate normalizes the query and rejects anything that is not a plain filter.
+func (q Query) Validate() (Query, error) {
+	switch q.Aggregation {
+	case "":
+		q.Aggregation = Sum
+	case Sum, Count, Average, Max, Min, List:
+	default:
+		return Query{}, fmt.Errorf("%w: unknown aggregation", ErrInvalid)
+	}
+
+	var err error
+	if q.Category, err = cleanField("category", q.Category, maxCategoryLength); err != nil {
+		return Query{}, err
+	}
+	if q.Text, err = cleanField("text", q.Text, maxTextLength); err != nil {
+		return Query{}, err
+	}
+	if q.Tag != "" {
+		tag, ok := expense.NormalizeTag(q.Tag)
+		if !ok {
+			return Query{}, fmt.Errorf("%w: invalid tag", ErrInvalid)
+		}
+		q.Tag = tag
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Until.After(q.Since) {
+		return Query{}, fmt.Errorf("%w: range ends before it starts", ErrInvalid)
+	}
+	return q, nil
+}
+
+func cleanField(name, value string, limit int) (string, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if utf8.RuneCountInString(value) > limit {
+		return "", fmt.Errorf("%w: %s longer than %d characters", ErrInvalid, name, limit)
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("%w: control character in %s", ErrInvalid, name)
+		}
+	}
+	return value, nil
+}
+
+// Filter scopes the query to one user's expenses, leaving income out.
+func (q Query) Filter(userID int64) storage.ExportFilter {
+	return storage.ExportFilter{UserID: userID, Category: q.Category, Text: q.Text, Tag: q.Tag, Since: q.Since, Until: q.Until, Kind: expense.KindExpense}
+}
+
+// Answer is the result of running a query.
+type Answer struct {
+	Query Query
+	Count int
+	Total float64
+	// Items holds the matching expense for max and min, and the newest ListLimit
+	// expenses for list.
+	Items []storage.Expense
+}
+
+// Run validates q and aggregates the user's matching expenses.
+func Run(ctx context.Context, store storage.ExpenseStore, userID int64, q Query) (Answer, error) {
+	q, err := q.Validate()
+	if err != nil {
+		return Answer{}, err
+	}
+
+	answer := Answer{Query: q}
+	err = store.ExportExpenses(ctx, q.Filter(userID), func(e storage.Expense) error {
+		answer.Count++
+		answer.Total += e.Amount
+		switch q.Aggregation {
+		case Max:
+			if len(answer.Items) == 0 || e.Amount > answer.Items[0].Amount {
+				answer.Items = []storage.Expense{e}
+			}
+		case Min:
+			if len(answer.Items) == 0 || e.Amount < answer.Items[0].Amount {
+				answer.Items = []storage.Expense{e}
+			}
+		case List:
+			// Expenses stream oldest first; keep the newest ListLimit.
+			answer.Items = append(answer.Items, e)
+			if len(answer.Items) > ListLimit {
+				answer.Items = answer.Items[1:]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Answer{}, err
+	}
+	sort.SliceStable(answer.Items, func(i, j int) bool {
+		return answer.Items[i].CreatedAt.After(answer.Items[j].CreatedAt)
+	})
+	return answer, nil
+}
+
+// String phrases the answer with the default settings.
+func (a Answer) String() string {
+	return a.Format(locale.Default())
+}
+
+// Format phrases the answer as a reply in the user's language, currency and time zone,
+// e.g. "You spent $23.50 on Coffee in September 2026 (7 expenses)."
+func (a Answer) Format(s locale.Settings) string {
+	subject := describeSubject(a.Query, s)
+	period := DescribePeriod(a.Query.Since, a.Query.Until, s)
+	if a.Count == 0 {
+		return s.T("query.none", subject, period)
+	}
+
+	expenses := s.Plural("count.expenses", a.Count)
+	switch a.Query.Aggregation {
+	case Count:
+		return s.T("query.count", expenses, subject, period)
+	case Average:
+		return s.T("query.average", s.Money(a.Total/float64(a.Count)), subject, period, expenses)
+	case Max, Min:
+		key := "query.max"
+		if a.Query.Aggregation == Min {
+			key = "query.min"
+		}
+		e := a.Items[0]
+		return s.T(key, subject, period, s.Money(e.Amount), e.Description, s.Date(e.CreatedAt), e.ID)
+	case List:
+		var builder strings.Builder
+		builder.WriteString(s.T("query.list", expenses, subject, period, s.Money(a.Total)))
+		if a.Count > len(a.Items) {
+			builder.WriteString(s.T("query.list_latest", len(a.Items)))
+		}
+		builder.WriteString(":")
+		for _, e := range a.Items {
+			builder.WriteString(fmt.Sprintf("\n- #%d %s %s: %s (%s)", e.ID, s.Date(e.CreatedAt), s.Category(e.Category), s.Money(e.Amount), e.Description))
+		}
+		return builder.String()
+	default:
+		return s.T("query.sum", s.Money(a.Total), subject, period, expenses)
+	}
+}
+
+func describeSubject(q Query, s locale.Settings) string {
+	subject := describeMatch(q, s)
+	if q.Tag != "" {
+		subject += s.T("query.tagged", q.Tag)
+	}
+	return subject
+}
+
+func describeMatch(q Query, s locale.Settings) string {
+	switch {
+	case q.Category != "" && q.Text != "":
+		return s.T("query.on_matching", s.Category(q.Category), q.Text)
+	case q.Category != "":
+		return s.T("query.on", s.Category(q.Category))
+	case q.Text != "":
+		return s.T("query.matching", q.Text)
+	default:
+		return s.T("query.all_categories")
+	}
+}
+
+// DescribePeriod phrases a date range with an exclusive end in the user's language, e.g.
+// "in September 2026", "on 2026-09-03" or "from 2026-09-01 to 2026-09-14".
+func DescribePeriod(since, until time.Time, s locale.Settings) string {
+	const day = "2006-01-02"
+	switch {
+	case since.IsZero() && until.IsZero():
+		return s.T("period.overall")
+	case until.IsZero():
+		return s.T("period.since", since.Format(day))
+	case since.IsZero():
+		return s.T("period.before", until.Format(day))
+	}
+	last := until.AddDate(0, 0, -1)
+	switch {
+	case isMidnight(since) && isMidnight(until) && since.Day() == 1 && until.Equal(since.AddDate(0, 1, 0)):
+		return s.T("period.month", s.Month(since.Month()), since.Year())
+	case isMidnight(since) && isMidnight(until) && since.YearDay() == 1 && until.Equal(since.AddDate(1, 0, 0)):
+		return s.T("period.year", since.Year())
+	case isMidnight(since) && until.Equal(since.AddDate(0, 0, 1)):
+		return s.T("period.day", since.Format(day))
+	default:
+		return s.T("period.range", since.Format(day), last.Format(day))
+	}
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
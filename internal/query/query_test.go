@@ -0,0 +1,132 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func seedStore(t *testing.T) *memory.Store {
+	t.Helper()
+	store := memory.NewStore()
+	seed := []struct {
+		user        int64
+		category    string
+		amount      float64
+		description string
+		day         time.Time
+	}{
+		{7, "Coffee", 3.5, "Latte", time.Date(2026, 9, 2, 9, 0, 0, 0, time.UTC)},
+		{7, "Coffee", 4, "Flat white", time.Date(2026, 9, 15, 9, 0, 0, 0, time.UTC)},
+		{7, "Food", 12, "Lunch with coffee", time.Date(2026, 9, 20, 13, 0, 0, 0, time.UTC)},
+		{7, "Coffee", 5, "Latte", time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{8, "Coffee", 100, "Someone else's", time.Date(2026, 9, 10, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, s := range seed {
+		if _, err := store.SaveExpense(context.Background(), s.user, expense.Item{Category: s.category, Amount: s.amount, Description: s.description, Date: s.day}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+var september = Query{Since: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+
+func TestRunAggregations(t *testing.T) {
+	store := seedStore(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"sum by category", Query{Category: "coffee", Since: september.Since, Until: september.Until}, "You spent $7.50 on coffee in September 2026 (2 expenses)."},
+		{"count by text", Query{Text: "COFFEE", Aggregation: Count}, `1 expense matching "COFFEE" overall.`},
+		{"average", Query{Category: "Coffee", Aggregation: Average}, "On average you spent $4.17 per expense on Coffee overall (3 expenses)."},
+		{"max", Query{Aggregation: Max, Since: september.Since, Until: september.Until}, "Your biggest expense in all categories in September 2026 was $12.00 for Lunch with coffee on 2026-09-20 (#3)."},
+		{"min", Query{Category: "Coffee", Aggregation: Min}, "Your smallest expense on Coffee overall was $3.50 for Latte on 2026-09-02 (#1)."},
+		{"nothing", Query{Category: "Travel"}, "No expenses on Travel overall."},
+	}
+	for _, tc := range cases {
+		answer, err := Run(ctx, store, 7, tc.query)
+		if err != nil {
+			t.Fatalf("%s: Run error: %v", tc.name, err)
+		}
+		if got := answer.String(); got != tc.want {
+			t.Fatalf("%s:\n got %q\nwant %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRunListsNewestFirst(t *testing.T) {
+	store := seedStore(t)
+	answer, err := Run(context.Background(), store, 7, Query{Text: "latte", Aggregation: List})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := answer.String()
+	if !strings.HasPrefix(got, `2 expenses matching "latte" overall, $8.50 in total:`) {
+		t.Fatalf("unexpected list header %q", got)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "2026-10-01") || !strings.Contains(lines[2], "2026-09-02") {
+		t.Fatalf("expected newest first, got %q", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	q, err := Query{Category: "  Eating   out ", Text: "\tpizza ", Tag: "#Work"}.Validate()
+	if err != nil || q.Category != "Eating out" || q.Text != "pizza" || q.Tag != "work" || q.Aggregation != Sum {
+		t.Fatalf("unexpected normalized query %#v (%v)", q, err)
+	}
+
+	invalid := []Query{
+		{Aggregation: "DROP TABLE expenses"},
+		{Category: strings.Repeat("x", 41)},
+		{Text: "a\x00b"},
+		{Tag: "no spaces"},
+		{Since: september.Until, Until: september.Since},
+	}
+	for _, q := range invalid {
+		if _, err := q.Validate(); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("expected %#v to be rejected, got %v", q, err)
+		}
+	}
+	if _, err := Run(context.Background(), memory.NewStore(), 7, invalid[0]); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected Run to validate, got %v", err)
+	}
+}
+
+func TestDescribePeriod(t *testing.T) {
+	d := func(y int, m time.Month, day int) time.Time { return time.Date(y, m, day, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		since, until time.Time
+		want         string
+	}{
+		{d(2026, 9, 1), d(2026, 10, 1), "in September 2026"},
+		{d(2026, 1, 1), d(2027, 1, 1), "in 2026"},
+		{d(2026, 9, 3), d(2026, 9, 4), "on 2026-09-03"},
+		{d(2026, 9, 1), d(2026, 9, 15), "from 2026-09-01 to 2026-09-14"},
+		{d(2026, 9, 1), time.Time{}, "since 2026-09-01"},
+		{time.Time{}, d(2026, 9, 1), "before 2026-09-01"},
+		{time.Time{}, time.Time{}, "overall"},
+	}
+	for _, tc := range cases {
+		if got := DescribePeriod(tc.since, tc.until, locale.Default()); got != tc.want {
+			t.Fatalf("DescribePeriod(%v, %v) = %q, want %q", tc.since, tc.until, got, tc.want)
+		}
+	}
+
+	spanish := locale.Default()
+	spanish.Language = locale.Spanish
+	if got, want := DescribePeriod(d(2026, 9, 1), d(2026, 10, 1), spanish), "en septiembre de 2026"; got != want {
+		t.Fatalf("DescribePeriod in Spanish = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+// Package reqctx carries per-update request metadata through context.Context so that
+// decorators deep in the call chain (usage accounting, logging) know who they act for.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+)
+
+type userKey struct{}
+
+// User identifies the Telegram user an operation is performed for.
+type User struct {
+	ID       int64
+	Username string
+}
+
+// WithUser returns a copy of ctx carrying the provided user.
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user stored in ctx, if any.
+func UserFrom(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
+
+type requestKey struct{}
+
+// Request identifies the update or job being handled, so that its log lines can be told
+// apart from those of other requests.
+type Request struct {
+	ID     string
+	ChatID int64
+}
+
+// NewRequestID returns a random identifier for a new request.
+func NewRequestID() string {
+	return rand.Text()[:12]
+}
+
+// WithRequest returns a copy of ctx carrying the provided request.
+func WithRequest(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, request)
+}
+
+// RequestFrom returns the request stored in ctx, if any.
+func RequestFrom(ctx context.Context) (Request, bool) {
+	request, ok := ctx.Value(requestKey{}).(Request)
+	return request, ok
+}
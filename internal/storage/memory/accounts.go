@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.AccountStore = (*Store)(nil)
+
+// CreateAccount adds an account; the user's first one becomes their default.
+func (s *Store) CreateAccount(_ context.Context, account storage.Account) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account.Default = true
+	for _, a := range s.accounts {
+		if a.UserID != account.UserID {
+			continue
+		}
+		if strings.EqualFold(a.Name, account.Name) {
+			return 0, storage.ErrExists
+		}
+		account.Default = false
+	}
+	s.accountSeq++
+	account.ID = s.accountSeq
+	s.accounts = append(s.accounts, account)
+	return account.ID, nil
+}
+
+// ListAccounts returns a user's accounts ordered by name.
+func (s *Store) ListAccounts(_ context.Context, userID int64) ([]storage.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var accounts []storage.Account
+	for _, a := range s.accounts {
+		if a.UserID == userID {
+			accounts = append(accounts, a)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return strings.ToLower(accounts[i].Name) < strings.ToLower(accounts[j].Name)
+	})
+	return accounts, nil
+}
+
+// SetDefaultAccount makes the named account the user's default.
+func (s *Store) SetDefaultAccount(_ context.Context, userID int64, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for _, a := range s.accounts {
+		if a.UserID == userID && strings.EqualFold(a.Name, name) {
+			found = true
+		}
+	}
+	if !found {
+		return storage.ErrNotFound
+	}
+	for i, a := range s.accounts {
+		if a.UserID == userID {
+			s.accounts[i].Default = strings.EqualFold(a.Name, name)
+		}
+	}
+	return nil
+}
+
+// AccountBalances returns a user's accounts with their balances and the spending since
+// the given time.
+func (s *Store) AccountBalances(ctx context.Context, userID int64, since time.Time) ([]storage.AccountBalance, error) {
+	accounts, err := s.ListAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balances := make([]storage.AccountBalance, len(accounts))
+	for i, a := range accounts {
+		balances[i] = storage.AccountBalance{Account: a, Balance: a.OpeningBalance}
+		for _, rec := range s.records {
+			if rec.userID != userID || rec.item.Account != a.Name {
+				continue
+			}
+			if rec.item.IsIncome() {
+				balances[i].Balance += rec.item.Amount
+				continue
+			}
+			balances[i].Balance -= rec.item.Amount
+			if !rec.createdAt.Before(since) {
+				balances[i].Spent += rec.item.Amount
+			}
+		}
+	}
+	return balances, nil
+}
+
+// resolveAccount returns the name of the account an expense paid with hint belongs to, or
+// "" when the user has none that fits. Callers hold s.mu.
+func (s *Store) resolveAccount(userID int64, hint string) string {
+	hintType, _ := storage.ParseAccountType(hint)
+	var byType, byDefault string
+	for _, a := range s.accounts {
+		switch {
+		case a.UserID != userID:
+		case strings.EqualFold(a.Name, hint):
+			return a.Name
+		case byType == "" && hintType != "" && a.Type == hintType:
+			byType = a.Name
+		case byDefault == "" && a.Default:
+			byDefault = a.Name
+		}
+	}
+	if byType != "" {
+		return byType
+	}
+	return byDefault
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.AggregateStore = (*Store)(nil)
+
+// BucketTotals groups the expenses matching filter by time bucket and category.
+func (s *Store) BucketTotals(_ context.Context, filter storage.ExportFilter, granularity storage.Granularity) ([]storage.BucketTotal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type key struct {
+		start    time.Time
+		category string
+	}
+	totals := map[key]*storage.BucketTotal{}
+	for _, rec := range s.records {
+		e := rec.expense()
+		if !filter.Matches(e) {
+			continue
+		}
+		k := key{start: bucketStart(e.CreatedAt, granularity), category: e.Category}
+		total, ok := totals[k]
+		if !ok {
+			total = &storage.BucketTotal{Start: k.start, Category: k.category}
+			totals[k] = total
+		}
+		total.Count++
+		total.Total += e.Amount
+	}
+
+	result := make([]storage.BucketTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].Category < result[j].Category
+	})
+	return result, nil
+}
+
+// CategoryStats returns the count, sum and sum of squares of amounts per category.
+func (s *Store) CategoryStats(_ context.Context, filter storage.ExportFilter) ([]storage.CategoryStat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := map[string]*storage.CategoryStat{}
+	for _, rec := range s.records {
+		e := rec.expense()
+		if !filter.Matches(e) {
+			continue
+		}
+		stat, ok := stats[e.Category]
+		if !ok {
+			stat = &storage.CategoryStat{Category: e.Category}
+			stats[e.Category] = stat
+		}
+		stat.Count++
+		stat.Sum += e.Amount
+		stat.SumSquares += e.Amount * e.Amount
+	}
+
+	result := make([]storage.CategoryStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Category < result[j].Category })
+	return result, nil
+}
+
+func bucketStart(t time.Time, granularity storage.Granularity) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case storage.BucketWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case storage.BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.BudgetStore = (*Store)(nil)
+
+type budgetKey struct {
+	userID   int64
+	category string
+}
+
+// SetBudget creates or replaces a category budget.
+func (s *Store) SetBudget(_ context.Context, budget storage.CategoryBudget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.budgets == nil {
+		s.budgets = make(map[budgetKey]storage.CategoryBudget)
+	}
+	s.budgets[budgetKey{budget.UserID, budget.Category}] = budget
+	return nil
+}
+
+// ListBudgets returns a user's budgets ordered by category.
+func (s *Store) ListBudgets(_ context.Context, userID int64) ([]storage.CategoryBudget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var budgets []storage.CategoryBudget
+	for key, budget := range s.budgets {
+		if key.userID == userID {
+			budgets = append(budgets, budget)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Category < budgets[j].Category })
+	return budgets, nil
+}
+
+// DeleteBudget removes a category budget.
+func (s *Store) DeleteBudget(_ context.Context, userID int64, category string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := budgetKey{userID, category}
+	if _, ok := s.budgets[key]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.budgets, key)
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.GoalStore = (*Store)(nil)
+
+// CreateGoal adds a savings goal.
+func (s *Store) CreateGoal(_ context.Context, goal storage.Goal) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.findGoal(goal.UserID, goal.Name); ok {
+		return 0, storage.ErrExists
+	}
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = time.Now().UTC()
+	}
+	s.goalSeq++
+	goal.ID = s.goalSeq
+	s.goals = append(s.goals, storage.GoalProgress{Goal: goal})
+	return goal.ID, nil
+}
+
+// ContributeToGoal logs an amount saved toward one of the user's goals.
+func (s *Store) ContributeToGoal(_ context.Context, userID int64, name string, amount float64, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.findGoal(userID, name)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	s.goals[i].Saved += amount
+	s.goals[i].Contributions++
+	return nil
+}
+
+// DeleteGoal removes one of the user's goals.
+func (s *Store) DeleteGoal(_ context.Context, userID int64, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.findGoal(userID, name)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	s.goals = append(s.goals[:i], s.goals[i+1:]...)
+	return nil
+}
+
+// GoalProgress returns a user's goals with their savings, oldest goal first.
+func (s *Store) GoalProgress(_ context.Context, userID int64) ([]storage.GoalProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var goals []storage.GoalProgress
+	for _, g := range s.goals {
+		if g.UserID == userID {
+			goals = append(goals, g)
+		}
+	}
+	return goals, nil
+}
+
+func (s *Store) findGoal(userID int64, name string) (int, bool) {
+	for i, g := range s.goals {
+		if g.UserID == userID && strings.EqualFold(g.Name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
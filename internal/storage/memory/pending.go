@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.PendingQueue = (*Store)(nil)
+
+// EnqueuePending stores a message for a later retry.
+func (s *Store) EnqueuePending(_ context.Context, pending storage.PendingExpense) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pendingSeq++
+	pending.ID = s.pendingSeq
+	if pending.CreatedAt.IsZero() {
+		pending.CreatedAt = time.Now().UTC()
+	}
+	if s.pending == nil {
+		s.pending = make(map[int64]storage.PendingExpense)
+	}
+	s.pending[pending.ID] = pending
+	return pending.ID, nil
+}
+
+// DuePending returns queued messages whose next attempt is at or before now.
+func (s *Store) DuePending(_ context.Context, now time.Time, limit int) ([]storage.PendingExpense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []storage.PendingExpense
+	for _, p := range s.sortedPending() {
+		if p.NextAttempt.IsZero() || p.NextAttempt.After(now) {
+			continue
+		}
+		due = append(due, p)
+		if len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
+
+// ReschedulePending records a failed attempt and the next retry time.
+func (s *Store) ReschedulePending(_ context.Context, id int64, next time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pending[id]
+	if !ok {
+		return nil
+	}
+	p.Attempts++
+	p.LastError = lastError
+	p.NextAttempt = next
+	s.pending[id] = p
+	return nil
+}
+
+// DeletePending removes a message from the queue.
+func (s *Store) DeletePending(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+	return nil
+}
+
+// ListPending returns every queued message for a chat, oldest first.
+func (s *Store) ListPending(_ context.Context, chatID int64) ([]storage.PendingExpense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []storage.PendingExpense
+	for _, p := range s.sortedPending() {
+		if p.ChatID == chatID {
+			items = append(items, p)
+		}
+	}
+	return items, nil
+}
+
+func (s *Store) sortedPending() []storage.PendingExpense {
+	items := make([]storage.PendingExpense, 0, len(s.pending))
+	for _, p := range s.pending {
+		items = append(items, p)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
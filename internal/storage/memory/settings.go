@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.SettingsStore = (*Store)(nil)
+
+// UserSettings returns the user's saved settings, or the defaults.
+func (s *Store) UserSettings(_ context.Context, userID int64) (locale.Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if settings, ok := s.settings[userID]; ok {
+		return settings, nil
+	}
+	return locale.Default(), nil
+}
+
+// SaveUserSettings creates or replaces the user's settings.
+func (s *Store) SaveUserSettings(_ context.Context, userID int64, settings locale.Settings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.settings == nil {
+		s.settings = make(map[int64]locale.Settings)
+	}
+	s.settings[userID] = settings
+	return nil
+}
@@ -2,20 +2,35 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
 	"github.com/Oxyrus/financebot/internal/storage"
 )
 
 // Store keeps expenses in-memory; useful for development and testing.
 type Store struct {
-	mu      sync.Mutex
-	records []record
+	mu         sync.Mutex
+	records    []record
+	expenseSeq int64
+	pending    map[int64]storage.PendingExpense
+	pendingSeq int64
+	budgets    map[budgetKey]storage.CategoryBudget
+	tokens     map[int64]storage.APIToken
+	tokenSeq   int64
+	accounts   []storage.Account
+	accountSeq int64
+	goals      []storage.GoalProgress
+	goalSeq    int64
+	settings   map[int64]locale.Settings
 }
 
 type record struct {
+	id        int64
+	userID    int64
 	item      expense.Item
 	createdAt time.Time
 }
@@ -28,13 +43,144 @@ func NewStore() *Store {
 }
 
 // SaveExpense appends a new expense to memory.
-func (s *Store) SaveExpense(_ context.Context, item expense.Item) error {
+func (s *Store) SaveExpense(_ context.Context, userID int64, item expense.Item) (int64, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.records = append(s.records, record{item: item, createdAt: time.Now().UTC()})
+	createdAt := time.Now().UTC()
+	if !item.Date.IsZero() {
+		createdAt = item.Date.UTC()
+	}
+	item.Account = s.resolveAccount(userID, item.Account)
+	s.expenseSeq++
+	s.records = append(s.records, record{id: s.expenseSeq, userID: userID, item: item, createdAt: createdAt})
+	return s.expenseSeq, nil
+}
+
+// RecentExpenses returns a user's expenses recorded since the provided time, newest first.
+func (s *Store) RecentExpenses(_ context.Context, userID int64, since time.Time) ([]storage.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recent []storage.Expense
+	for _, rec := range s.records {
+		if rec.userID != userID || rec.createdAt.Before(since) {
+			continue
+		}
+		recent = append(recent, rec.expense())
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].CreatedAt.After(recent[j].CreatedAt)
+	})
+	return recent, nil
+}
+
+// ExportExpenses passes the expenses matching filter to fn, oldest first.
+func (s *Store) ExportExpenses(_ context.Context, filter storage.ExportFilter, fn func(storage.Expense) error) error {
+	s.mu.Lock()
+	var matched []storage.Expense
+	for _, rec := range s.records {
+		if e := rec.expense(); filter.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+	})
+	for _, e := range matched {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListExpenses returns one page of the expenses matching filter, newest first.
+func (s *Store) ListExpenses(_ context.Context, filter storage.ExportFilter, limit, offset int) ([]storage.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []storage.Expense
+	for _, rec := range s.records {
+		if e := rec.expense(); filter.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// GetExpense returns one of the user's expenses.
+func (s *Store) GetExpense(_ context.Context, userID, id int64) (storage.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.find(userID, id)
+	if !ok {
+		return storage.Expense{}, storage.ErrNotFound
+	}
+	return s.records[i].expense(), nil
+}
+
+// UpdateExpense replaces the fields of one of the user's expenses; a zero Date keeps the
+// original timestamp, an empty Account keeps the original account and the tags are left
+// alone.
+func (s *Store) UpdateExpense(_ context.Context, userID, id int64, item expense.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.find(userID, id)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if !item.Date.IsZero() {
+		s.records[i].createdAt = item.Date.UTC()
+	}
+	item.Tags = s.records[i].item.Tags
+	if item.Account == "" {
+		item.Account = s.records[i].item.Account
+	} else {
+		item.Account = s.resolveAccount(userID, item.Account)
+	}
+	s.records[i].item = item
 	return nil
 }
 
+// DeleteExpense removes one of the user's expenses.
+func (s *Store) DeleteExpense(_ context.Context, userID, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.find(userID, id)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	s.records = append(s.records[:i], s.records[i+1:]...)
+	return nil
+}
+
+func (s *Store) find(userID, id int64) (int, bool) {
+	for i, rec := range s.records {
+		if rec.id == id && rec.userID == userID {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 // Items returns a defensive copy of all stored expenses; primarily for tests.
 func (s *Store) Items() []expense.Item {
 	s.mu.Lock()
@@ -52,21 +198,34 @@ func (s *Store) Close() error {
 	return nil
 }
 
-// Stats aggregates expenses since the provided time.
-func (s *Store) Stats(_ context.Context, since time.Time) (storage.Summary, error) {
+// Stats aggregates the expenses matching filter by category and by tag, and totals the
+// matching income.
+func (s *Store) Stats(_ context.Context, filter storage.ExportFilter) (storage.Summary, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	summary := storage.Summary{CategoryTotals: make(map[string]float64)}
+	summary := storage.Summary{CategoryTotals: make(map[string]float64), TagTotals: make(map[string]float64)}
 
 	for _, rec := range s.records {
-		if rec.createdAt.Before(since) {
+		if !filter.Matches(rec.expense()) {
+			continue
+		}
+		if rec.item.IsIncome() {
+			summary.IncomeCount++
+			summary.IncomeAmount += rec.item.Amount
 			continue
 		}
 		summary.TotalCount++
 		summary.TotalAmount += rec.item.Amount
 		summary.CategoryTotals[rec.item.Category] += rec.item.Amount
+		for _, tag := range rec.item.Tags {
+			summary.TagTotals[tag] += rec.item.Amount
+		}
 	}
 
 	return summary, nil
 }
+
+func (r record) expense() storage.Expense {
+	return storage.Expense{ID: r.id, UserID: r.userID, CreatedAt: r.createdAt, Item: r.item}
+}
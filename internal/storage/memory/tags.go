@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.TagStore = (*Store)(nil)
+
+// TagExpense adds tags to one of the user's expenses.
+func (s *Store) TagExpense(_ context.Context, userID, id int64, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.find(userID, id)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	// Copy so expenses handed out earlier do not see the change.
+	updated := expense.AddTags(slices.Clone(s.records[i].item.Tags), tags...)
+	if len(updated) > expense.MaxTags {
+		return storage.ErrTooManyTags
+	}
+	s.records[i].item.Tags = updated
+	return nil
+}
+
+// UntagExpense removes tags from one of the user's expenses.
+func (s *Store) UntagExpense(_ context.Context, userID, id int64, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.find(userID, id)
+	if !ok {
+		return storage.ErrNotFound
+	}
+	var kept []string
+	for _, tag := range s.records[i].item.Tags {
+		if !expense.HasTag(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	s.records[i].item.Tags = kept
+	return nil
+}
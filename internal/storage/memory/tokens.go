@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.APITokenStore = (*Store)(nil)
+
+// CreateAPIToken stores a token hash.
+func (s *Store) CreateAPIToken(_ context.Context, token storage.APIToken) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokenSeq++
+	token.ID = s.tokenSeq
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+	if s.tokens == nil {
+		s.tokens = make(map[int64]storage.APIToken)
+	}
+	s.tokens[token.ID] = token
+	return token.ID, nil
+}
+
+// APITokenByHash looks up a token by the hash of its secret.
+func (s *Store) APITokenByHash(_ context.Context, hash string) (storage.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return storage.APIToken{}, storage.ErrNotFound
+}
+
+// ListAPITokens returns a user's tokens, oldest first.
+func (s *Store) ListAPITokens(_ context.Context, userID int64) ([]storage.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tokens []storage.APIToken
+	for _, token := range s.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes one of the user's tokens, or all of them when id is zero.
+func (s *Store) RevokeAPIToken(_ context.Context, userID, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for tokenID, token := range s.tokens {
+		if token.UserID == userID && (id == 0 || tokenID == id) {
+			delete(s.tokens, tokenID)
+			found = true
+		}
+	}
+	if !found && id != 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
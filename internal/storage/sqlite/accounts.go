@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.AccountStore = (*Store)(nil)
+
+// CreateAccount adds an account; the user's first one becomes their default.
+func (s *Store) CreateAccount(ctx context.Context, account storage.Account) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: begin create account: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts WHERE user_id = ? AND name = ?`, account.UserID, account.Name).Scan(&existing)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: look up account: %w", err)
+	}
+	if existing > 0 {
+		return 0, storage.ErrExists
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO accounts (user_id, name, type, opening_balance, is_default, created_at)
+		VALUES (?, ?, ?, ?, NOT EXISTS (SELECT 1 FROM accounts WHERE user_id = ?), ?)`,
+		account.UserID, account.Name, string(account.Type), account.OpeningBalance, account.UserID, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: insert account: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: account id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite: commit account: %w", err)
+	}
+	return id, nil
+}
+
+// ListAccounts returns a user's accounts ordered by name.
+func (s *Store) ListAccounts(ctx context.Context, userID int64) ([]storage.Account, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, type, opening_balance, is_default
+		FROM accounts WHERE user_id = ? ORDER BY name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []storage.Account
+	for rows.Next() {
+		var a storage.Account
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Name, &a.Type, &a.OpeningBalance, &a.Default); err != nil {
+			return nil, fmt.Errorf("sqlite: scan account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: account rows: %w", err)
+	}
+	return accounts, nil
+}
+
+// SetDefaultAccount makes the named account the user's default.
+func (s *Store) SetDefaultAccount(ctx context.Context, userID int64, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin default account: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM accounts WHERE user_id = ? AND name = ?`, userID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("sqlite: look up account: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE accounts SET is_default = (id = ?) WHERE user_id = ?`, id, userID); err != nil {
+		return fmt.Errorf("sqlite: set default account: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit default account: %w", err)
+	}
+	return nil
+}
+
+// AccountBalances returns a user's accounts with their balances and the spending since
+// the given time.
+func (s *Store) AccountBalances(ctx context.Context, userID int64, since time.Time) ([]storage.AccountBalance, error) {
+	if s.encrypted() {
+		return s.accountBalancesDecrypted(ctx, userID, since)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT a.id, a.user_id, a.name, a.type, a.opening_balance, a.is_default,
+			a.opening_balance + COALESCE(SUM(e.sign * e.amount), 0),
+			COALESCE(SUM(CASE WHEN e.sign = -1 AND e.created_at >= ? THEN e.amount END), 0)
+		FROM accounts a
+		LEFT JOIN expenses e ON e.account_id = a.id AND e.user_id = a.user_id
+		WHERE a.user_id = ?
+		GROUP BY a.id
+		ORDER BY a.name`, since.UTC(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query account balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []storage.AccountBalance
+	for rows.Next() {
+		var b storage.AccountBalance
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Name, &b.Type, &b.OpeningBalance, &b.Default, &b.Balance, &b.Spent); err != nil {
+			return nil, fmt.Errorf("sqlite: scan account balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: account balance rows: %w", err)
+	}
+	return balances, nil
+}
+
+// resolveAccount picks the account for an expense paid with hint: the account by that
+// name, else the first account of the type the hint names, else the user's default. It
+// returns a null ID when nothing matches.
+func resolveAccount(ctx context.Context, tx *sql.Tx, userID int64, hint string) (sql.NullInt64, error) {
+	hintType, _ := storage.ParseAccountType(hint)
+	var id sql.NullInt64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM accounts
+		WHERE user_id = ? AND (name = ? OR type = ? OR is_default)
+		ORDER BY CASE WHEN name = ? THEN 0 WHEN type = ? THEN 1 ELSE 2 END, id
+		LIMIT 1`, userID, hint, string(hintType), hint, string(hintType)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullInt64{}, nil
+	}
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("sqlite: resolve account: %w", err)
+	}
+	return id, nil
+}
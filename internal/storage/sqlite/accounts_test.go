@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStoreAccounts(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "Wallet", Type: storage.AccountCash, OpeningBalance: 100}); err != nil {
+		t.Fatalf("CreateAccount wallet: %v", err)
+	}
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "Visa", Type: storage.AccountCredit}); err != nil {
+		t.Fatalf("CreateAccount visa: %v", err)
+	}
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "visa", Type: storage.AccountDebit}); !errors.Is(err, storage.ErrExists) {
+		t.Fatalf("expected ErrExists for a duplicate name, got %v", err)
+	}
+
+	accounts, err := store.ListAccounts(ctx, 7)
+	if err != nil {
+		t.Fatalf("ListAccounts error: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Name != "Visa" || accounts[0].Default || !accounts[1].Default {
+		t.Fatalf("expected the first account to be the default, got %#v", accounts)
+	}
+
+	for _, item := range []expense.Item{
+		{Category: "Coffee", Amount: 4, Description: "Latte", Account: "VISA"},
+		{Category: "Taxi", Amount: 10, Description: "Taxi", Account: "cash"},
+		{Category: "Food", Amount: 6, Description: "Snack"},
+		{Category: "Refund", Amount: 20, Description: "Refund", Account: "visa", Kind: expense.KindIncome},
+		{Category: "Hotel", Amount: 300, Description: "Hotel", Account: "visa", Date: time.Now().AddDate(0, -2, 0)},
+	} {
+		if _, err := store.SaveExpense(ctx, 7, item); err != nil {
+			t.Fatalf("SaveExpense %s: %v", item.Description, err)
+		}
+	}
+
+	recent, err := store.RecentExpenses(ctx, 7, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("RecentExpenses error: %v", err)
+	}
+	if len(recent) != 4 || recent[3].Account != "Visa" || recent[2].Account != "Wallet" || recent[1].Account != "Wallet" {
+		t.Fatalf("expected expenses linked by name, type and default, got %#v", recent)
+	}
+
+	balances, err := store.AccountBalances(ctx, 7, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("AccountBalances error: %v", err)
+	}
+	if len(balances) != 2 || balances[0].Balance != -284 || balances[0].Spent != 4 || balances[1].Balance != 84 || balances[1].Spent != 16 {
+		t.Fatalf("unexpected balances %#v", balances)
+	}
+
+	if err := store.SetDefaultAccount(ctx, 7, "visa"); err != nil {
+		t.Fatalf("SetDefaultAccount error: %v", err)
+	}
+	if err := store.SetDefaultAccount(ctx, 8, "visa"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's account, got %v", err)
+	}
+	id, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 5, Description: "Bagel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, err := store.GetExpense(ctx, 7, id); err != nil || e.Account != "Visa" {
+		t.Fatalf("expected the new default account, got %#v (%v)", e, err)
+	}
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 5, Description: "Bagel", Account: "visa"}); err != nil {
+		t.Fatalf("SaveExpense without accounts: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.AggregateStore = (*Store)(nil)
+
+// bucketExpressions compute the first day of an expense's bucket. Timestamps are stored
+// in UTC, so the leading date is the UTC day, and buckets ignore the user's settings.
+var bucketExpressions = map[storage.Granularity]string{
+	storage.BucketDay:   `substr(created_at, 1, 10)`,
+	storage.BucketWeek:  `date(substr(created_at, 1, 10), 'weekday 0', '-6 days')`,
+	storage.BucketMonth: `substr(created_at, 1, 7) || '-01'`,
+}
+
+// BucketTotals groups the expenses matching filter by time bucket and category.
+func (s *Store) BucketTotals(ctx context.Context, filter storage.ExportFilter, granularity storage.Granularity) ([]storage.BucketTotal, error) {
+	bucket, ok := bucketExpressions[granularity]
+	if !ok {
+		return nil, fmt.Errorf("sqlite: unknown granularity %d", granularity)
+	}
+	if s.encrypted() {
+		return s.bucketTotalsDecrypted(ctx, filter, granularity)
+	}
+	where, args := filterClause(filter)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+bucket+` AS bucket, category, COUNT(*), COALESCE(SUM(amount), 0)
+		FROM expenses
+		WHERE `+where+`
+		GROUP BY bucket, category
+		ORDER BY bucket, category`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query bucket totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []storage.BucketTotal
+	for rows.Next() {
+		var (
+			start string
+			total storage.BucketTotal
+		)
+		if err := rows.Scan(&start, &total.Category, &total.Count, &total.Total); err != nil {
+			return nil, fmt.Errorf("sqlite: scan bucket total: %w", err)
+		}
+		total.Start, err = time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: parse bucket %q: %w", start, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: bucket total rows: %w", err)
+	}
+	return totals, nil
+}
+
+// CategoryStats returns the count, sum and sum of squares of amounts per category.
+func (s *Store) CategoryStats(ctx context.Context, filter storage.ExportFilter) ([]storage.CategoryStat, error) {
+	if s.encrypted() {
+		return s.categoryStatsDecrypted(ctx, filter)
+	}
+	where, args := filterClause(filter)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT category, COUNT(*), COALESCE(SUM(amount), 0), COALESCE(SUM(amount * amount), 0)
+		FROM expenses
+		WHERE `+where+`
+		GROUP BY category
+		ORDER BY category`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query category stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []storage.CategoryStat
+	for rows.Next() {
+		var stat storage.CategoryStat
+		if err := rows.Scan(&stat.Category, &stat.Count, &stat.Sum, &stat.SumSquares); err != nil {
+			return nil, fmt.Errorf("sqlite: scan category stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: category stat rows: %w", err)
+	}
+	return stats, nil
+}
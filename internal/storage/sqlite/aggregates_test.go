@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStoreBucketTotals(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	seed := []struct {
+		user     int64
+		category string
+		amount   float64
+		at       time.Time
+	}{
+		{7, "Food", 10, time.Date(2026, 9, 28, 8, 0, 0, 0, time.UTC)},   // Monday
+		{7, "Food", 5, time.Date(2026, 10, 4, 23, 30, 0, 0, time.UTC)},  // Sunday, same week
+		{7, "Coffee", 3, time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)},  // next Monday
+		{7, "Food", 20, time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)},  // next Monday
+		{8, "Food", 100, time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)}, // someone else
+	}
+	for _, s := range seed {
+		if _, err := store.SaveExpense(ctx, s.user, expense.Item{Category: s.category, Amount: s.amount, Description: "x", Date: s.at}); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		granularity storage.Granularity
+		want        []storage.BucketTotal
+	}{
+		{storage.BucketDay, []storage.BucketTotal{
+			{Start: day(2026, 9, 28), Category: "Food", Count: 1, Total: 10},
+			{Start: day(2026, 10, 4), Category: "Food", Count: 1, Total: 5},
+			{Start: day(2026, 10, 5), Category: "Coffee", Count: 1, Total: 3},
+			{Start: day(2026, 10, 5), Category: "Food", Count: 1, Total: 20},
+		}},
+		{storage.BucketWeek, []storage.BucketTotal{
+			{Start: day(2026, 9, 28), Category: "Food", Count: 2, Total: 15},
+			{Start: day(2026, 10, 5), Category: "Coffee", Count: 1, Total: 3},
+			{Start: day(2026, 10, 5), Category: "Food", Count: 1, Total: 20},
+		}},
+		{storage.BucketMonth, []storage.BucketTotal{
+			{Start: day(2026, 9, 1), Category: "Food", Count: 1, Total: 10},
+			{Start: day(2026, 10, 1), Category: "Coffee", Count: 1, Total: 3},
+			{Start: day(2026, 10, 1), Category: "Food", Count: 2, Total: 25},
+		}},
+	}
+	for _, tc := range cases {
+		got, err := store.BucketTotals(ctx, storage.ExportFilter{UserID: 7}, tc.granularity)
+		if err != nil {
+			t.Fatalf("BucketTotals(%d) error: %v", tc.granularity, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("BucketTotals(%d) = %#v, want %#v", tc.granularity, got, tc.want)
+		}
+		for i := range got {
+			if !got[i].Start.Equal(tc.want[i].Start) || got[i].Category != tc.want[i].Category || got[i].Count != tc.want[i].Count || got[i].Total != tc.want[i].Total {
+				t.Fatalf("BucketTotals(%d)[%d] = %#v, want %#v", tc.granularity, i, got[i], tc.want[i])
+			}
+		}
+	}
+
+	since, err := store.BucketTotals(ctx, storage.ExportFilter{UserID: 7, Since: day(2026, 10, 5)}, storage.BucketWeek)
+	if err != nil || len(since) != 2 {
+		t.Fatalf("expected the filter to apply, got %#v (%v)", since, err)
+	}
+}
+
+func TestSQLiteStoreCategoryStats(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, amount := range []float64{2, 4, 6} {
+		if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: amount, Description: "Latte"}); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 10, Description: "Lunch"}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+
+	stats, err := store.CategoryStats(ctx, storage.ExportFilter{UserID: 7, Category: "Coffee"})
+	if err != nil {
+		t.Fatalf("CategoryStats error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Count != 3 || stats[0].Sum != 12 || math.Abs(stats[0].SumSquares-56) > 1e-9 {
+		t.Fatalf("unexpected stats %#v", stats)
+	}
+}
@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SchemaVersion is the schema version this binary migrates databases to.
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+// Backup writes a consistent copy of the database to path while the store stays usable.
+// VACUUM INTO reads from a single transaction, so writes that land during the backup are
+// either wholly in it or not at all. path must not exist yet. The copy is readable by
+// its owner only, like the database it holds.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	if err := ensureDir(path); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("sqlite: backup: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("sqlite: backup: %w", err)
+	}
+	return nil
+}
+
+// CheckBackup opens the database file at path read-only, runs an integrity check and
+// returns its schema version. It fails for files that are not financebot databases or
+// that were written by a newer binary; older versions are migrated when opened.
+func CheckBackup(ctx context.Context, path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: %w", err)
+	}
+	// Escaped as a URI, so names containing ? or # still open the right file.
+	dsn := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "mode=ro"}
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: open: %w", err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: integrity check: %w", err)
+	}
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("sqlite: check backup: integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: integrity check: %w", err)
+	}
+	if len(problems) > 0 {
+		return 0, fmt.Errorf("sqlite: check backup: integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("sqlite: check backup: read schema version: %w", err)
+	}
+	switch {
+	case version == 0:
+		return 0, errors.New("sqlite: check backup: not a financebot database")
+	case version > len(migrations):
+		return 0, fmt.Errorf("sqlite: check backup: schema version %d is newer than this binary supports (%d)", version, len(migrations))
+	}
+	return version, nil
+}
+
+// Restore checks the backup at backupPath and copies it over the database at
+// databasePath. The copy is written next to the destination and renamed into place, so
+// an interrupted restore leaves the old database untouched. It holds an exclusive lock
+// on an existing database while it runs, and fails when another process has it locked
+// or left a rollback journal behind, which both mean the bot is still running or
+// crashed mid-write.
+func Restore(ctx context.Context, backupPath, databasePath string) (int, error) {
+	version, err := CheckBackup(ctx, backupPath)
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureDir(databasePath); err != nil {
+		return 0, err
+	}
+	unlock, err := lockExclusive(ctx, databasePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	src, err := os.Open(backupPath)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: restore: %w", err)
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(databasePath), filepath.Base(databasePath)+".restore-*")
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: restore: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("sqlite: restore: copy: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("sqlite: restore: sync: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("sqlite: restore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), databasePath); err != nil {
+		return 0, fmt.Errorf("sqlite: restore: %w", err)
+	}
+	return version, nil
+}
+
+// lockExclusive takes an exclusive lock on the database at path, if there is one, and
+// returns the function that releases it.
+func lockExclusive(ctx context.Context, path string) (func(), error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return func() {}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("sqlite: restore: %w", err)
+	}
+	if _, err := os.Stat(path + "-journal"); err == nil {
+		return nil, fmt.Errorf("sqlite: restore: %s-journal exists; stop the bot, or start it once to recover the interrupted write, then try again", path)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: restore: lock: %w", err)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite: restore: lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `BEGIN EXCLUSIVE`); err != nil {
+		conn.Close()
+		db.Close()
+		return nil, fmt.Errorf("sqlite: restore: %s is in use; stop the bot first: %w", path, err)
+	}
+	return func() {
+		// The lock is on the replaced file, so rolling back cannot touch the restored one.
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `ROLLBACK`)
+		conn.Close()
+		db.Close()
+	}, nil
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+
+	backup := filepath.Join(dir, "backups", "finance-backup.db")
+	if err := store.Backup(ctx, backup); err != nil {
+		t.Fatalf("Backup error: %v", err)
+	}
+	if info, err := os.Stat(backup); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a backup readable by its owner only, got %v (%v)", info.Mode(), err)
+	}
+	if err := store.Backup(ctx, backup); err == nil {
+		t.Fatal("expected an existing backup not to be overwritten")
+	}
+	if version, err := CheckBackup(ctx, backup); err != nil || version != SchemaVersion() {
+		t.Fatalf("expected a valid backup at version %d, got %d (%v)", SchemaVersion(), version, err)
+	}
+
+	restored := filepath.Join(dir, "restored", "finance.db")
+	if _, err := Restore(ctx, backup, restored); err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+	restoredStore, err := NewStore(restored)
+	if err != nil {
+		t.Fatalf("NewStore on restored database error: %v", err)
+	}
+	defer restoredStore.Close()
+	summary, err := restoredStore.Stats(ctx, storage.ExportFilter{UserID: 7})
+	if err != nil {
+		t.Fatalf("Stats error: %v", err)
+	}
+	if summary.TotalCount != 1 || summary.TotalAmount != 3.5 {
+		t.Fatalf("expected the backed up expense, got %+v", summary)
+	}
+}
+
+func TestCheckBackupRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := CheckBackup(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Fatal("expected a missing backup to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.db")); err == nil {
+		t.Fatal("expected the check not to create the file")
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte(strings.Repeat("not a database ", 100)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CheckBackup(ctx, garbage); err == nil {
+		t.Fatal("expected a corrupt file to be rejected")
+	}
+
+	empty := filepath.Join(dir, "empty.db")
+	writeVersion(t, empty, 0)
+	if _, err := CheckBackup(ctx, empty); err == nil || !strings.Contains(err.Error(), "not a financebot database") {
+		t.Fatalf("expected an unversioned database to be rejected, got %v", err)
+	}
+
+	future := filepath.Join(dir, "future.db")
+	writeVersion(t, future, SchemaVersion()+1)
+	if _, err := CheckBackup(ctx, future); err == nil || !strings.Contains(err.Error(), "newer than this binary") {
+		t.Fatalf("expected a newer schema to be rejected, got %v", err)
+	}
+	target := filepath.Join(dir, "target.db")
+	if _, err := Restore(ctx, future, target); err == nil {
+		t.Fatal("expected Restore to reject a newer schema")
+	}
+	if _, err := os.Stat(target); err == nil {
+		t.Fatal("expected a rejected restore to leave no database behind")
+	}
+}
+
+func TestCheckBackupEscapesPath(t *testing.T) {
+	dir := t.TempDir()
+	plain, path := filepath.Join(dir, "plain.db"), filepath.Join(dir, "odd?name#1.db")
+	writeVersion(t, plain, SchemaVersion())
+	if err := os.Rename(plain, path); err != nil {
+		t.Fatal(err)
+	}
+	if version, err := CheckBackup(context.Background(), path); err != nil || version != SchemaVersion() {
+		t.Fatalf("expected version %d, got %d (%v)", SchemaVersion(), version, err)
+	}
+}
+
+func TestRestoreRefusesDatabaseInUse(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	backup := filepath.Join(dir, "backup.db")
+	writeVersion(t, backup, SchemaVersion())
+	target := filepath.Join(dir, "finance.db")
+	writeVersion(t, target, SchemaVersion())
+
+	db, err := sql.Open("sqlite", target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(ctx, backup, target); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected a locked database to be refused, got %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `ROLLBACK`); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if err := os.WriteFile(target+"-journal", []byte("hot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(ctx, backup, target); err == nil || !strings.Contains(err.Error(), "-journal exists") {
+		t.Fatalf("expected a leftover journal to be refused, got %v", err)
+	}
+	if err := os.Remove(target + "-journal"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(ctx, backup, target); err != nil {
+		t.Fatalf("expected the restore to succeed once the database is free, got %v", err)
+	}
+}
+
+func writeVersion(t *testing.T, path string, version int) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE t (x INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.BudgetStore = (*Store)(nil)
+
+// SetBudget creates or replaces a category budget.
+func (s *Store) SetBudget(ctx context.Context, budget storage.CategoryBudget) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO budgets (user_id, category, monthly_limit) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, category) DO UPDATE SET monthly_limit = excluded.monthly_limit`,
+		budget.UserID, budget.Category, budget.MonthlyLimit); err != nil {
+		return fmt.Errorf("sqlite: set budget: %w", err)
+	}
+	return nil
+}
+
+// ListBudgets returns a user's budgets ordered by category.
+func (s *Store) ListBudgets(ctx context.Context, userID int64) ([]storage.CategoryBudget, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT user_id, category, monthly_limit FROM budgets WHERE user_id = ? ORDER BY category`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []storage.CategoryBudget
+	for rows.Next() {
+		var b storage.CategoryBudget
+		if err := rows.Scan(&b.UserID, &b.Category, &b.MonthlyLimit); err != nil {
+			return nil, fmt.Errorf("sqlite: scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: budget rows: %w", err)
+	}
+	return budgets, nil
+}
+
+// DeleteBudget removes a category budget.
+func (s *Store) DeleteBudget(ctx context.Context, userID int64, category string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM budgets WHERE user_id = ? AND category = ?`, userID, category)
+	if err != nil {
+		return fmt.Errorf("sqlite: delete budget: %w", err)
+	}
+	return expectAffected(res, "delete budget")
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+// GetCachedExtraction returns a cached extraction stored at or after notBefore.
+func (s *Store) GetCachedExtraction(ctx context.Context, key string, notBefore time.Time) (expense.Item, bool, error) {
+	var raw any
+	err := s.db.QueryRowContext(ctx, `
+		SELECT item FROM extraction_cache
+		WHERE key = ? AND created_at >= ?`, s.storedCacheKey(key), notBefore.UTC()).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return expense.Item{}, false, nil
+	}
+	if err != nil {
+		return expense.Item{}, false, fmt.Errorf("sqlite: query extraction cache: %w", err)
+	}
+
+	text, err := s.openText("extraction cache", cacheAAD(key), raw)
+	if err != nil {
+		return expense.Item{}, false, err
+	}
+	var item expense.Item
+	if err := json.Unmarshal([]byte(text), &item); err != nil {
+		return expense.Item{}, false, fmt.Errorf("sqlite: decode extraction cache: %w", err)
+	}
+	return item, true, nil
+}
+
+// PutCachedExtraction stores or refreshes a cached extraction.
+func (s *Store) PutCachedExtraction(ctx context.Context, key string, item expense.Item, at time.Time) error {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("sqlite: encode extraction cache: %w", err)
+	}
+	// The cached item holds the same details as the expense it becomes.
+	stored, err := s.sealText(s.enc.Description, cacheAAD(key), string(raw))
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO extraction_cache (key, item, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET item = excluded.item, created_at = excluded.created_at`,
+		s.storedCacheKey(key), stored, at.UTC()); err != nil {
+		return fmt.Errorf("sqlite: insert extraction cache: %w", err)
+	}
+	return nil
+}
+
+// PruneCachedExtractions drops entries older than olderThan and keeps at most keep of the
+// newest ones; keep <= 0 disables the size bound.
+func (s *Store) PruneCachedExtractions(ctx context.Context, olderThan time.Time, keep int) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM extraction_cache WHERE created_at < ?`, olderThan.UTC()); err != nil {
+		return fmt.Errorf("sqlite: prune extraction cache: %w", err)
+	}
+	if keep <= 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		DELETE FROM extraction_cache
+		WHERE key NOT IN (SELECT key FROM extraction_cache ORDER BY created_at DESC LIMIT ?)`, keep); err != nil {
+		return fmt.Errorf("sqlite: trim extraction cache: %w", err)
+	}
+	return nil
+}
+
+// storedCacheKey is the key a cache entry is stored under. The extractor's keys are plain
+// hashes of the message, so with encryption on they are hashed again with a secret key;
+// otherwise anyone with the database could confirm guesses of short messages. Changing the
+// current key orphans the old entries, which Reencrypt clears.
+func (s *Store) storedCacheKey(key string) string {
+	if !s.enc.Description {
+		return key
+	}
+	return s.enc.Keys.lookupKey(key)
+}
+
+// cacheAAD scopes an encrypted cache entry to its key.
+func cacheAAD(key string) string {
+	return "extraction_cache:" + key
+}
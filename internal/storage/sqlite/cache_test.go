@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+)
+
+func TestSQLiteStoreExtractionCache(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	item := expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}
+
+	if err := store.PutCachedExtraction(ctx, "k1", item, now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("PutCachedExtraction error: %v", err)
+	}
+	if _, ok, err := store.GetCachedExtraction(ctx, "k1", now.Add(-time.Hour)); err != nil || ok {
+		t.Fatalf("expected stale entry to be ignored, got ok=%v err=%v", ok, err)
+	}
+
+	if err := store.PutCachedExtraction(ctx, "k1", item, now); err != nil {
+		t.Fatalf("PutCachedExtraction refresh error: %v", err)
+	}
+	got, ok, err := store.GetCachedExtraction(ctx, "k1", now.Add(-time.Hour))
+	if err != nil || !ok || !reflect.DeepEqual(got, item) {
+		t.Fatalf("expected cached item, got %#v ok=%v err=%v", got, ok, err)
+	}
+
+	if err := store.PutCachedExtraction(ctx, "k2", item, now.Add(time.Second)); err != nil {
+		t.Fatalf("PutCachedExtraction error: %v", err)
+	}
+	if err := store.PruneCachedExtractions(ctx, now.Add(-time.Hour), 1); err != nil {
+		t.Fatalf("PruneCachedExtractions error: %v", err)
+	}
+	if _, ok, _ := store.GetCachedExtraction(ctx, "k1", time.Time{}); ok {
+		t.Fatal("expected oldest entry to be trimmed by the size bound")
+	}
+	if _, ok, _ := store.GetCachedExtraction(ctx, "k2", time.Time{}); !ok {
+		t.Fatal("expected newest entry to survive pruning")
+	}
+}
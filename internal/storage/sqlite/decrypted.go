@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+// The aggregates below stand in for the SQL ones once encryption is configured. SQL
+// cannot sum or group encrypted amounts and categories, so they stream the matching
+// expenses through eachExpense and total the decrypted values, with the same results.
+
+// statsDecrypted is Stats over decrypted rows.
+func (s *Store) statsDecrypted(ctx context.Context, filter storage.ExportFilter) (storage.Summary, error) {
+	summary := storage.Summary{CategoryTotals: make(map[string]float64), TagTotals: make(map[string]float64)}
+	err := s.eachExpense(ctx, filter, "id", func(e storage.Expense) error {
+		if e.IsIncome() {
+			summary.IncomeCount++
+			summary.IncomeAmount += e.Amount
+			return nil
+		}
+		for _, tag := range e.Tags {
+			summary.TagTotals[tag] += e.Amount
+		}
+		// Like the SQL query, uncategorized spending only counts toward its tags.
+		if e.Category == "" {
+			return nil
+		}
+		summary.TotalCount++
+		summary.TotalAmount += e.Amount
+		summary.CategoryTotals[e.Category] += e.Amount
+		return nil
+	})
+	return summary, err
+}
+
+// bucketTotalsDecrypted is BucketTotals over decrypted rows.
+func (s *Store) bucketTotalsDecrypted(ctx context.Context, filter storage.ExportFilter, granularity storage.Granularity) ([]storage.BucketTotal, error) {
+	type key struct {
+		start    time.Time
+		category string
+	}
+	totals := map[key]*storage.BucketTotal{}
+	err := s.eachExpense(ctx, filter, "id", func(e storage.Expense) error {
+		k := key{start: bucketStart(e.CreatedAt, granularity), category: e.Category}
+		total, ok := totals[k]
+		if !ok {
+			total = &storage.BucketTotal{Start: k.start, Category: k.category}
+			totals[k] = total
+		}
+		total.Count++
+		total.Total += e.Amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.BucketTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].Category < result[j].Category
+	})
+	return result, nil
+}
+
+// categoryStatsDecrypted is CategoryStats over decrypted rows.
+func (s *Store) categoryStatsDecrypted(ctx context.Context, filter storage.ExportFilter) ([]storage.CategoryStat, error) {
+	stats := map[string]*storage.CategoryStat{}
+	err := s.eachExpense(ctx, filter, "id", func(e storage.Expense) error {
+		stat, ok := stats[e.Category]
+		if !ok {
+			stat = &storage.CategoryStat{Category: e.Category}
+			stats[e.Category] = stat
+		}
+		stat.Count++
+		stat.Sum += e.Amount
+		stat.SumSquares += e.Amount * e.Amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]storage.CategoryStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Category < result[j].Category })
+	return result, nil
+}
+
+// accountBalancesDecrypted is AccountBalances over decrypted rows. Expenses carry the
+// name of their account, which is unique per user.
+func (s *Store) accountBalancesDecrypted(ctx context.Context, userID int64, since time.Time) ([]storage.AccountBalance, error) {
+	accounts, err := s.ListAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]storage.AccountBalance, len(accounts))
+	byName := make(map[string]*storage.AccountBalance, len(accounts))
+	for i, a := range accounts {
+		balances[i] = storage.AccountBalance{Account: a, Balance: a.OpeningBalance}
+		byName[a.Name] = &balances[i]
+	}
+
+	err = s.eachExpense(ctx, storage.ExportFilter{UserID: userID}, "id", func(e storage.Expense) error {
+		b, ok := byName[e.Account]
+		if !ok {
+			return nil
+		}
+		if e.IsIncome() {
+			b.Balance += e.Amount
+			return nil
+		}
+		b.Balance -= e.Amount
+		if !e.CreatedAt.Before(since) {
+			b.Spent += e.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// bucketStart is the Go counterpart of bucketExpressions.
+func bucketStart(t time.Time, granularity storage.Granularity) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case storage.BucketWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case storage.BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
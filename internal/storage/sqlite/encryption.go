@@ -0,0 +1,492 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// sealedVersion leads every encrypted value, ahead of the key ID, the nonce and the
+	// AES-GCM ciphertext.
+	sealedVersion = 1
+	keySize       = 32
+	reencryptPage = 200
+)
+
+// Keyring holds the AES-256 keys expense fields are encrypted with. The first key
+// encrypts; all of them decrypt, so values written under an older key stay readable
+// until Reencrypt has moved them to the current one.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+	encoded []string
+	// macKey is derived from the current key and keys lookups that must not reveal what
+	// they look up.
+	macKey []byte
+}
+
+// ParseKeyring reads keys written as id:base64, separated by commas or newlines, with the
+// current key first. Blank lines and lines starting with # are ignored. Keys are 32
+// random bytes, e.g. from `openssl rand -base64 32`.
+func ParseKeyring(raw string) (*Keyring, error) {
+	ring := &Keyring{aeads: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" || len(id) > 255 {
+			return nil, errors.New("sqlite: encryption key must be written as id:base64")
+		}
+		if _, dup := ring.aeads[id]; dup {
+			return nil, fmt.Errorf("sqlite: encryption key %q is listed twice", id)
+		}
+		encoded = strings.TrimSpace(encoded)
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: encryption key %q: %w", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("sqlite: encryption key %q is %d bytes, want %d", id, len(key), keySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: encryption key %q: %w", id, err)
+		}
+		if ring.current == "" {
+			ring.current = id
+			derive := hmac.New(sha256.New, key)
+			derive.Write([]byte("financebot lookup key"))
+			ring.macKey = derive.Sum(nil)
+		}
+		ring.aeads[id] = aead
+		ring.encoded = append(ring.encoded, encoded)
+	}
+	if ring.current == "" {
+		return nil, errors.New("sqlite: no encryption key given")
+	}
+	return ring, nil
+}
+
+// Encoded returns each key as it was written, in base64, so logs can scrub them.
+func (k *Keyring) Encoded() []string {
+	return append([]string(nil), k.encoded...)
+}
+
+// lookupKey hashes value with a key only the keyring holds, so a stored lookup key
+// cannot be matched against guesses of the value without it.
+func (k *Keyring) lookupKey(value string) string {
+	mac := hmac.New(sha256.New, k.macKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal encrypts plain with the current key. aad binds the value to where it is stored,
+// so it cannot be copied into another column or another user's row.
+func (k *Keyring) seal(plain []byte, aad string) ([]byte, error) {
+	aead := k.aeads[k.current]
+	out := make([]byte, 0, 2+len(k.current)+aead.NonceSize()+len(plain)+aead.Overhead())
+	out = append(out, sealedVersion, byte(len(k.current)))
+	out = append(out, k.current...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("sqlite: encryption nonce: %w", err)
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plain, []byte(aad)), nil
+}
+
+// open decrypts a value written by seal.
+func (k *Keyring) open(sealed []byte, aad string) ([]byte, error) {
+	id, rest, err := sealedKeyID(sealed)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("sqlite: value is encrypted with unknown key %q", id)
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("sqlite: encrypted value is truncated")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: decrypt with key %q: %w", id, err)
+	}
+	return plain, nil
+}
+
+// sealedKeyID returns the ID of the key a value was sealed with and the bytes after it.
+func sealedKeyID(sealed []byte) (string, []byte, error) {
+	if len(sealed) < 2 || sealed[0] != sealedVersion || len(sealed) < 2+int(sealed[1]) {
+		return "", nil, errors.New("sqlite: malformed encrypted value")
+	}
+	end := 2 + int(sealed[1])
+	return string(sealed[2:end]), sealed[end:], nil
+}
+
+// Encryption selects the expense fields that are encrypted and the keys used. Encrypted
+// values are stored as BLOBs, which is how they are told apart from plaintext, so a
+// database can hold both while Reencrypt catches up with a change of settings.
+type Encryption struct {
+	Keys        *Keyring
+	Description bool
+	Category    bool
+	Amount      bool
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithEncryption encrypts expense fields as enc describes. The extraction cache, which
+// holds the same details, is encrypted along with the description. Filters on the
+// category and description and all aggregates then run over the decrypted rows rather
+// than in SQL.
+func WithEncryption(enc Encryption) Option {
+	return func(s *Store) {
+		s.enc = enc
+	}
+}
+
+// encrypted reports whether a keyring is configured, so stored values may be encrypted.
+func (s *Store) encrypted() bool {
+	return s.enc.Keys != nil
+}
+
+// fieldAAD scopes an expense field to its column and owner.
+func fieldAAD(field string, userID int64) string {
+	return "expenses." + field + ":" + strconv.FormatInt(userID, 10)
+}
+
+// sealText returns value as it should be stored: encrypted under aad when on, else
+// unchanged.
+func (s *Store) sealText(on bool, aad, value string) (any, error) {
+	if !on {
+		return value, nil
+	}
+	return s.enc.Keys.seal([]byte(value), aad)
+}
+
+// sealAmount is sealText for the amount, which is encrypted as its decimal text.
+func (s *Store) sealAmount(userID int64, amount float64) (any, error) {
+	if !s.enc.Amount {
+		return amount, nil
+	}
+	return s.enc.Keys.seal([]byte(strconv.FormatFloat(amount, 'g', -1, 64)), fieldAAD("amount", userID))
+}
+
+// sealItem encodes an expense's category, amount and description for storage.
+func (s *Store) sealItem(userID int64, category string, amount float64, description string) (any, any, any, error) {
+	sealedCategory, err := s.sealText(s.enc.Category, fieldAAD("category", userID), category)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sealedAmount, err := s.sealAmount(userID, amount)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sealedDescription, err := s.sealText(s.enc.Description, fieldAAD("description", userID), description)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sealedCategory, sealedAmount, sealedDescription, nil
+}
+
+// openText decodes a text field scanned into raw, decrypting it under aad if it was
+// stored encrypted.
+func (s *Store) openText(field, aad string, raw any) (string, error) {
+	switch v := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		if !s.encrypted() {
+			return "", fmt.Errorf("sqlite: %s is encrypted but no key is configured", field)
+		}
+		plain, err := s.enc.Keys.open(v, aad)
+		if err != nil {
+			return "", fmt.Errorf("sqlite: %s: %w", field, err)
+		}
+		return string(plain), nil
+	default:
+		return "", fmt.Errorf("sqlite: unexpected %s value of type %T", field, raw)
+	}
+}
+
+// openAmount decodes an amount scanned into raw.
+func (s *Store) openAmount(userID int64, raw any) (float64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case []byte:
+		text, err := s.openText("amount", fieldAAD("amount", userID), v)
+		if err != nil {
+			return 0, err
+		}
+		amount, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: amount: %w", err)
+		}
+		return amount, nil
+	default:
+		return 0, fmt.Errorf("sqlite: unexpected amount value of type %T", raw)
+	}
+}
+
+// checkKeys fails when the database holds values encrypted with a key that keys lacks,
+// or any encrypted values when keys is nil, since SQL aggregates would silently skip
+// them. It reads the distinct key IDs rather than decrypting anything.
+func checkKeys(ctx context.Context, db *sql.DB, keys *Keyring) error {
+	rows, err := db.QueryContext(ctx, `
+		WITH RECURSIVE
+			id_length(n, byte) AS (
+				SELECT 1, '01'
+				UNION ALL SELECT n + 1, printf('%02X', n + 1) FROM id_length WHERE n < 255
+			),
+			sealed(value) AS (
+				SELECT description FROM expenses WHERE typeof(description) = 'blob'
+				UNION ALL SELECT category FROM expenses WHERE typeof(category) = 'blob'
+				UNION ALL SELECT amount FROM expenses WHERE typeof(amount) = 'blob'
+				UNION ALL SELECT text FROM pending_expenses WHERE typeof(text) = 'blob'
+				UNION ALL SELECT last_error FROM pending_expenses WHERE typeof(last_error) = 'blob'
+				UNION ALL SELECT item FROM extraction_cache WHERE typeof(item) = 'blob'
+			)
+		SELECT DISTINCT CAST(substr(value, 3, n) AS TEXT)
+		FROM sealed JOIN id_length ON byte = hex(substr(value, 2, 1))`)
+	if err != nil {
+		return fmt.Errorf("sqlite: look for encryption keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("sqlite: look for encryption keys: %w", err)
+		}
+		if keys == nil {
+			return errors.New("sqlite: the database holds encrypted values; configure the encryption key to open it")
+		}
+		if _, ok := keys.aeads[id]; !ok {
+			return fmt.Errorf("sqlite: the database holds values encrypted with key %q, which is not configured", id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sqlite: look for encryption keys: %w", err)
+	}
+	return nil
+}
+
+// Reencrypt rewrites stored expenses to match the store's encryption settings: plaintext
+// fields that should be encrypted are encrypted, fields encrypted with an older key are
+// encrypted again with the current one, and encrypted fields that should no longer be
+// are decrypted. Queued messages are rewritten the same way, and the extraction cache is
+// cleared rather than rewritten. The database is vacuumed at the end, since the pages the
+// old values were on would otherwise keep them on disk. It returns how many rows changed
+// and is safe to run again after an interruption.
+func (s *Store) Reencrypt(ctx context.Context) (int, error) {
+	if !s.encrypted() {
+		return 0, errors.New("sqlite: reencrypt: no encryption key is configured")
+	}
+
+	var (
+		changed int
+		lastID  int64
+	)
+	for {
+		page, err := s.rawExpenses(ctx, lastID)
+		if err != nil {
+			return changed, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		lastID = page[len(page)-1].id
+		n, err := s.reencryptPage(ctx, page)
+		if err != nil {
+			return changed, err
+		}
+		changed += n
+	}
+
+	n, err := s.reencryptPending(ctx)
+	if err != nil {
+		return changed, err
+	}
+	changed += n
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM extraction_cache`); err != nil {
+		return changed, fmt.Errorf("sqlite: reencrypt: clear extraction cache: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return changed, fmt.Errorf("sqlite: reencrypt: vacuum: %w", err)
+	}
+	return changed, nil
+}
+
+// rawExpense is an expense row as stored, before decryption.
+type rawExpense struct {
+	id, userID                    int64
+	category, amount, description any
+}
+
+// rawExpenses reads the next page of expenses after afterID. The page is read in full
+// before it is rewritten, since the store has a single connection.
+func (s *Store) rawExpenses(ctx context.Context, afterID int64) ([]rawExpense, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, category, amount, description
+		FROM expenses
+		WHERE id > ?
+		ORDER BY id
+		LIMIT ?`, afterID, reencryptPage)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: reencrypt: query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var page []rawExpense
+	for rows.Next() {
+		var r rawExpense
+		if err := rows.Scan(&r.id, &r.userID, &r.category, &r.amount, &r.description); err != nil {
+			return nil, fmt.Errorf("sqlite: reencrypt: scan expense: %w", err)
+		}
+		page = append(page, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: reencrypt: expense rows: %w", err)
+	}
+	return page, nil
+}
+
+// reencryptPage rewrites the rows of page that are not in their current form, in one
+// transaction.
+func (s *Store) reencryptPage(ctx context.Context, page []rawExpense) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	changed := 0
+	for _, r := range page {
+		if s.current(s.enc.Category, r.category) && s.current(s.enc.Amount, r.amount) && s.current(s.enc.Description, r.description) {
+			continue
+		}
+		category, err := s.openText("category", fieldAAD("category", r.userID), r.category)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt expense %d: %w", r.id, err)
+		}
+		amount, err := s.openAmount(r.userID, r.amount)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt expense %d: %w", r.id, err)
+		}
+		description, err := s.openText("description", fieldAAD("description", r.userID), r.description)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt expense %d: %w", r.id, err)
+		}
+		sealedCategory, sealedAmount, sealedDescription, err := s.sealItem(r.userID, category, amount, description)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE expenses SET category = ?, amount = ?, description = ? WHERE id = ?`,
+			sealedCategory, sealedAmount, sealedDescription, r.id); err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt expense %d: %w", r.id, err)
+		}
+		changed++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: commit: %w", err)
+	}
+	return changed, nil
+}
+
+// current reports whether a stored value is already in the form the settings ask for:
+// encrypted with the current key when on, plaintext otherwise.
+func (s *Store) current(on bool, raw any) bool {
+	sealed, isSealed := raw.([]byte)
+	if !on || !isSealed {
+		return on == isSealed
+	}
+	id, _, err := sealedKeyID(sealed)
+	return err == nil && id == s.enc.Keys.current
+}
+
+// reencryptPending is reencryptPage for the retry queue, which is small enough to rewrite
+// in one transaction.
+func (s *Store) reencryptPending(ctx context.Context) (int, error) {
+	type rawPending struct {
+		id, userID      int64
+		text, lastError any
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, text, last_error FROM pending_expenses ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: query pending: %w", err)
+	}
+	var queue []rawPending
+	for rows.Next() {
+		var r rawPending
+		if err := rows.Scan(&r.id, &r.userID, &r.text, &r.lastError); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("sqlite: reencrypt: scan pending: %w", err)
+		}
+		queue = append(queue, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: pending rows: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	on := s.pendingEncrypted()
+	changed := 0
+	for _, r := range queue {
+		if s.current(on, r.text) && s.current(on, r.lastError) {
+			continue
+		}
+		text, err := s.openText("pending text", pendingAAD("text", r.userID), r.text)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt pending %d: %w", r.id, err)
+		}
+		lastError, err := s.openText("pending error", pendingAAD("last_error", r.userID), r.lastError)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt pending %d: %w", r.id, err)
+		}
+		sealedText, sealedError, err := s.sealPending(r.userID, text, lastError)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE pending_expenses SET text = ?, last_error = ? WHERE id = ?`,
+			sealedText, sealedError, r.id); err != nil {
+			return 0, fmt.Errorf("sqlite: reencrypt pending %d: %w", r.id, err)
+		}
+		changed++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite: reencrypt: commit: %w", err)
+	}
+	return changed, nil
+}
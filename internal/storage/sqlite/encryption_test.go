@@ -0,0 +1,340 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func testKey(id string, fill byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, keySize))
+}
+
+func mustKeyring(t *testing.T, raw string) *Keyring {
+	t.Helper()
+	keys, err := ParseKeyring(raw)
+	if err != nil {
+		t.Fatalf("ParseKeyring error: %v", err)
+	}
+	return keys
+}
+
+func TestParseKeyring(t *testing.T) {
+	keys := mustKeyring(t, "# rotated 2026-10\n"+testKey("new", 2)+"\n\n"+testKey("old", 1))
+	if keys.current != "new" || len(keys.aeads) != 2 {
+		t.Fatalf("expected the first key to be current, got %q of %d", keys.current, len(keys.aeads))
+	}
+	if got := keys.Encoded(); len(got) != 2 || got[0] != strings.TrimPrefix(testKey("new", 2), "new:") {
+		t.Fatalf("expected both keys in base64, current first, got %q", got)
+	}
+	for _, raw := range []string{
+		"",
+		"no-colon",
+		"short:" + base64.StdEncoding.EncodeToString([]byte("too short")),
+		"bad:not base64!",
+		testKey("dup", 1) + "," + testKey("dup", 2),
+	} {
+		if _, err := ParseKeyring(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+// seedExpenses saves the same expenses to store, with a tag and an account, so results
+// can be compared across stores.
+func seedExpenses(t *testing.T, store *Store) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := store.CreateAccount(ctx, storage.Account{UserID: 7, Name: "Visa", Type: storage.AccountCredit}); err != nil {
+		t.Fatalf("CreateAccount error: %v", err)
+	}
+	for _, item := range []expense.Item{
+		{Category: "Coffee", Amount: 3.5, Description: "Latte at the corner", Date: time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC), Tags: []string{"work"}},
+		{Category: "Health", Amount: 120, Description: "Therapy session", Date: time.Date(2026, 10, 6, 18, 0, 0, 0, time.UTC)},
+		{Category: "coffee", Amount: 4.25, Description: "Flat white", Date: time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)},
+		{Category: "", Amount: 9, Description: "Uncategorized", Date: time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC), Tags: []string{"work"}},
+		{Category: "Salary", Amount: 2000, Description: "October salary", Date: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), Kind: expense.KindIncome},
+	} {
+		if _, err := store.SaveExpense(ctx, 7, item); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Coffee", Amount: 50, Description: "Someone else's latte", Date: time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+}
+
+func TestEncryptedStoreMatchesPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := NewStore(filepath.Join(dir, "plain.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer plain.Close()
+	encryptedPath := filepath.Join(dir, "encrypted.db")
+	encrypted, err := NewStore(encryptedPath, WithEncryption(Encryption{
+		Keys: mustKeyring(t, testKey("k1", 1)), Description: true, Category: true, Amount: true,
+	}))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer encrypted.Close()
+	seedExpenses(t, plain)
+	seedExpenses(t, encrypted)
+	ctx := context.Background()
+
+	var blobs int
+	if err := encrypted.db.QueryRow(`
+		SELECT COUNT(*) FROM expenses
+		WHERE typeof(description) = 'blob' AND typeof(category) = 'blob' AND typeof(amount) = 'blob'`).Scan(&blobs); err != nil {
+		t.Fatalf("count encrypted rows: %v", err)
+	}
+	if blobs != 6 {
+		t.Fatalf("expected every row to be encrypted, got %d", blobs)
+	}
+	encrypted.Close()
+	raw, err := os.ReadFile(encryptedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("Therapy")) || bytes.Contains(raw, []byte("Health")) {
+		t.Fatal("expected no plaintext in the database file")
+	}
+	encrypted, err = NewStore(encryptedPath, WithEncryption(Encryption{
+		Keys: mustKeyring(t, testKey("k1", 1)), Description: true, Category: true, Amount: true,
+	}))
+	if err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+	defer encrypted.Close()
+
+	same := func(name string, query func(*Store) (any, error)) {
+		t.Helper()
+		want, err := query(plain)
+		if err != nil {
+			t.Fatalf("%s on plaintext store: %v", name, err)
+		}
+		got, err := query(encrypted)
+		if err != nil {
+			t.Fatalf("%s on encrypted store: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s differs:\nencrypted %+v\nplaintext %+v", name, got, want)
+		}
+	}
+	for _, filter := range []storage.ExportFilter{
+		{UserID: 7},
+		{UserID: 7, Category: "COFFEE"},
+		{UserID: 7, Text: "latte"},
+		{Tag: "work"},
+		{Since: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC), Kind: expense.KindExpense},
+	} {
+		same("Stats", func(s *Store) (any, error) { return s.Stats(ctx, filter) })
+		same("CategoryStats", func(s *Store) (any, error) { return s.CategoryStats(ctx, filter) })
+		same("BucketTotals", func(s *Store) (any, error) { return s.BucketTotals(ctx, filter, storage.BucketWeek) })
+		same("ListExpenses", func(s *Store) (any, error) { return s.ListExpenses(ctx, filter, 0, 0) })
+		same("ListExpenses page", func(s *Store) (any, error) { return s.ListExpenses(ctx, filter, 1, 1) })
+		same("ExportExpenses", func(s *Store) (any, error) {
+			var all []storage.Expense
+			err := s.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+				all = append(all, e)
+				return nil
+			})
+			return all, err
+		})
+	}
+	same("AccountBalances", func(s *Store) (any, error) {
+		return s.AccountBalances(ctx, 7, time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC))
+	})
+	same("RecentExpenses", func(s *Store) (any, error) { return s.RecentExpenses(ctx, 7, time.Time{}) })
+	same("GetExpense", func(s *Store) (any, error) { return s.GetExpense(ctx, 7, 2) })
+
+	if err := encrypted.UpdateExpense(ctx, 7, 2, expense.Item{Category: "Health", Amount: 130, Description: "Therapy, longer session"}); err != nil {
+		t.Fatalf("UpdateExpense error: %v", err)
+	}
+	e, err := encrypted.GetExpense(ctx, 7, 2)
+	if err != nil || e.Amount != 130 || e.Description != "Therapy, longer session" {
+		t.Fatalf("expected the update to round-trip, got %+v (%v)", e, err)
+	}
+}
+
+func TestEncryptedValuesAreBoundToTheirRow(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"), WithEncryption(Encryption{
+		Keys: mustKeyring(t, testKey("k1", 1)), Description: true,
+	}))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	seedExpenses(t, store)
+
+	// Moving user 8's description onto a row of user 7 must not decrypt.
+	if _, err := store.db.Exec(`UPDATE expenses SET description = (SELECT description FROM expenses WHERE user_id = 8) WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetExpense(context.Background(), 7, 1); err == nil {
+		t.Fatal("expected a value copied from another user's row to fail to decrypt")
+	}
+}
+
+func TestReencryptMigratesRotatesAndDecrypts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "finance.db")
+	ctx := context.Background()
+	open := func(enc Encryption) *Store {
+		t.Helper()
+		store, err := NewStore(path, WithEncryption(enc))
+		if err != nil {
+			t.Fatalf("NewStore error: %v", err)
+		}
+		return store
+	}
+	countBlobs := func(store *Store) int {
+		t.Helper()
+		var n int
+		if err := store.db.QueryRow(`SELECT COUNT(*) FROM expenses WHERE typeof(description) = 'blob'`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	store := open(Encryption{})
+	seedExpenses(t, store)
+	want, err := store.Stats(ctx, storage.ExportFilter{UserID: 7})
+	if err != nil {
+		t.Fatalf("Stats error: %v", err)
+	}
+	if _, err := store.Reencrypt(ctx); err == nil {
+		t.Fatal("expected Reencrypt without keys to fail")
+	}
+	if err := store.PutCachedExtraction(ctx, "k", expense.Item{Description: "Cached before encryption"}, time.Now()); err != nil {
+		t.Fatalf("PutCachedExtraction error: %v", err)
+	}
+	store.Close()
+
+	old := testKey("old", 1)
+	store = open(Encryption{Keys: mustKeyring(t, old), Description: true, Amount: true})
+	if n, err := store.Reencrypt(ctx); err != nil || n != 6 {
+		t.Fatalf("expected 6 rows encrypted, got %d (%v)", n, err)
+	}
+	if n, err := store.Reencrypt(ctx); err != nil || n != 0 {
+		t.Fatalf("expected a second run to change nothing, got %d (%v)", n, err)
+	}
+	if _, ok, _ := store.GetCachedExtraction(ctx, "k", time.Time{}); ok {
+		t.Fatal("expected the extraction cache to be cleared")
+	}
+	if data, err := os.ReadFile(path); err != nil || bytes.Contains(data, []byte("Therapy session")) || bytes.Contains(data, []byte("Cached before encryption")) {
+		t.Fatalf("expected no plaintext left in the database file (%v)", err)
+	}
+	if got, err := store.Stats(ctx, storage.ExportFilter{UserID: 7}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the same stats after encrypting, got %+v (%v)", got, err)
+	}
+	store.Close()
+
+	if _, err := NewStore(path); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Fatalf("expected opening without keys to fail, got %v", err)
+	}
+
+	if _, err := NewStore(path, WithEncryption(Encryption{Keys: mustKeyring(t, testKey("new", 2)), Description: true})); err == nil || !strings.Contains(err.Error(), `key "old"`) {
+		t.Fatalf("expected opening without the old key to fail, got %v", err)
+	}
+
+	rotated := mustKeyring(t, testKey("new", 2)+","+old)
+	store = open(Encryption{Keys: rotated, Description: true, Amount: true})
+	if n, err := store.Reencrypt(ctx); err != nil || n != 6 {
+		t.Fatalf("expected 6 rows re-encrypted, got %d (%v)", n, err)
+	}
+	store.Close()
+	store = open(Encryption{Keys: mustKeyring(t, testKey("new", 2)), Description: true, Amount: true})
+	if got, err := store.Stats(ctx, storage.ExportFilter{UserID: 7}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the old key to be retired, got %+v (%v)", got, err)
+	}
+	store.Close()
+
+	store = open(Encryption{Keys: mustKeyring(t, testKey("new", 2))})
+	if n, err := store.Reencrypt(ctx); err != nil || n != 6 || countBlobs(store) != 0 {
+		t.Fatalf("expected every row decrypted, got %d (%v)", n, err)
+	}
+	store.Close()
+	store = open(Encryption{})
+	defer store.Close()
+	if got, err := store.Stats(ctx, storage.ExportFilter{UserID: 7}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the plaintext stats back, got %+v (%v)", got, err)
+	}
+}
+
+func TestEncryptedExtractionCache(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"), WithEncryption(Encryption{
+		Keys: mustKeyring(t, testKey("k1", 1)), Description: true,
+	}))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	item := expense.Item{Category: "Health", Amount: 120, Description: "Therapy session"}
+	if err := store.PutCachedExtraction(ctx, "k", item, time.Now()); err != nil {
+		t.Fatalf("PutCachedExtraction error: %v", err)
+	}
+	var kind, key string
+	if err := store.db.QueryRow(`SELECT typeof(item), key FROM extraction_cache`).Scan(&kind, &key); err != nil || kind != "blob" {
+		t.Fatalf("expected an encrypted cache entry, got %q (%v)", kind, err)
+	}
+	if key == "k" || len(key) != 64 {
+		t.Fatalf("expected the cache key to be hashed with a secret, got %q", key)
+	}
+	got, ok, err := store.GetCachedExtraction(ctx, "k", time.Time{})
+	if err != nil || !ok || !reflect.DeepEqual(got, item) {
+		t.Fatalf("expected the cached item back, got %+v %v (%v)", got, ok, err)
+	}
+}
+
+func TestEncryptedPendingQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "finance.db")
+	ctx := context.Background()
+	plain, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	if _, err := plain.EnqueuePending(ctx, storage.PendingExpense{ChatID: 1, UserID: 7, Username: "ana", Text: "therapy 120", LastError: "timeout"}); err != nil {
+		t.Fatalf("EnqueuePending error: %v", err)
+	}
+	plain.Close()
+
+	store, err := NewStore(path, WithEncryption(Encryption{Keys: mustKeyring(t, testKey("k1", 1)), Amount: true}))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	if n, err := store.Reencrypt(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the queued message encrypted, got %d (%v)", n, err)
+	}
+	id, err := store.EnqueuePending(ctx, storage.PendingExpense{ChatID: 1, UserID: 7, Username: "ana", Text: "dentist 80"})
+	if err != nil {
+		t.Fatalf("EnqueuePending error: %v", err)
+	}
+	if err := store.ReschedulePending(ctx, id, time.Now().Add(-time.Minute), "rate limited"); err != nil {
+		t.Fatalf("ReschedulePending error: %v", err)
+	}
+
+	var plaintext int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM pending_expenses WHERE typeof(text) != 'blob' OR typeof(last_error) != 'blob'`).Scan(&plaintext); err != nil || plaintext != 0 {
+		t.Fatalf("expected every queued text and error encrypted, got %d plaintext (%v)", plaintext, err)
+	}
+	queue, err := store.ListPending(ctx, 1)
+	if err != nil || len(queue) != 2 {
+		t.Fatalf("expected two queued messages, got %+v (%v)", queue, err)
+	}
+	if queue[0].Text != "therapy 120" || queue[0].LastError != "timeout" || queue[1].Text != "dentist 80" || queue[1].LastError != "rate limited" {
+		t.Fatalf("expected the queue to round-trip, got %+v", queue)
+	}
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.GoalStore = (*Store)(nil)
+
+// CreateGoal adds a savings goal.
+func (s *Store) CreateGoal(ctx context.Context, goal storage.Goal) (int64, error) {
+	createdAt := goal.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	var deadline any
+	if !goal.Deadline.IsZero() {
+		deadline = goal.Deadline.UTC()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO goals (user_id, name, target, deadline, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, name) DO NOTHING`,
+		goal.UserID, goal.Name, goal.Target, deadline, createdAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: insert goal: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("sqlite: insert goal: %w", err)
+	} else if n == 0 {
+		return 0, storage.ErrExists
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: goal id: %w", err)
+	}
+	return id, nil
+}
+
+// ContributeToGoal logs an amount saved toward one of the user's goals.
+func (s *Store) ContributeToGoal(ctx context.Context, userID int64, name string, amount float64, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO goal_contributions (goal_id, amount, created_at)
+		SELECT id, ?, ? FROM goals WHERE user_id = ? AND name = ?`,
+		amount, at.UTC(), userID, name)
+	if err != nil {
+		return fmt.Errorf("sqlite: insert goal contribution: %w", err)
+	}
+	return expectAffected(res, "insert goal contribution")
+}
+
+// DeleteGoal removes one of the user's goals together with its contributions.
+func (s *Store) DeleteGoal(ctx context.Context, userID int64, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin delete goal: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM goals WHERE user_id = ? AND name = ?`, userID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("sqlite: look up goal: %w", err)
+	}
+	// Foreign keys are not enforced by default, so the cascade is done by hand.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goal_contributions WHERE goal_id = ?`, id); err != nil {
+		return fmt.Errorf("sqlite: delete goal contributions: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goals WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("sqlite: delete goal: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit delete goal: %w", err)
+	}
+	return nil
+}
+
+// GoalProgress returns a user's goals with their savings, oldest goal first.
+func (s *Store) GoalProgress(ctx context.Context, userID int64) ([]storage.GoalProgress, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT g.id, g.user_id, g.name, g.target, g.deadline, g.created_at,
+			COALESCE(SUM(c.amount), 0), COUNT(c.id)
+		FROM goals g
+		LEFT JOIN goal_contributions c ON c.goal_id = g.id
+		WHERE g.user_id = ?
+		GROUP BY g.id
+		ORDER BY g.created_at, g.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []storage.GoalProgress
+	for rows.Next() {
+		var (
+			g        storage.GoalProgress
+			deadline sql.NullTime
+		)
+		if err := rows.Scan(&g.ID, &g.UserID, &g.Name, &g.Target, &deadline, &g.CreatedAt, &g.Saved, &g.Contributions); err != nil {
+			return nil, fmt.Errorf("sqlite: scan goal: %w", err)
+		}
+		g.Deadline = deadline.Time
+		goals = append(goals, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: goal rows: %w", err)
+	}
+	return goals, nil
+}
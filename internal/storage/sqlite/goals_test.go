@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStoreGoals(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	deadline := time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC)
+	if _, err := store.CreateGoal(ctx, storage.Goal{UserID: 7, Name: "Japan trip", Target: 3000, Deadline: deadline}); err != nil {
+		t.Fatalf("CreateGoal error: %v", err)
+	}
+	if _, err := store.CreateGoal(ctx, storage.Goal{UserID: 7, Name: "Laptop", Target: 1500}); err != nil {
+		t.Fatalf("CreateGoal error: %v", err)
+	}
+	if _, err := store.CreateGoal(ctx, storage.Goal{UserID: 7, Name: "japan TRIP", Target: 10}); !errors.Is(err, storage.ErrExists) {
+		t.Fatalf("expected ErrExists for a duplicate name, got %v", err)
+	}
+
+	now := time.Now()
+	for _, amount := range []float64{500, 250, -50} {
+		if err := store.ContributeToGoal(ctx, 7, "JAPAN trip", amount, now); err != nil {
+			t.Fatalf("ContributeToGoal error: %v", err)
+		}
+	}
+	if err := store.ContributeToGoal(ctx, 8, "Japan trip", 100, now); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's goal, got %v", err)
+	}
+
+	goals, err := store.GoalProgress(ctx, 7)
+	if err != nil {
+		t.Fatalf("GoalProgress error: %v", err)
+	}
+	if len(goals) != 2 || goals[0].Name != "Japan trip" || goals[0].Saved != 700 || goals[0].Contributions != 3 || !goals[0].Deadline.Equal(deadline) {
+		t.Fatalf("unexpected goals %#v", goals)
+	}
+	if goals[1].Saved != 0 || !goals[1].Deadline.IsZero() || goals[1].CreatedAt.IsZero() {
+		t.Fatalf("unexpected empty goal %#v", goals[1])
+	}
+
+	if err := store.DeleteGoal(ctx, 7, "japan trip"); err != nil {
+		t.Fatalf("DeleteGoal error: %v", err)
+	}
+	if err := store.DeleteGoal(ctx, 7, "japan trip"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	var orphans int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM goal_contributions`).Scan(&orphans); err != nil || orphans != 0 {
+		t.Fatalf("expected contributions to be deleted with the goal, got %d (%v)", orphans, err)
+	}
+}
@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.PendingQueue = (*Store)(nil)
+
+const pendingColumns = `id, chat_id, user_id, username, text, attempts, last_error, next_attempt_at, created_at`
+
+// EnqueuePending persists a message for a later retry and returns its queue ID.
+func (s *Store) EnqueuePending(ctx context.Context, pending storage.PendingExpense) (int64, error) {
+	createdAt := pending.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	text, lastError, err := s.sealPending(pending.UserID, pending.Text, pending.LastError)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO pending_expenses (chat_id, user_id, username, text, attempts, last_error, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		pending.ChatID, pending.UserID, pending.Username, text, pending.Attempts,
+		lastError, nullTime(pending.NextAttempt), createdAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: insert pending: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: pending id: %w", err)
+	}
+	return id, nil
+}
+
+// DuePending returns queued messages that are ready to be retried.
+func (s *Store) DuePending(ctx context.Context, now time.Time, limit int) ([]storage.PendingExpense, error) {
+	return s.queryPending(ctx, `
+		SELECT `+pendingColumns+`
+		FROM pending_expenses
+		WHERE next_attempt_at IS NOT NULL AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?`, now.UTC(), limit)
+}
+
+// ReschedulePending bumps the attempt counter and sets the next retry time.
+func (s *Store) ReschedulePending(ctx context.Context, id int64, next time.Time, lastError string) error {
+	var stored any = lastError
+	if s.pendingEncrypted() {
+		// The error is bound to the message's owner, like its text.
+		var userID int64
+		err := s.db.QueryRowContext(ctx, `SELECT user_id FROM pending_expenses WHERE id = ?`, id).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("sqlite: reschedule pending: %w", err)
+		}
+		if stored, err = s.enc.Keys.seal([]byte(lastError), pendingAAD("last_error", userID)); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE pending_expenses
+		SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+		WHERE id = ?`, stored, nullTime(next), id); err != nil {
+		return fmt.Errorf("sqlite: reschedule pending: %w", err)
+	}
+	return nil
+}
+
+// DeletePending removes a message from the queue.
+func (s *Store) DeletePending(ctx context.Context, id int64) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM pending_expenses WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("sqlite: delete pending: %w", err)
+	}
+	return nil
+}
+
+// ListPending returns every queued message for a chat, oldest first.
+func (s *Store) ListPending(ctx context.Context, chatID int64) ([]storage.PendingExpense, error) {
+	return s.queryPending(ctx, `
+		SELECT `+pendingColumns+`
+		FROM pending_expenses
+		WHERE chat_id = ?
+		ORDER BY id`, chatID)
+}
+
+func (s *Store) queryPending(ctx context.Context, query string, args ...any) ([]storage.PendingExpense, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query pending: %w", err)
+	}
+	defer rows.Close()
+
+	var items []storage.PendingExpense
+	for rows.Next() {
+		var (
+			p               storage.PendingExpense
+			text, lastError any
+			next            sql.NullTime
+		)
+		if err := rows.Scan(&p.ID, &p.ChatID, &p.UserID, &p.Username, &text, &p.Attempts, &lastError, &next, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: scan pending: %w", err)
+		}
+		if p.Text, err = s.openText("pending text", pendingAAD("text", p.UserID), text); err != nil {
+			return nil, err
+		}
+		if p.LastError, err = s.openText("pending error", pendingAAD("last_error", p.UserID), lastError); err != nil {
+			return nil, err
+		}
+		if next.Valid {
+			p.NextAttempt = next.Time
+		}
+		items = append(items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: pending rows: %w", err)
+	}
+	return items, nil
+}
+
+// pendingEncrypted reports whether queued messages are encrypted. A message holds every
+// detail of the expense it becomes, so it is whenever any expense field is.
+func (s *Store) pendingEncrypted() bool {
+	return s.enc.Description || s.enc.Category || s.enc.Amount
+}
+
+// sealPending encodes a queued message's text and last error for storage.
+func (s *Store) sealPending(userID int64, text, lastError string) (any, any, error) {
+	on := s.pendingEncrypted()
+	sealedText, err := s.sealText(on, pendingAAD("text", userID), text)
+	if err != nil {
+		return nil, nil, err
+	}
+	sealedError, err := s.sealText(on, pendingAAD("last_error", userID), lastError)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sealedText, sealedError, nil
+}
+
+// pendingAAD scopes a queued message's field to its column and owner.
+func pendingAAD(field string, userID int64) string {
+	return "pending_expenses." + field + ":" + strconv.FormatInt(userID, 10)
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStorePendingQueue(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	dueID, err := store.EnqueuePending(ctx, storage.PendingExpense{
+		ChatID: 1, UserID: 7, Username: "iamoxyrus", Text: "coffee 3.5",
+		Attempts: 1, LastError: "busy", NextAttempt: now.Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("EnqueuePending error: %v", err)
+	}
+	if _, err := store.EnqueuePending(ctx, storage.PendingExpense{
+		ChatID: 1, UserID: 7, Username: "iamoxyrus", Text: "taxi 20",
+		Attempts: 1, NextAttempt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("EnqueuePending error: %v", err)
+	}
+
+	due, err := store.DuePending(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("DuePending error: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != dueID || due[0].Text != "coffee 3.5" || due[0].LastError != "busy" {
+		t.Fatalf("unexpected due entries %#v", due)
+	}
+
+	if err := store.ReschedulePending(ctx, dueID, time.Time{}, "gave up"); err != nil {
+		t.Fatalf("ReschedulePending error: %v", err)
+	}
+	if due, _ := store.DuePending(ctx, now.Add(24*time.Hour), 10); len(due) != 1 || due[0].Text != "taxi 20" {
+		t.Fatalf("expected parked entry to never be due, got %#v", due)
+	}
+
+	all, err := store.ListPending(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListPending error: %v", err)
+	}
+	if len(all) != 2 || all[0].Attempts != 2 || !all[0].NextAttempt.IsZero() || all[0].LastError != "gave up" {
+		t.Fatalf("unexpected pending entries %#v", all)
+	}
+
+	if err := store.DeletePending(ctx, dueID); err != nil {
+		t.Fatalf("DeletePending error: %v", err)
+	}
+	if all, _ := store.ListPending(ctx, 1); len(all) != 1 {
+		t.Fatalf("expected one entry left, got %#v", all)
+	}
+}
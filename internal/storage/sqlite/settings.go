@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.SettingsStore = (*Store)(nil)
+
+// UserSettings returns the user's saved settings, or the defaults.
+func (s *Store) UserSettings(ctx context.Context, userID int64) (locale.Settings, error) {
+	var (
+		timezone  string
+		settings  locale.Settings
+		weekStart int
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT language, timezone, currency, decimal_comma, week_start FROM user_settings WHERE user_id = ?`, userID).
+		Scan(&settings.Language, &timezone, &settings.Currency, &settings.DecimalComma, &weekStart)
+	if errors.Is(err, sql.ErrNoRows) {
+		return locale.Default(), nil
+	}
+	if err != nil {
+		return locale.Settings{}, fmt.Errorf("sqlite: query settings: %w", err)
+	}
+	if settings.Location, err = locale.LoadLocation(timezone); err != nil {
+		return locale.Settings{}, fmt.Errorf("sqlite: settings: %w", err)
+	}
+	settings.WeekStart = time.Weekday(weekStart)
+	return settings, nil
+}
+
+// SaveUserSettings creates or replaces the user's settings.
+func (s *Store) SaveUserSettings(ctx context.Context, userID int64, settings locale.Settings) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_settings (user_id, language, timezone, currency, decimal_comma, week_start) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			language = excluded.language,
+			timezone = excluded.timezone,
+			currency = excluded.currency,
+			decimal_comma = excluded.decimal_comma,
+			week_start = excluded.week_start`,
+		userID, string(settings.Lang()), settings.Loc().String(), settings.Currency, settings.DecimalComma, int(settings.WeekStart)); err != nil {
+		return fmt.Errorf("sqlite: save settings: %w", err)
+	}
+	return nil
+}
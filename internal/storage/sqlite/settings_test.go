@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/locale"
+)
+
+func TestSQLiteStoreUserSettings(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	got, err := store.UserSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("UserSettings error: %v", err)
+	}
+	if got != locale.Default() {
+		t.Fatalf("expected defaults for a new user, got %#v", got)
+	}
+
+	bogota, err := locale.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatalf("LoadLocation error: %v", err)
+	}
+	want := locale.Settings{Language: locale.Spanish, Location: bogota, Currency: "€", DecimalComma: true, WeekStart: time.Sunday}
+	for range 2 {
+		if err := store.SaveUserSettings(ctx, 7, want); err != nil {
+			t.Fatalf("SaveUserSettings error: %v", err)
+		}
+	}
+
+	got, err = store.UserSettings(ctx, 7)
+	if err != nil {
+		t.Fatalf("UserSettings error: %v", err)
+	}
+	if got.Language != locale.Spanish || got.Loc().String() != "America/Bogota" || got.Currency != "€" || !got.DecimalComma || got.WeekStart != time.Sunday {
+		t.Fatalf("unexpected settings %#v", got)
+	}
+	if other, _ := store.UserSettings(ctx, 8); other != locale.Default() {
+		t.Fatalf("settings leaked to another user: %#v", other)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Oxyrus/financebot/internal/expense"
@@ -17,7 +18,7 @@ import (
 
 const (
 	defaultMaxOpenConns = 1
-	expenseInsert       = `INSERT INTO expenses (category, amount, description, created_at) VALUES (?, ?, ?, ?)`
+	expenseInsert       = `INSERT INTO expenses (user_id, category, amount, description, created_at, sign, account_id) VALUES (?, ?, ?, ?, ?, ?, ?)`
 	expenseSchema       = `CREATE TABLE IF NOT EXISTS expenses (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		category TEXT NOT NULL,
@@ -25,19 +26,134 @@ const (
 		description TEXT NOT NULL,
 		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);`
+	pendingSchema = `CREATE TABLE IF NOT EXISTS pending_expenses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL,
+		text TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		next_attempt_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_pending_next_attempt ON pending_expenses (next_attempt_at);`
+	expenseUserSchema = `ALTER TABLE expenses ADD COLUMN user_id INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_expenses_user_created ON expenses (user_id, created_at);`
+	extractionCacheSchema = `CREATE TABLE IF NOT EXISTS extraction_cache (
+		key TEXT PRIMARY KEY,
+		item TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_extraction_cache_created ON extraction_cache (created_at);`
+	usageSchema = `CREATE TABLE IF NOT EXISTS llm_usage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL,
+		prompt_tokens INTEGER NOT NULL,
+		completion_tokens INTEGER NOT NULL,
+		latency_ms INTEGER NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_llm_usage_created ON llm_usage (created_at);`
+	budgetSchema = `CREATE TABLE IF NOT EXISTS budgets (
+		user_id INTEGER NOT NULL,
+		category TEXT NOT NULL,
+		monthly_limit REAL NOT NULL,
+		PRIMARY KEY (user_id, category)
+	);`
+	apiTokenSchema = `CREATE TABLE IF NOT EXISTS api_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		username TEXT NOT NULL DEFAULT '',
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens (user_id);`
+	expenseTagSchema = `CREATE TABLE IF NOT EXISTS expense_tags (
+		expense_id INTEGER NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (expense_id, tag)
+	);
+	CREATE INDEX IF NOT EXISTS idx_expense_tags_tag ON expense_tags (tag, expense_id);`
+	// incomeSchema records the direction of each entry: -1 for expenses, +1 for income.
+	incomeSchema = `ALTER TABLE expenses ADD COLUMN sign INTEGER NOT NULL DEFAULT -1 CHECK (sign IN (-1, 1));`
+	// accountSchema adds payment accounts; expenses recorded before it have no account.
+	accountSchema = `CREATE TABLE IF NOT EXISTS accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL COLLATE NOCASE,
+		type TEXT NOT NULL,
+		opening_balance REAL NOT NULL DEFAULT 0,
+		is_default INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (user_id, name)
+	);
+	ALTER TABLE expenses ADD COLUMN account_id INTEGER REFERENCES accounts (id);
+	CREATE INDEX IF NOT EXISTS idx_expenses_account ON expenses (account_id, created_at);`
+	goalSchema = `CREATE TABLE IF NOT EXISTS goals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL COLLATE NOCASE,
+		target REAL NOT NULL,
+		deadline TIMESTAMP,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (user_id, name)
+	);
+	CREATE TABLE IF NOT EXISTS goal_contributions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		goal_id INTEGER NOT NULL REFERENCES goals (id) ON DELETE CASCADE,
+		amount REAL NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_goal_contributions_goal ON goal_contributions (goal_id, created_at);`
+	// settingsSchema stores the IANA time zone name and the time.Weekday the week starts on.
+	settingsSchema = `CREATE TABLE IF NOT EXISTS user_settings (
+		user_id INTEGER PRIMARY KEY,
+		timezone TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		decimal_comma INTEGER NOT NULL DEFAULT 0,
+		week_start INTEGER NOT NULL DEFAULT 1
+	);`
+	// languageSchema adds the reply language; users who chose none keep English.
+	languageSchema = `ALTER TABLE user_settings ADD COLUMN language TEXT NOT NULL DEFAULT 'en';`
+	// expenseColumns selects an expense for scanExpense, with its tags space-separated.
+	expenseColumns = `id, user_id, category, amount, description, created_at, sign,
+		(SELECT group_concat(tag, ' ') FROM (SELECT tag FROM expense_tags WHERE expense_id = expenses.id ORDER BY tag)),
+		(SELECT name FROM accounts WHERE accounts.id = expenses.account_id)`
 )
 
+// migrations are applied in order; the database's user_version records how many have run.
+// Append new schema changes to the end and never edit a migration that has shipped.
+var migrations = []string{
+	expenseSchema,
+	pendingSchema,
+	expenseUserSchema,
+	extractionCacheSchema,
+	usageSchema,
+	budgetSchema,
+	apiTokenSchema,
+	expenseTagSchema,
+	incomeSchema,
+	accountSchema,
+	goalSchema,
+	settingsSchema,
+	languageSchema,
+}
+
 // Store persists expenses in a local SQLite database file.
 type Store struct {
 	db           *sql.DB
 	insertStmt   *sql.Stmt
 	databasePath string
+	enc          Encryption
 }
 
 var _ storage.ExpenseStore = (*Store)(nil)
 
 // NewStore opens (or creates) the SQLite database at the provided path.
-func NewStore(databasePath string) (*Store, error) {
+func NewStore(databasePath string, opts ...Option) (*Store, error) {
 	if databasePath == "" {
 		return nil, errors.New("sqlite: database path is required")
 	}
@@ -60,26 +176,363 @@ func NewStore(databasePath string) (*Store, error) {
 		return nil, err
 	}
 
-	stmt, err := db.Prepare(expenseInsert)
+	s := &Store{db: db, databasePath: databasePath}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := checkKeys(context.Background(), db, s.enc.Keys); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s.insertStmt, err = db.Prepare(expenseInsert)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("sqlite: prepare insert: %w", err)
 	}
+	return s, nil
+}
+
+// SaveExpense writes a new expense row to the database and returns its ID.
+func (s *Store) SaveExpense(ctx context.Context, userID int64, item expense.Item) (int64, error) {
+	if item.Description == "" {
+		return 0, fmt.Errorf("sqlite: %w: description cannot be empty", storage.ErrInvalidExpense)
+	}
+	createdAt := time.Now().UTC()
+	if !item.Date.IsZero() {
+		createdAt = item.Date.UTC()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: begin insert expense: %w", err)
+	}
+	defer tx.Rollback()
+
+	accountID, err := resolveAccount(ctx, tx, userID, item.Account)
+	if err != nil {
+		return 0, err
+	}
+	category, amount, description, err := s.sealItem(userID, item.Category, item.Amount, item.Description)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.StmtContext(ctx, s.insertStmt).ExecContext(ctx, userID, category, amount, description, createdAt, item.Kind.Sign(), accountID)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: insert expense: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: expense id: %w", err)
+	}
+	if err := insertTags(ctx, tx, id, item.Tags); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite: commit expense: %w", err)
+	}
+	return id, nil
+}
+
+// RecentExpenses lists a user's expenses recorded since the provided time, newest first.
+func (s *Store) RecentExpenses(ctx context.Context, userID int64, since time.Time) ([]storage.Expense, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+expenseColumns+`
+		FROM expenses
+		WHERE user_id = ? AND created_at >= ?
+		ORDER BY created_at DESC, id DESC`, userID, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query recent expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var recent []storage.Expense
+	for rows.Next() {
+		e, err := s.scanExpense(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: scan recent expense: %w", err)
+		}
+		recent = append(recent, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: recent expense rows: %w", err)
+	}
+	return recent, nil
+}
+
+// ExportExpenses streams the expenses matching filter to fn row by row, oldest first.
+func (s *Store) ExportExpenses(ctx context.Context, filter storage.ExportFilter, fn func(storage.Expense) error) error {
+	return s.eachExpense(ctx, filter, "created_at, id", fn)
+}
+
+// eachExpense streams the expenses matching filter to fn in the given order.
+func (s *Store) eachExpense(ctx context.Context, filter storage.ExportFilter, order string, fn func(storage.Expense) error) error {
+	where, args, matches := s.expenseFilter(filter)
+	query := `SELECT ` + expenseColumns + ` FROM expenses WHERE ` + where + ` ORDER BY ` + order
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("sqlite: query export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := s.scanExpense(rows)
+		if err != nil {
+			return fmt.Errorf("sqlite: scan export: %w", err)
+		}
+		if matches != nil && !matches(e) {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sqlite: export rows: %w", err)
+	}
+	return nil
+}
+
+// errPageFull stops eachExpense once a page has been filled.
+var errPageFull = errors.New("sqlite: page full")
+
+// ListExpenses returns one page of the expenses matching filter, newest first.
+func (s *Store) ListExpenses(ctx context.Context, filter storage.ExportFilter, limit, offset int) ([]storage.Expense, error) {
+	if _, _, matches := s.expenseFilter(filter); matches != nil {
+		return s.listDecrypted(ctx, filter, limit, offset)
+	}
+	where, args := filterClause(filter)
+	if limit <= 0 {
+		limit = -1 // SQLite treats a negative LIMIT as unbounded.
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+expenseColumns+`
+		FROM expenses
+		WHERE `+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var page []storage.Expense
+	for rows.Next() {
+		e, err := s.scanExpense(rows)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: scan expense: %w", err)
+		}
+		page = append(page, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: expense rows: %w", err)
+	}
+	return page, nil
+}
+
+// listDecrypted is ListExpenses for filters that are checked after decryption, where
+// SQL cannot apply the limit and offset.
+func (s *Store) listDecrypted(ctx context.Context, filter storage.ExportFilter, limit, offset int) ([]storage.Expense, error) {
+	var page []storage.Expense
+	err := s.eachExpense(ctx, filter, "created_at DESC, id DESC", func(e storage.Expense) error {
+		if offset > 0 {
+			offset--
+			return nil
+		}
+		page = append(page, e)
+		if limit > 0 && len(page) == limit {
+			return errPageFull
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errPageFull) {
+		return nil, err
+	}
+	return page, nil
+}
 
-	return &Store{
-		db:           db,
-		insertStmt:   stmt,
-		databasePath: databasePath,
-	}, nil
+// GetExpense returns one of the user's expenses.
+func (s *Store) GetExpense(ctx context.Context, userID, id int64) (storage.Expense, error) {
+	e, err := s.scanExpense(s.db.QueryRowContext(ctx, `
+		SELECT `+expenseColumns+`
+		FROM expenses
+		WHERE id = ? AND user_id = ?`, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Expense{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return storage.Expense{}, fmt.Errorf("sqlite: get expense: %w", err)
+	}
+	return e, nil
 }
 
-// SaveExpense writes a new expense row to the database.
-func (s *Store) SaveExpense(ctx context.Context, item expense.Item) error {
+// UpdateExpense replaces the fields of one of the user's expenses; a zero Date keeps the
+// original timestamp, an empty Account keeps the original account and the tags are left
+// alone.
+func (s *Store) UpdateExpense(ctx context.Context, userID, id int64, item expense.Item) error {
 	if item.Description == "" {
-		return errors.New("sqlite: expense description cannot be empty")
+		return fmt.Errorf("sqlite: %w: description cannot be empty", storage.ErrInvalidExpense)
+	}
+	var createdAt any
+	if !item.Date.IsZero() {
+		createdAt = item.Date.UTC()
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin update expense: %w", err)
+	}
+	defer tx.Rollback()
+
+	var accountID sql.NullInt64
+	if item.Account != "" {
+		if accountID, err = resolveAccount(ctx, tx, userID, item.Account); err != nil {
+			return err
+		}
+	}
+	category, amount, description, err := s.sealItem(userID, item.Category, item.Amount, item.Description)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE expenses
+		SET category = ?, amount = ?, description = ?, created_at = COALESCE(?, created_at), sign = ?,
+			account_id = COALESCE(?, account_id)
+		WHERE id = ? AND user_id = ?`,
+		category, amount, description, createdAt, item.Kind.Sign(), accountID, id, userID)
+	if err != nil {
+		return fmt.Errorf("sqlite: update expense: %w", err)
 	}
-	if _, err := s.insertStmt.ExecContext(ctx, item.Category, item.Amount, item.Description, time.Now().UTC()); err != nil {
-		return fmt.Errorf("sqlite: insert expense: %w", err)
+	if err := expectAffected(res, "update expense"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit update expense: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpense removes one of the user's expenses together with its tags.
+func (s *Store) DeleteExpense(ctx context.Context, userID, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin delete expense: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM expenses WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("sqlite: delete expense: %w", err)
+	}
+	if err := expectAffected(res, "delete expense"); err != nil {
+		return err
+	}
+	// Foreign keys are not enforced by default, so the cascade is done by hand.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM expense_tags WHERE expense_id = ?`, id); err != nil {
+		return fmt.Errorf("sqlite: delete expense tags: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit delete expense: %w", err)
+	}
+	return nil
+}
+
+// scanExpense reads a row selected with expenseColumns, decrypting encrypted fields.
+func (s *Store) scanExpense(row interface{ Scan(...any) error }) (storage.Expense, error) {
+	var (
+		e                             storage.Expense
+		category, amount, description any
+		sign                          int
+		tags                          sql.NullString
+		account                       sql.NullString
+	)
+	if err := row.Scan(&e.ID, &e.UserID, &category, &amount, &description, &e.CreatedAt, &sign, &tags, &account); err != nil {
+		return storage.Expense{}, err
+	}
+	var err error
+	if e.Category, err = s.openText("category", fieldAAD("category", e.UserID), category); err != nil {
+		return storage.Expense{}, err
+	}
+	if e.Amount, err = s.openAmount(e.UserID, amount); err != nil {
+		return storage.Expense{}, err
+	}
+	if e.Description, err = s.openText("description", fieldAAD("description", e.UserID), description); err != nil {
+		return storage.Expense{}, err
+	}
+	e.Kind = expense.KindOf(sign)
+	e.Tags = strings.Fields(tags.String)
+	e.Account = account.String
+	return e, nil
+}
+
+// filterClause renders filter as a WHERE condition with bound parameters.
+func filterClause(filter storage.ExportFilter) (string, []any) {
+	conditions := []string{"1 = 1"}
+	var args []any
+	if filter.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, "category = ? COLLATE NOCASE")
+		args = append(args, filter.Category)
+	}
+	if filter.Text != "" {
+		conditions = append(conditions, `description LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(filter.Text)+"%")
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "id IN (SELECT expense_id FROM expense_tags WHERE tag = ?)")
+		args = append(args, strings.ToLower(filter.Tag))
+	}
+	if filter.Kind != "" {
+		conditions = append(conditions, "sign = ?")
+		args = append(args, filter.Kind.Sign())
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// expenseFilter is filterClause for queries whose rows are decrypted before use. Once
+// encryption is configured the category and description may be stored encrypted, so
+// conditions on them are returned as a check on each decrypted expense instead.
+func (s *Store) expenseFilter(filter storage.ExportFilter) (string, []any, func(storage.Expense) bool) {
+	if !s.encrypted() || (filter.Category == "" && filter.Text == "") {
+		where, args := filterClause(filter)
+		return where, args, nil
+	}
+	decrypted := storage.ExportFilter{Category: filter.Category, Text: filter.Text}
+	filter.Category, filter.Text = "", ""
+	where, args := filterClause(filter)
+	return where, args, decrypted.Matches
+}
+
+// likeEscaper escapes LIKE wildcards so filter text always matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// expectAffected maps an update or delete that matched no row to storage.ErrNotFound.
+func expectAffected(res sql.Result, op string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlite: %s: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Ping checks that the database answers queries.
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	if err := s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("sqlite: ping: %w", err)
 	}
 	return nil
 }
@@ -95,17 +548,23 @@ func (s *Store) Close() error {
 	return nil
 }
 
-// Stats aggregates spending grouped by category since the provided time.
-func (s *Store) Stats(ctx context.Context, since time.Time) (storage.Summary, error) {
-	summary := storage.Summary{CategoryTotals: make(map[string]float64)}
+// Stats aggregates the spending matching filter by category and by tag, and totals the
+// matching income.
+func (s *Store) Stats(ctx context.Context, filter storage.ExportFilter) (storage.Summary, error) {
+	if s.encrypted() {
+		return s.statsDecrypted(ctx, filter)
+	}
+	summary := storage.Summary{CategoryTotals: make(map[string]float64), TagTotals: make(map[string]float64)}
+	where, args := filterClause(filter)
 
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT category, COUNT(*), COALESCE(SUM(amount), 0) 
+		SELECT category, COUNT(*), COALESCE(SUM(amount), 0)
 		FROM expenses
-		WHERE created_at >= ?
+		WHERE `+where+`
+			AND sign = -1
 			AND category IS NOT NULL
 			AND category != ''
-		GROUP BY category`, since.UTC())
+		GROUP BY category`, args...)
 	if err != nil {
 		return summary, fmt.Errorf("sqlite: query stats: %w", err)
 	}
@@ -129,12 +588,68 @@ func (s *Store) Stats(ctx context.Context, since time.Time) (storage.Summary, er
 		return summary, fmt.Errorf("sqlite: stats rows: %w", err)
 	}
 
+	tagRows, err := s.db.QueryContext(ctx, `
+		SELECT expense_tags.tag, COALESCE(SUM(amount), 0)
+		FROM expense_tags
+		JOIN expenses ON expenses.id = expense_tags.expense_id
+		WHERE `+where+` AND sign = -1
+		GROUP BY expense_tags.tag`, args...)
+	if err != nil {
+		return summary, fmt.Errorf("sqlite: query tag stats: %w", err)
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var (
+			tag   string
+			total float64
+		)
+		if err := tagRows.Scan(&tag, &total); err != nil {
+			return summary, fmt.Errorf("sqlite: scan tag stats: %w", err)
+		}
+		summary.TagTotals[tag] = total
+	}
+	if err := tagRows.Err(); err != nil {
+		return summary, fmt.Errorf("sqlite: tag stats rows: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM expenses
+		WHERE `+where+` AND sign = 1`, args...).Scan(&summary.IncomeCount, &summary.IncomeAmount)
+	if err != nil {
+		return summary, fmt.Errorf("sqlite: query income stats: %w", err)
+	}
+
 	return summary, nil
 }
 
 func migrate(db *sql.DB) error {
-	if _, err := db.Exec(expenseSchema); err != nil {
-		return fmt.Errorf("sqlite: migrate schema: %w", err)
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("sqlite: read schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("sqlite: database schema version %d is newer than this binary supports (%d)", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("sqlite: begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite: migrate schema to version %d: %w", i+1, err)
+		}
+		// PRAGMA does not accept bound parameters; the value is an integer we control.
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite: record schema version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("sqlite: commit migration %d: %w", i+1, err)
+		}
 	}
 	return nil
 }
@@ -3,11 +3,14 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
 )
 
 func TestNewStoreRequiresPath(t *testing.T) {
@@ -16,6 +19,20 @@ func TestNewStoreRequiresPath(t *testing.T) {
 	}
 }
 
+func TestSQLiteStorePing(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping error: %v", err)
+	}
+	store.Close()
+	if err := store.Ping(context.Background()); err == nil {
+		t.Fatal("expected Ping to fail on a closed store")
+	}
+}
+
 func TestSQLiteStoreSaveExpense(t *testing.T) {
 	t.Parallel()
 
@@ -32,7 +49,7 @@ func TestSQLiteStoreSaveExpense(t *testing.T) {
 		Description: "Taxi",
 	}
 
-	if err := store.SaveExpense(context.Background(), item); err != nil {
+	if _, err := store.SaveExpense(context.Background(), 7, item); err != nil {
 		t.Fatalf("SaveExpense error: %v", err)
 	}
 
@@ -65,7 +82,7 @@ func TestSQLiteStoreRejectsEmptyDescription(t *testing.T) {
 	}
 	defer store.Close()
 
-	err = store.SaveExpense(context.Background(), expense.Item{
+	_, err = store.SaveExpense(context.Background(), 7, expense.Item{
 		Category: "General",
 		Amount:   10,
 	})
@@ -84,10 +101,10 @@ func TestSQLiteStoreStats(t *testing.T) {
 	defer store.Close()
 
 	ctx := context.Background()
-	if err := store.SaveExpense(ctx, expense.Item{Category: "Food", Amount: 10, Description: "Lunch"}); err != nil {
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 10, Description: "Lunch"}); err != nil {
 		t.Fatalf("SaveExpense food: %v", err)
 	}
-	if err := store.SaveExpense(ctx, expense.Item{Category: "Travel", Amount: 15.5, Description: "Taxi"}); err != nil {
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Travel", Amount: 15.5, Description: "Taxi"}); err != nil {
 		t.Fatalf("SaveExpense travel: %v", err)
 	}
 
@@ -96,7 +113,7 @@ func TestSQLiteStoreStats(t *testing.T) {
 		t.Fatalf("insert old expense: %v", err)
 	}
 
-	summary, err := store.Stats(ctx, time.Now().AddDate(0, 0, -7))
+	summary, err := store.Stats(ctx, storage.ExportFilter{Since: time.Now().AddDate(0, 0, -7)})
 	if err != nil {
 		t.Fatalf("Stats error: %v", err)
 	}
@@ -117,3 +134,316 @@ func TestSQLiteStoreStats(t *testing.T) {
 		t.Fatalf("expected old expenses to be excluded")
 	}
 }
+
+func TestSQLiteStoreIncome(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	salaryID, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Salary", Amount: 2000, Description: "October pay", Kind: expense.KindIncome})
+	if err != nil {
+		t.Fatalf("SaveExpense income: %v", err)
+	}
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Rent", Amount: 1500, Description: "Rent"}); err != nil {
+		t.Fatalf("SaveExpense rent: %v", err)
+	}
+
+	summary, err := store.Stats(ctx, storage.ExportFilter{UserID: 7})
+	if err != nil {
+		t.Fatalf("Stats error: %v", err)
+	}
+	if summary.TotalCount != 1 || summary.TotalAmount != 1500 || summary.IncomeCount != 1 || summary.IncomeAmount != 2000 {
+		t.Fatalf("expected income apart from spending, got %#v", summary)
+	}
+	if _, ok := summary.CategoryTotals["Salary"]; ok {
+		t.Fatal("expected income to stay out of category totals")
+	}
+	if rate, ok := summary.SavingsRate(); !ok || rate != 0.25 {
+		t.Fatalf("expected 25%% savings rate, got %v (%v)", rate, ok)
+	}
+
+	var kinds []expense.Kind
+	err = store.ExportExpenses(ctx, storage.ExportFilter{UserID: 7, Kind: expense.KindIncome}, func(e storage.Expense) error {
+		kinds = append(kinds, e.Kind)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportExpenses error: %v", err)
+	}
+	if len(kinds) != 1 || kinds[0] != expense.KindIncome {
+		t.Fatalf("expected only the income entry, got %v", kinds)
+	}
+
+	got, err := store.GetExpense(ctx, 7, salaryID)
+	if err != nil || !got.IsIncome() {
+		t.Fatalf("expected income to round-trip, got %#v (%v)", got, err)
+	}
+}
+
+func TestSQLiteStoreMigrationsRecordVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "finance.db")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+
+	var version int
+	if err := store.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("read user_version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Fatalf("expected schema version %d, got %d", len(migrations), version)
+	}
+	store.Close()
+
+	// Reopening must be a no-op rather than re-running migrations.
+	store, err = NewStore(path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.db.Exec(`PRAGMA user_version = 999`); err != nil {
+		t.Fatalf("bump user_version: %v", err)
+	}
+	if err := migrate(store.db); err == nil {
+		t.Fatal("expected error for a schema newer than the binary")
+	}
+}
+
+func TestSQLiteStoreRecentExpenses(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	firstID, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"})
+	if err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	secondID, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 12, Description: "Lunch"})
+	if err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Old", Amount: 1, Description: "Old", Date: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+
+	recent, err := store.RecentExpenses(ctx, 7, time.Now().Add(-10*time.Minute))
+	if err != nil {
+		t.Fatalf("RecentExpenses error: %v", err)
+	}
+	if len(recent) != 2 || recent[0].ID != secondID || recent[1].ID != firstID {
+		t.Fatalf("unexpected recent expenses %#v", recent)
+	}
+	if recent[1].Description != "Latte" || recent[1].UserID != 7 || recent[1].CreatedAt.IsZero() {
+		t.Fatalf("unexpected expense fields %#v", recent[1])
+	}
+}
+
+func TestSQLiteStoreExportExpenses(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	day := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, user := range []int64{7, 8, 7, 7} {
+		item := expense.Item{Category: "Food", Amount: float64(i + 1), Description: fmt.Sprintf("meal %d", i), Date: day.AddDate(0, 0, i)}
+		if _, err := store.SaveExpense(ctx, user, item); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+
+	var got []float64
+	filter := storage.ExportFilter{UserID: 7, Since: day.AddDate(0, 0, 1), Until: day.AddDate(0, 0, 3)}
+	err = store.ExportExpenses(ctx, filter, func(e storage.Expense) error {
+		got = append(got, e.Amount)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportExpenses error: %v", err)
+	}
+	if len(got) != 1 || got[0] != 3 {
+		t.Fatalf("expected only the third expense, got %v", got)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = store.ExportExpenses(ctx, storage.ExportFilter{}, func(storage.Expense) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected export to stop at the callback error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestSQLiteStoreExportFiltersByCategoryAndText(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	items := []expense.Item{
+		{Category: "Coffee", Amount: 1, Description: "Latte at Blue Bottle"},
+		{Category: "coffee", Amount: 2, Description: "Espresso"},
+		{Category: "Food", Amount: 3, Description: "100% juice_bar"},
+	}
+	for _, item := range items {
+		if _, err := store.SaveExpense(ctx, 7, item); err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter storage.ExportFilter
+		want   []float64
+	}{
+		{filter: storage.ExportFilter{Category: "COFFEE"}, want: []float64{1, 2}},
+		{filter: storage.ExportFilter{Text: "blue bottle"}, want: []float64{1}},
+		{filter: storage.ExportFilter{Category: "coffee", Text: "ESP"}, want: []float64{2}},
+		// LIKE wildcards in the text are matched literally.
+		{filter: storage.ExportFilter{Text: "0%"}, want: []float64{3}},
+		{filter: storage.ExportFilter{Text: "e_a"}, want: nil},
+		{filter: storage.ExportFilter{Text: "%"}, want: []float64{3}},
+	}
+	for _, tt := range tests {
+		var got []float64
+		err := store.ExportExpenses(ctx, tt.filter, func(e storage.Expense) error {
+			got = append(got, e.Amount)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExportExpenses error: %v", err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("filter %+v: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestSQLiteStoreExpenseCRUD(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	day := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		category := "Food"
+		if i%2 == 1 {
+			category = "Coffee"
+		}
+		id, err := store.SaveExpense(ctx, 7, expense.Item{Category: category, Amount: float64(i + 1), Description: fmt.Sprintf("item %d", i), Date: day.AddDate(0, 0, i)})
+		if err != nil {
+			t.Fatalf("SaveExpense error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	page, err := store.ListExpenses(ctx, storage.ExportFilter{UserID: 7}, 2, 1)
+	if err != nil {
+		t.Fatalf("ListExpenses error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != ids[3] || page[1].ID != ids[2] {
+		t.Fatalf("expected the second page newest first, got %#v", page)
+	}
+	coffee, err := store.ListExpenses(ctx, storage.ExportFilter{UserID: 7, Category: "Coffee"}, 0, 0)
+	if err != nil || len(coffee) != 2 {
+		t.Fatalf("expected two coffee expenses, got %#v (%v)", coffee, err)
+	}
+
+	got, err := store.GetExpense(ctx, 7, ids[0])
+	if err != nil || got.Description != "item 0" || !got.CreatedAt.Equal(day) {
+		t.Fatalf("unexpected expense %#v (%v)", got, err)
+	}
+	if _, err := store.GetExpense(ctx, 8, ids[0]); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected another user's expense to be hidden, got %v", err)
+	}
+
+	if err := store.UpdateExpense(ctx, 7, ids[0], expense.Item{Category: "Travel", Amount: 42, Description: "Taxi"}); err != nil {
+		t.Fatalf("UpdateExpense error: %v", err)
+	}
+	got, _ = store.GetExpense(ctx, 7, ids[0])
+	if got.Category != "Travel" || got.Amount != 42 || !got.CreatedAt.Equal(day) {
+		t.Fatalf("expected updated fields with the original date, got %#v", got)
+	}
+	if err := store.UpdateExpense(ctx, 8, ids[0], expense.Item{Category: "x", Amount: 1, Description: "x"}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected update by another user to fail, got %v", err)
+	}
+
+	if err := store.DeleteExpense(ctx, 7, ids[0]); err != nil {
+		t.Fatalf("DeleteExpense error: %v", err)
+	}
+	if err := store.DeleteExpense(ctx, 7, ids[0]); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected second delete to report not found, got %v", err)
+	}
+}
+
+func TestSQLiteStoreBudgetsAndTokens(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, b := range []storage.CategoryBudget{{UserID: 7, Category: "Food", MonthlyLimit: 300}, {UserID: 7, Category: "Coffee", MonthlyLimit: 40}, {UserID: 7, Category: "Food", MonthlyLimit: 250}, {UserID: 8, Category: "Food", MonthlyLimit: 1}} {
+		if err := store.SetBudget(ctx, b); err != nil {
+			t.Fatalf("SetBudget error: %v", err)
+		}
+	}
+	budgets, err := store.ListBudgets(ctx, 7)
+	if err != nil || len(budgets) != 2 || budgets[0].Category != "Coffee" || budgets[1].MonthlyLimit != 250 {
+		t.Fatalf("unexpected budgets %#v (%v)", budgets, err)
+	}
+	if err := store.DeleteBudget(ctx, 7, "Food"); err != nil {
+		t.Fatalf("DeleteBudget error: %v", err)
+	}
+	if err := store.DeleteBudget(ctx, 7, "Food"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	id, err := store.CreateAPIToken(ctx, storage.APIToken{UserID: 7, Username: "iamoxyrus", Hash: "abc"})
+	if err != nil {
+		t.Fatalf("CreateAPIToken error: %v", err)
+	}
+	if _, err := store.CreateAPIToken(ctx, storage.APIToken{UserID: 7, Hash: "def"}); err != nil {
+		t.Fatalf("CreateAPIToken error: %v", err)
+	}
+	token, err := store.APITokenByHash(ctx, "abc")
+	if err != nil || token.ID != id || token.UserID != 7 || token.Username != "iamoxyrus" {
+		t.Fatalf("unexpected token %#v (%v)", token, err)
+	}
+	if err := store.RevokeAPIToken(ctx, 8, id); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected another user's revoke to fail, got %v", err)
+	}
+	if err := store.RevokeAPIToken(ctx, 7, id); err != nil {
+		t.Fatalf("RevokeAPIToken error: %v", err)
+	}
+	if _, err := store.APITokenByHash(ctx, "abc"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected revoked token to be gone, got %v", err)
+	}
+	if err := store.RevokeAPIToken(ctx, 7, 0); err != nil {
+		t.Fatalf("revoke all error: %v", err)
+	}
+	if tokens, _ := store.ListAPITokens(ctx, 7); len(tokens) != 0 {
+		t.Fatalf("expected all tokens revoked, got %#v", tokens)
+	}
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.TagStore = (*Store)(nil)
+
+// TagExpense adds tags to one of the user's expenses, up to expense.MaxTags in total.
+func (s *Store) TagExpense(ctx context.Context, userID, id int64, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin tag expense: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := ownExpense(ctx, tx, userID, id); err != nil {
+		return err
+	}
+	if err := insertTags(ctx, tx, id, tags); err != nil {
+		return err
+	}
+	// Counting after the insert leaves out tags the expense already had.
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM expense_tags WHERE expense_id = ?`, id).Scan(&count); err != nil {
+		return fmt.Errorf("sqlite: count tags: %w", err)
+	}
+	if count > expense.MaxTags {
+		return storage.ErrTooManyTags
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit tag expense: %w", err)
+	}
+	return nil
+}
+
+// UntagExpense removes tags from one of the user's expenses.
+func (s *Store) UntagExpense(ctx context.Context, userID, id int64, tags []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin untag expense: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := ownExpense(ctx, tx, userID, id); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM expense_tags WHERE expense_id = ? AND tag = ?`, id, tag); err != nil {
+			return fmt.Errorf("sqlite: untag expense: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit untag expense: %w", err)
+	}
+	return nil
+}
+
+// ownExpense returns storage.ErrNotFound unless the expense exists and belongs to the user.
+func ownExpense(ctx context.Context, tx *sql.Tx, userID, id int64) error {
+	var found int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM expenses WHERE id = ? AND user_id = ?`, id, userID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("sqlite: look up expense: %w", err)
+	}
+	return nil
+}
+
+func insertTags(ctx context.Context, tx *sql.Tx, id int64, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO expense_tags (expense_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return fmt.Errorf("sqlite: insert tag: %w", err)
+		}
+	}
+	return nil
+}
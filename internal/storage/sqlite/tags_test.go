@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStoreTags(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	hotel, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Travel", Amount: 300, Description: "Hotel", Tags: []string{"work", "vacation-2026"}})
+	if err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	dinner, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Food", Amount: 40, Description: "Dinner"})
+	if err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+
+	e, err := store.GetExpense(ctx, 7, hotel)
+	if err != nil || strings.Join(e.Tags, ",") != "vacation-2026,work" {
+		t.Fatalf("expected sorted tags, got %v (%v)", e.Tags, err)
+	}
+
+	if err := store.TagExpense(ctx, 7, dinner, []string{"vacation-2026"}); err != nil {
+		t.Fatalf("TagExpense error: %v", err)
+	}
+	if err := store.TagExpense(ctx, 7, dinner, []string{"vacation-2026"}); err != nil {
+		t.Fatalf("expected tagging twice to be harmless, got %v", err)
+	}
+	var many []string
+	for i := range expense.MaxTags {
+		many = append(many, fmt.Sprintf("t%d", i))
+	}
+	if err := store.TagExpense(ctx, 7, dinner, many); !errors.Is(err, storage.ErrTooManyTags) {
+		t.Fatalf("expected ErrTooManyTags past the limit, got %v", err)
+	}
+	if e, _ := store.GetExpense(ctx, 7, dinner); len(e.Tags) != 1 {
+		t.Fatalf("expected a rejected tagging to change nothing, got %v", e.Tags)
+	}
+	if err := store.TagExpense(ctx, 8, dinner, []string{"stolen"}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's expense, got %v", err)
+	}
+
+	summary, err := store.Stats(ctx, storage.ExportFilter{Tag: "vacation-2026"})
+	if err != nil {
+		t.Fatalf("Stats error: %v", err)
+	}
+	if summary.TotalAmount != 340 || summary.TagTotals["vacation-2026"] != 340 || summary.TagTotals["work"] != 300 {
+		t.Fatalf("unexpected tag summary %#v", summary)
+	}
+
+	// Updates keep the tags.
+	if err := store.UpdateExpense(ctx, 7, dinner, expense.Item{Category: "Food", Amount: 45, Description: "Dinner"}); err != nil {
+		t.Fatalf("UpdateExpense error: %v", err)
+	}
+	if err := store.UntagExpense(ctx, 7, hotel, []string{"work"}); err != nil {
+		t.Fatalf("UntagExpense error: %v", err)
+	}
+	var tagged []int64
+	err = store.ExportExpenses(ctx, storage.ExportFilter{Tag: "vacation-2026", Since: time.Now().Add(-time.Hour)}, func(e storage.Expense) error {
+		tagged = append(tagged, e.ID)
+		return nil
+	})
+	if err != nil || len(tagged) != 2 {
+		t.Fatalf("expected both expenses to keep the vacation tag, got %v (%v)", tagged, err)
+	}
+
+	if err := store.DeleteExpense(ctx, 7, hotel); err != nil {
+		t.Fatalf("DeleteExpense error: %v", err)
+	}
+	var orphans int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM expense_tags WHERE expense_id = ?`, hotel).Scan(&orphans); err != nil || orphans != 0 {
+		t.Fatalf("expected the deleted expense's tags to be removed, got %d (%v)", orphans, err)
+	}
+}
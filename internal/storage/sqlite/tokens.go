@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.APITokenStore = (*Store)(nil)
+
+// CreateAPIToken stores a token hash and returns the token's ID.
+func (s *Store) CreateAPIToken(ctx context.Context, token storage.APIToken) (int64, error) {
+	createdAt := token.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_tokens (user_id, username, token_hash, created_at) VALUES (?, ?, ?, ?)`,
+		token.UserID, token.Username, token.Hash, createdAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: insert api token: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: api token id: %w", err)
+	}
+	return id, nil
+}
+
+// APITokenByHash looks up a token by the hash of its secret.
+func (s *Store) APITokenByHash(ctx context.Context, hash string) (storage.APIToken, error) {
+	var t storage.APIToken
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, username, token_hash, created_at FROM api_tokens WHERE token_hash = ?`, hash).
+		Scan(&t.ID, &t.UserID, &t.Username, &t.Hash, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.APIToken{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return storage.APIToken{}, fmt.Errorf("sqlite: get api token: %w", err)
+	}
+	return t, nil
+}
+
+// ListAPITokens returns a user's tokens, oldest first.
+func (s *Store) ListAPITokens(ctx context.Context, userID int64) ([]storage.APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, username, token_hash, created_at FROM api_tokens WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []storage.APIToken
+	for rows.Next() {
+		var t storage.APIToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Username, &t.Hash, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: scan api token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: api token rows: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes one of the user's tokens, or all of them when id is zero.
+func (s *Store) RevokeAPIToken(ctx context.Context, userID, id int64) error {
+	if id == 0 {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("sqlite: revoke api tokens: %w", err)
+		}
+		return nil
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("sqlite: revoke api token: %w", err)
+	}
+	return expectAffected(res, "revoke api token")
+}
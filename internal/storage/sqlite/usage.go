@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var _ storage.UsageStore = (*Store)(nil)
+
+// RecordUsage stores the token counts of a single LLM call.
+func (s *Store) RecordUsage(ctx context.Context, usage storage.LLMUsage) error {
+	createdAt := usage.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO llm_usage (user_id, username, model, prompt_tokens, completion_tokens, latency_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		usage.UserID, usage.Username, usage.Model, usage.PromptTokens, usage.CompletionTokens,
+		usage.Latency.Milliseconds(), createdAt.UTC()); err != nil {
+		return fmt.Errorf("sqlite: insert usage: %w", err)
+	}
+	return nil
+}
+
+// UsageTotals aggregates usage since the provided time by day in loc, user and model.
+func (s *Store) UsageTotals(ctx context.Context, since time.Time, loc *time.Location) ([]storage.UsageTotal, error) {
+	// Timestamps are written in UTC. Grouping by minute keeps the rows few while still
+	// placing every call on its local day, since zone offsets are whole minutes.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT substr(created_at, 1, 16) AS minute, user_id, MAX(username), model,
+			COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0)
+		FROM llm_usage
+		WHERE created_at >= ?
+		GROUP BY minute, user_id, model
+		ORDER BY minute, user_id, model`, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: query usage: %w", err)
+	}
+	defer rows.Close()
+
+	type key struct {
+		day    string
+		userID int64
+		model  string
+	}
+	var (
+		totals []storage.UsageTotal
+		index  = map[key]int{}
+	)
+	for rows.Next() {
+		var (
+			minute string
+			t      storage.UsageTotal
+		)
+		if err := rows.Scan(&minute, &t.UserID, &t.Username, &t.Model, &t.Calls, &t.PromptTokens, &t.CompletionTokens); err != nil {
+			return nil, fmt.Errorf("sqlite: scan usage: %w", err)
+		}
+		at, err := time.Parse("2006-01-02 15:04", minute)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: parse usage time %q: %w", minute, err)
+		}
+		t.Day = at.In(loc).Format("2006-01-02")
+
+		k := key{day: t.Day, userID: t.UserID, model: t.Model}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(totals)
+			totals = append(totals, t)
+			continue
+		}
+		totals[i].Calls += t.Calls
+		totals[i].PromptTokens += t.PromptTokens
+		totals[i].CompletionTokens += t.CompletionTokens
+		totals[i].Username = max(totals[i].Username, t.Username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: usage rows: %w", err)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		a, b := totals[i], totals[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.Model < b.Model
+	})
+	return totals, nil
+}
+
+// TokensUsedSince sums all tokens recorded since the provided time.
+func (s *Store) TokensUsedSince(ctx context.Context, since time.Time) (int64, error) {
+	var total int64
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(prompt_tokens + completion_tokens), 0)
+		FROM llm_usage
+		WHERE created_at >= ?`, since.UTC()).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sqlite: sum usage: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+func TestSQLiteStoreUsage(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "finance.db"))
+	if err != nil {
+		t.Fatalf("NewStore error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	day := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	records := []storage.LLMUsage{
+		{UserID: 7, Username: "iamoxyrus", Model: "gpt-4o-mini", PromptTokens: 100, CompletionTokens: 20, Latency: 300 * time.Millisecond, CreatedAt: day},
+		{UserID: 7, Username: "iamoxyrus", Model: "gpt-4o-mini", PromptTokens: 50, CompletionTokens: 10, CreatedAt: day.Add(time.Hour)},
+		{UserID: 8, Username: "partner", Model: "gpt-4o-mini", PromptTokens: 10, CompletionTokens: 5, CreatedAt: day.AddDate(0, 0, 1)},
+		{UserID: 8, Username: "partner", Model: "gpt-4o-mini", PromptTokens: 1000, CompletionTokens: 1000, CreatedAt: day.AddDate(0, -1, 0)},
+	}
+	for _, r := range records {
+		if err := store.RecordUsage(ctx, r); err != nil {
+			t.Fatalf("RecordUsage error: %v", err)
+		}
+	}
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := store.UsageTotals(ctx, since, time.UTC)
+	if err != nil {
+		t.Fatalf("UsageTotals error: %v", err)
+	}
+	if len(totals) != 2 {
+		t.Fatalf("expected two day/user groups, got %#v", totals)
+	}
+	first := totals[0]
+	if first.Day != "2026-10-17" || first.UserID != 7 || first.Username != "iamoxyrus" || first.Calls != 2 || first.PromptTokens != 150 || first.CompletionTokens != 30 {
+		t.Fatalf("unexpected first group %#v", first)
+	}
+	if totals[1].Day != "2026-10-18" || totals[1].UserID != 8 {
+		t.Fatalf("unexpected second group %#v", totals[1])
+	}
+
+	// In Bogotá (UTC-5) a call at 03:00 UTC on the 18th still falls on the 17th.
+	bogota, err := time.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordUsage(ctx, storage.LLMUsage{UserID: 8, Username: "partner", Model: "gpt-4o-mini", PromptTokens: 1, CreatedAt: time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+	local, err := store.UsageTotals(ctx, since, bogota)
+	if err != nil {
+		t.Fatalf("UsageTotals error: %v", err)
+	}
+	if len(local) != 3 || local[1].Day != "2026-10-17" || local[1].UserID != 8 || local[1].Calls != 1 || local[2].Day != "2026-10-18" {
+		t.Fatalf("expected calls bucketed by local day, got %#v", local)
+	}
+
+	used, err := store.TokensUsedSince(ctx, since)
+	if err != nil {
+		t.Fatalf("TokensUsedSince error: %v", err)
+	}
+	if used != 196 {
+		t.Fatalf("expected 196 tokens this month, got %d", used)
+	}
+}
@@ -2,21 +2,372 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/locale"
 )
 
-// ExpenseStore persists categorized expenses.
+var (
+	// ErrNotFound is returned when a record does not exist or belongs to another user.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when creating a record whose name the user already uses.
+	ErrExists = errors.New("already exists")
+	// ErrTooManyTags is returned when tagging would leave an expense with more than
+	// expense.MaxTags tags.
+	ErrTooManyTags = errors.New("too many tags")
+	// ErrInvalidExpense is returned when an expense cannot be stored as given, such as
+	// one without a description. Retrying it cannot succeed.
+	ErrInvalidExpense = errors.New("invalid expense")
+)
+
+// ExpenseStore persists categorized expenses and income entries; "expense" in method names
+// covers both.
 type ExpenseStore interface {
-	SaveExpense(ctx context.Context, item expense.Item) error
+	// SaveExpense records an expense on behalf of a Telegram user and returns its ID.
+	SaveExpense(ctx context.Context, userID int64, item expense.Item) (int64, error)
 	Close() error
-	Stats(ctx context.Context, since time.Time) (Summary, error)
+	// Stats aggregates the expenses matching filter by category and by tag, and totals the
+	// matching income separately.
+	Stats(ctx context.Context, filter ExportFilter) (Summary, error)
+	// RecentExpenses lists a user's expenses recorded since the given time, newest first.
+	RecentExpenses(ctx context.Context, userID int64, since time.Time) ([]Expense, error)
+	// ExportExpenses streams the expenses matching filter to fn, oldest first, without
+	// loading them all into memory. It stops at the first error fn returns.
+	ExportExpenses(ctx context.Context, filter ExportFilter, fn func(Expense) error) error
+	// ListExpenses returns one page of the expenses matching filter, newest first.
+	ListExpenses(ctx context.Context, filter ExportFilter, limit, offset int) ([]Expense, error)
+	// GetExpense, UpdateExpense and DeleteExpense act on a user's own expense and return
+	// ErrNotFound for IDs that do not exist or belong to someone else. UpdateExpense keeps
+	// the expense's tags; change them through a TagStore.
+	GetExpense(ctx context.Context, userID, id int64) (Expense, error)
+	UpdateExpense(ctx context.Context, userID, id int64, item expense.Item) error
+	DeleteExpense(ctx context.Context, userID, id int64) error
+}
+
+// ExportFilter narrows an export; zero values leave that bound open.
+type ExportFilter struct {
+	// UserID limits the export to one Telegram user; zero exports everyone's expenses.
+	UserID int64
+	Since  time.Time
+	// Until is exclusive.
+	Until time.Time
+	// Category matches case-insensitively when set.
+	Category string
+	// Text matches expenses whose description contains it, ignoring case.
+	Text string
+	// Tag matches expenses carrying the tag.
+	Tag string
+	// Kind limits the results to expenses or to income; zero matches both.
+	Kind expense.Kind
+}
+
+// Matches reports whether an expense passes the filter.
+func (f ExportFilter) Matches(e Expense) bool {
+	if f.UserID != 0 && e.UserID != f.UserID {
+		return false
+	}
+	if f.Category != "" && !strings.EqualFold(e.Category, f.Category) {
+		return false
+	}
+	if f.Text != "" && !strings.Contains(strings.ToLower(e.Description), strings.ToLower(f.Text)) {
+		return false
+	}
+	if f.Tag != "" && !expense.HasTag(e.Tags, f.Tag) {
+		return false
+	}
+	if f.Kind != "" && e.Kind.Sign() != f.Kind.Sign() {
+		return false
+	}
+	if !f.Since.IsZero() && e.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || e.CreatedAt.Before(f.Until)
+}
+
+// Expense is a stored expense together with its identifying metadata.
+type Expense struct {
+	ID        int64
+	UserID    int64
+	CreatedAt time.Time
+	expense.Item
 }
 
-// Summary describes aggregate expense data over a period.
+// Summary describes aggregate expense data over a period. Income is kept out of the
+// expense totals and reported on its own.
 type Summary struct {
 	TotalCount     int
 	TotalAmount    float64
 	CategoryTotals map[string]float64
+	// TagTotals sums the expenses carrying each tag; an expense with several tags counts
+	// toward each of them.
+	TagTotals    map[string]float64
+	IncomeCount  int
+	IncomeAmount float64
+}
+
+// Net is income minus expenses.
+func (s Summary) Net() float64 {
+	return s.IncomeAmount - s.TotalAmount
+}
+
+// SavingsRate is the share of income left after expenses, negative when spending exceeded
+// income. It reports false when there was no income.
+func (s Summary) SavingsRate() (float64, bool) {
+	if s.IncomeAmount <= 0 {
+		return 0, false
+	}
+	return s.Net() / s.IncomeAmount, true
+}
+
+// TagStore changes the tags of stored expenses. Tags are expected to be normalized with
+// expense.NormalizeTag.
+type TagStore interface {
+	// TagExpense adds tags to one of the user's expenses, ignoring ones it already has. It
+	// returns ErrTooManyTags, and changes nothing, if the expense would end up with more
+	// than expense.MaxTags.
+	TagExpense(ctx context.Context, userID, id int64, tags []string) error
+	// UntagExpense removes tags from one of the user's expenses.
+	UntagExpense(ctx context.Context, userID, id int64, tags []string) error
+}
+
+// AccountType is the kind of a payment account.
+type AccountType string
+
+const (
+	AccountCash   AccountType = "cash"
+	AccountDebit  AccountType = "debit"
+	AccountCredit AccountType = "credit"
+)
+
+// ParseAccountType accepts "cash", "debit" or "credit", optionally followed by "card",
+// or their Spanish names such as "efectivo" or "tarjeta de débito", ignoring case and
+// accents.
+func ParseAccountType(raw string) (AccountType, bool) {
+	raw = locale.StripAccents(strings.ToLower(strings.Join(strings.Fields(raw), " ")))
+	raw = strings.TrimSuffix(raw, " card")
+	raw = strings.TrimPrefix(strings.TrimPrefix(raw, "tarjeta "), "de ")
+	switch raw {
+	case "cash", "efectivo":
+		return AccountCash, true
+	case "debit", "debito":
+		return AccountDebit, true
+	case "credit", "credito":
+		return AccountCredit, true
+	default:
+		return "", false
+	}
+}
+
+// Account is a user's card, bank account or cash wallet. Names are unique per user,
+// ignoring case.
+type Account struct {
+	ID             int64
+	UserID         int64
+	Name           string
+	Type           AccountType
+	OpeningBalance float64
+	// Default accounts receive the expenses that name no account, or one the user does
+	// not have. Each user has at most one.
+	Default bool
+}
+
+// AccountBalance is an account together with the money that went through it.
+type AccountBalance struct {
+	Account
+	// Balance is the opening balance plus income minus expenses; it goes negative as a
+	// credit card is used.
+	Balance float64
+	// Spent sums the account's expenses since the time passed to AccountBalances.
+	Spent float64
+}
+
+// AccountStore persists payment accounts. A saved expense is linked to the account whose
+// name matches its expense.Item.Account, else to the account of that type, else to the
+// user's default account.
+type AccountStore interface {
+	// CreateAccount returns ErrExists when the user already has an account by that name.
+	// A user's first account becomes their default.
+	CreateAccount(ctx context.Context, account Account) (int64, error)
+	// ListAccounts returns a user's accounts ordered by name.
+	ListAccounts(ctx context.Context, userID int64) ([]Account, error)
+	// SetDefaultAccount returns ErrNotFound when the user has no account by that name.
+	SetDefaultAccount(ctx context.Context, userID int64, name string) error
+	// AccountBalances returns a user's accounts ordered by name with their balances and
+	// the spending since the given time.
+	AccountBalances(ctx context.Context, userID int64, since time.Time) ([]AccountBalance, error)
+}
+
+// Goal is a savings target such as a trip, optionally with a date to reach it by. Names
+// are unique per user, ignoring case.
+type Goal struct {
+	ID     int64
+	UserID int64
+	Name   string
+	Target float64
+	// Deadline is the last day to reach the target; zero means none.
+	Deadline  time.Time
+	CreatedAt time.Time
+}
+
+// GoalProgress is a goal together with what has been put toward it.
+type GoalProgress struct {
+	Goal
+	Saved         float64
+	Contributions int
+}
+
+// GoalStore persists savings goals and the contributions logged against them.
+type GoalStore interface {
+	// CreateGoal returns ErrExists when the user already has a goal by that name.
+	CreateGoal(ctx context.Context, goal Goal) (int64, error)
+	// ContributeToGoal logs an amount saved toward the named goal; a negative amount is a
+	// withdrawal. It returns ErrNotFound when the user has no goal by that name.
+	ContributeToGoal(ctx context.Context, userID int64, name string, amount float64, at time.Time) error
+	// DeleteGoal removes the named goal and its contributions, or returns ErrNotFound.
+	DeleteGoal(ctx context.Context, userID int64, name string) error
+	// GoalProgress returns a user's goals with their savings, oldest goal first.
+	GoalProgress(ctx context.Context, userID int64) ([]GoalProgress, error)
+}
+
+// SettingsStore persists each user's time zone, currency and number format.
+type SettingsStore interface {
+	// UserSettings returns locale.Default() for users who never saved any settings.
+	UserSettings(ctx context.Context, userID int64) (locale.Settings, error)
+	// SaveUserSettings creates or replaces the user's settings.
+	SaveUserSettings(ctx context.Context, userID int64, settings locale.Settings) error
+}
+
+// PendingExpense is a message that could not be recorded yet and is waiting for a retry.
+type PendingExpense struct {
+	ID       int64
+	ChatID   int64
+	UserID   int64
+	Username string
+	Text     string
+	Attempts int
+	// LastError is a user-facing description of the most recent failure.
+	LastError string
+	// NextAttempt is when the message becomes due again; zero means retries were given up.
+	NextAttempt time.Time
+	CreatedAt   time.Time
+}
+
+// PendingQueue durably stores messages whose extraction or persistence failed.
+type PendingQueue interface {
+	EnqueuePending(ctx context.Context, pending PendingExpense) (int64, error)
+	// DuePending returns up to limit entries whose NextAttempt is at or before now.
+	DuePending(ctx context.Context, now time.Time, limit int) ([]PendingExpense, error)
+	// ReschedulePending records a failed attempt; a zero next time parks the entry for good.
+	ReschedulePending(ctx context.Context, id int64, next time.Time, lastError string) error
+	DeletePending(ctx context.Context, id int64) error
+	ListPending(ctx context.Context, chatID int64) ([]PendingExpense, error)
+}
+
+// LLMUsage records the tokens consumed by a single LLM call.
+type LLMUsage struct {
+	UserID           int64
+	Username         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration
+	CreatedAt        time.Time
+}
+
+// UsageTotal aggregates LLM usage for one day, user and model.
+type UsageTotal struct {
+	Day              string
+	UserID           int64
+	Username         string
+	Model            string
+	Calls            int
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// UsageStore persists and aggregates LLM token usage.
+type UsageStore interface {
+	RecordUsage(ctx context.Context, usage LLMUsage) error
+	// UsageTotals groups usage since the given time by day in loc, user and model.
+	UsageTotals(ctx context.Context, since time.Time, loc *time.Location) ([]UsageTotal, error)
+	// TokensUsedSince sums prompt and completion tokens recorded since the given time.
+	TokensUsedSince(ctx context.Context, since time.Time) (int64, error)
+}
+
+// CategoryBudget is a user's monthly spending limit for one category.
+type CategoryBudget struct {
+	UserID       int64
+	Category     string
+	MonthlyLimit float64
+}
+
+// BudgetStore persists per-user category budgets.
+type BudgetStore interface {
+	// SetBudget creates or replaces the budget for the user and category.
+	SetBudget(ctx context.Context, budget CategoryBudget) error
+	ListBudgets(ctx context.Context, userID int64) ([]CategoryBudget, error)
+	// DeleteBudget returns ErrNotFound when the user has no budget for the category.
+	DeleteBudget(ctx context.Context, userID int64, category string) error
+}
+
+// APIToken is a bearer token issued to a user. Only a hash of the secret is stored.
+type APIToken struct {
+	ID        int64
+	UserID    int64
+	Username  string
+	Hash      string
+	CreatedAt time.Time
+}
+
+// APITokenStore persists API tokens.
+type APITokenStore interface {
+	CreateAPIToken(ctx context.Context, token APIToken) (int64, error)
+	// APITokenByHash returns ErrNotFound for unknown or revoked tokens.
+	APITokenByHash(ctx context.Context, hash string) (APIToken, error)
+	ListAPITokens(ctx context.Context, userID int64) ([]APIToken, error)
+	// RevokeAPIToken deletes one of the user's tokens; an id of zero revokes all of them.
+	RevokeAPIToken(ctx context.Context, userID, id int64) error
+}
+
+// Granularity is the width of the time buckets in aggregate queries. Buckets are always
+// in UTC, whatever the user's time zone and week start; callers that show buckets to a
+// user group the expenses themselves, as /chart does.
+type Granularity int
+
+const (
+	// BucketDay groups by UTC day.
+	BucketDay Granularity = iota
+	// BucketWeek groups by UTC week, starting on Monday.
+	BucketWeek
+	// BucketMonth groups by UTC calendar month.
+	BucketMonth
+)
+
+// BucketTotal is the spending in one category during one time bucket.
+type BucketTotal struct {
+	// Start is the first day of the bucket, at midnight UTC.
+	Start    time.Time
+	Category string
+	Count    int
+	Total    float64
+}
+
+// CategoryStat holds running sums from which the mean and spread of a category's expense
+// amounts can be derived without loading every row.
+type CategoryStat struct {
+	Category   string
+	Count      int
+	Sum        float64
+	SumSquares float64
+}
+
+// AggregateStore answers the aggregate queries behind trends and anomaly detection.
+type AggregateStore interface {
+	// BucketTotals groups the expenses matching filter by bucket and category, oldest first.
+	BucketTotals(ctx context.Context, filter ExportFilter, granularity Granularity) ([]BucketTotal, error)
+	// CategoryStats summarizes the amounts of the expenses matching filter per category.
+	CategoryStats(ctx context.Context, filter ExportFilter) ([]CategoryStat, error)
 }
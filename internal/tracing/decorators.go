@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+var outcomeKey = attribute.Key("financebot.outcome")
+
+// Extractor records a span per extraction, with "success" or the failure reason as its
+// outcome. Neither the text nor the raw error is recorded, since both may hold user
+// content or provider details.
+type Extractor struct {
+	next   extractor.Service
+	tracer trace.Tracer
+}
+
+// NewExtractor traces next.
+func NewExtractor(next extractor.Service, tp trace.TracerProvider) *Extractor {
+	return &Extractor{next: next, tracer: tp.Tracer(tracerName)}
+}
+
+// Extract delegates to the wrapped service.
+func (e *Extractor) Extract(ctx context.Context, text string) (expense.Item, error) {
+	ctx, span := e.tracer.Start(ctx, "extractor.Extract")
+	defer span.End()
+
+	item, err := e.next.Extract(ctx, text)
+	if err != nil {
+		reason := extractor.Reason(err)
+		span.SetAttributes(outcomeKey.String(reason))
+		span.SetStatus(codes.Error, reason)
+		return item, err
+	}
+	span.SetAttributes(outcomeKey.String("success"), attribute.Bool("financebot.low_confidence", item.LowConfidence))
+	return item, nil
+}
+
+// ExpenseStore records spans around SaveExpense and Stats and passes the other methods
+// through untraced.
+type ExpenseStore struct {
+	storage.ExpenseStore
+	tracer trace.Tracer
+}
+
+// NewExpenseStore traces next.
+func NewExpenseStore(next storage.ExpenseStore, tp trace.TracerProvider) *ExpenseStore {
+	return &ExpenseStore{ExpenseStore: next, tracer: tp.Tracer(tracerName)}
+}
+
+// SaveExpense delegates to the wrapped store.
+func (s *ExpenseStore) SaveExpense(ctx context.Context, userID int64, item expense.Item) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "store.SaveExpense")
+	defer span.End()
+	id, err := s.ExpenseStore.SaveExpense(ctx, userID, item)
+	end(span, err)
+	return id, err
+}
+
+// Stats delegates to the wrapped store.
+func (s *ExpenseStore) Stats(ctx context.Context, filter storage.ExportFilter) (storage.Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "store.Stats")
+	defer span.End()
+	summary, err := s.ExpenseStore.Stats(ctx, filter)
+	end(span, err)
+	return summary, err
+}
+
+// end records the outcome of a store call on span.
+func end(span trace.Span, err error) {
+	switch {
+	case err == nil:
+		span.SetAttributes(outcomeKey.String("success"))
+	case errors.Is(err, storage.ErrNotFound):
+		span.SetAttributes(outcomeKey.String("not_found"))
+	default:
+		span.SetAttributes(outcomeKey.String("error"))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "store error")
+	}
+}
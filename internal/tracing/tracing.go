@@ -0,0 +1,81 @@
+// Package tracing sets up OpenTelemetry tracing and provides decorators that record spans
+// around the extractor and the expense store.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	serviceName = "financebot"
+	tracerName  = "github.com/Oxyrus/financebot/internal/tracing"
+)
+
+// Exporter names where spans are sent.
+type Exporter string
+
+const (
+	// ExporterNone disables tracing.
+	ExporterNone Exporter = ""
+	// ExporterStdout writes spans as JSON, for local debugging.
+	ExporterStdout Exporter = "stdout"
+	// ExporterOTLP sends spans over OTLP/HTTP to the endpoint in the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT variables, localhost:4318 by default.
+	ExporterOTLP Exporter = "otlp"
+)
+
+// ParseExporter accepts "", "none", "stdout" or "otlp", ignoring case.
+func ParseExporter(raw string) (Exporter, error) {
+	switch e := Exporter(strings.ToLower(strings.TrimSpace(raw))); e {
+	case ExporterNone, "none":
+		return ExporterNone, nil
+	case ExporterStdout, ExporterOTLP:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown exporter %q", raw)
+	}
+}
+
+// Setup returns a tracer provider exporting to exporter, and a function that flushes
+// pending spans and shuts it down. ExporterNone gives a provider that records nothing.
+// stdout is where the stdout exporter writes.
+func Setup(ctx context.Context, exporter Exporter, stdout io.Writer) (trace.TracerProvider, func(context.Context) error, error) {
+	var (
+		spanExporter sdktrace.SpanExporter
+		err          error
+	)
+	switch exporter {
+	case ExporterNone:
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(stdout))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, nil, fmt.Errorf("tracing: unknown exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("tracing: create %s exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	return provider, provider.Shutdown, nil
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/extractor"
+	"github.com/Oxyrus/financebot/internal/storage"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+type stubExtractor struct {
+	err error
+}
+
+func (s stubExtractor) Extract(context.Context, string) (expense.Item, error) {
+	if s.err != nil {
+		return expense.Item{}, s.err
+	}
+	return expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}, nil
+}
+
+func TestExtractorRecordsOutcome(t *testing.T) {
+	recorder, tp := newRecorder()
+	ctx := context.Background()
+	_, _ = NewExtractor(stubExtractor{}, tp).Extract(ctx, "latte 3.50")
+	_, _ = NewExtractor(stubExtractor{err: extractor.ErrNoAmount}, tp).Extract(ctx, "my secret latte")
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected two spans, got %d", len(spans))
+	}
+	if got := attr(spans[0], outcomeKey); got != "success" || spans[0].Name() != "extractor.Extract" {
+		t.Fatalf("unexpected success span %s %q", spans[0].Name(), got)
+	}
+	if got := attr(spans[1], outcomeKey); got != "no_amount" || spans[1].Status().Code != codes.Error {
+		t.Fatalf("expected a no_amount error span, got %q %v", got, spans[1].Status())
+	}
+	for _, kv := range spans[1].Attributes() {
+		if strings.Contains(kv.Value.Emit(), "secret") {
+			t.Fatalf("expected the text to stay out of the span, got %v", kv)
+		}
+	}
+}
+
+func TestExpenseStoreTracesSaveAndStats(t *testing.T) {
+	recorder, tp := newRecorder()
+	store := NewExpenseStore(memory.NewStore(), tp)
+	ctx := context.Background()
+
+	if _, err := store.SaveExpense(ctx, 7, expense.Item{Category: "Coffee", Amount: 3.5, Description: "Latte"}); err != nil {
+		t.Fatalf("SaveExpense error: %v", err)
+	}
+	if _, err := store.Stats(ctx, storage.ExportFilter{UserID: 7}); err != nil {
+		t.Fatalf("Stats error: %v", err)
+	}
+	if _, err := store.GetExpense(ctx, 7, 1); err != nil {
+		t.Fatalf("GetExpense error: %v", err)
+	}
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		if got := attr(span, outcomeKey); got != "success" {
+			t.Errorf("%s: expected success, got %q", span.Name(), got)
+		}
+	}
+	if strings.Join(names, ",") != "store.SaveExpense,store.Stats" {
+		t.Fatalf("expected only SaveExpense and Stats to be traced, got %v", names)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	var out bytes.Buffer
+	tp, shutdown, err := Setup(context.Background(), ExporterStdout, &out)
+	if err != nil {
+		t.Fatalf("Setup error: %v", err)
+	}
+	_, span := tp.Tracer("test").Start(context.Background(), "hello")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"Name":"hello"`) || !strings.Contains(out.String(), "financebot") {
+		t.Fatalf("expected the span on stdout, got %s", out.String())
+	}
+
+	if _, err := ParseExporter("zipkin"); err == nil {
+		t.Fatal("expected an unknown exporter to be rejected")
+	}
+	if e, err := ParseExporter("OTLP"); err != nil || e != ExporterOTLP {
+		t.Fatalf("expected otlp, got %q (%v)", e, err)
+	}
+	if _, _, err := Setup(context.Background(), "zipkin", nil); err == nil {
+		t.Fatal("expected Setup to reject an unknown exporter")
+	}
+}
@@ -0,0 +1,89 @@
+// Package usage estimates the cost of LLM calls from recorded token counts.
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Price is the USD cost per one million prompt (input) and completion (output) tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// PriceTable maps model names (or model name prefixes) to prices.
+type PriceTable map[string]Price
+
+// DefaultPrices returns list prices for the models the bot uses out of the box.
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		"gpt-4o-mini": {Input: 0.15, Output: 0.60},
+		"gpt-4o":      {Input: 2.50, Output: 10.00},
+	}
+}
+
+// ParsePriceTable reads overrides formatted as "model=input/output,..." (USD per 1M tokens)
+// on top of DefaultPrices.
+func ParsePriceTable(raw string) (PriceTable, error) {
+	prices := DefaultPrices()
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, rates, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid price entry %q: expected model=input/output", entry)
+		}
+		in, out, ok := strings.Cut(rates, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid price entry %q: expected model=input/output", entry)
+		}
+		input, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input price in %q: %w", entry, err)
+		}
+		output, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output price in %q: %w", entry, err)
+		}
+		prices[strings.TrimSpace(model)] = Price{Input: input, Output: output}
+	}
+	return prices, nil
+}
+
+// Lookup finds the price for model, falling back to the longest matching prefix so that
+// dated snapshots like "gpt-4o-mini-2024-07-18" use the "gpt-4o-mini" price.
+func (p PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range p {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// Cost estimates the USD cost of the given token counts; ok is false for unknown models.
+func (p PriceTable) Cost(model string, promptTokens, completionTokens int64) (float64, bool) {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1_000_000, true
+}
+
+// MonthStart returns midnight UTC on the first day of t's month, the budget period boundary.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
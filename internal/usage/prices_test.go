@@ -0,0 +1,52 @@
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParsePriceTable(t *testing.T) {
+	prices, err := ParsePriceTable("gpt-4o-mini=0.2/0.8, custom-model=1/2")
+	if err != nil {
+		t.Fatalf("ParsePriceTable error: %v", err)
+	}
+	if got := prices["gpt-4o-mini"]; got.Input != 0.2 || got.Output != 0.8 {
+		t.Fatalf("expected override, got %#v", got)
+	}
+	if _, ok := prices["gpt-4o"]; !ok {
+		t.Fatal("expected defaults to be kept")
+	}
+	if _, ok := prices["custom-model"]; !ok {
+		t.Fatal("expected custom model")
+	}
+
+	for _, raw := range []string{"nope", "model=1", "model=a/1", "model=1/b"} {
+		if _, err := ParsePriceTable(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestPriceTableCostUsesLongestPrefix(t *testing.T) {
+	prices := DefaultPrices()
+
+	cost, ok := prices.Cost("gpt-4o-mini-2024-07-18", 1_000_000, 1_000_000)
+	if !ok {
+		t.Fatal("expected dated snapshot to match its base model")
+	}
+	if math.Abs(cost-0.75) > 1e-9 {
+		t.Fatalf("expected gpt-4o-mini pricing, got %.4f", cost)
+	}
+
+	if _, ok := prices.Cost("unknown", 10, 10); ok {
+		t.Fatal("expected unknown model to have no price")
+	}
+}
+
+func TestMonthStart(t *testing.T) {
+	got := MonthStart(time.Date(2026, 10, 18, 23, 0, 0, 0, time.FixedZone("UTC-5", -5*3600)))
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
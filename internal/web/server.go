@@ -0,0 +1,283 @@
+// Package web serves the expense dashboard over HTTP.
+package web
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/httpserve"
+	"github.com/Oxyrus/financebot/internal/reqctx"
+	"github.com/Oxyrus/financebot/internal/storage"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+const (
+	sessionCookie     = "financebot_session"
+	defaultDays       = 30
+	maxDays           = 366
+	recentLimit       = 20
+	defaultLoginTTL   = 10 * time.Minute
+	defaultSessionTTL = 12 * time.Hour
+)
+
+// Config configures the dashboard server.
+type Config struct {
+	// Addr is the listen address, e.g. ":8080".
+	Addr string
+	// BaseURL is the externally reachable URL used in login links.
+	BaseURL string
+	// LoginTTL bounds how long a login link is valid; SessionTTL how long a login lasts.
+	LoginTTL   time.Duration
+	SessionTTL time.Duration
+}
+
+// Authorizer decides whether a Telegram user may still use the dashboard. Sessions
+// outlive the allow list, so it is consulted on every request.
+type Authorizer interface {
+	IsUserAllowed(username string) bool
+}
+
+// Server serves the dashboard page and its JSON data.
+type Server struct {
+	store      storage.ExpenseStore
+	signer     *Signer
+	authorizer Authorizer
+	cfg        Config
+	mux        *http.ServeMux
+	now        func() time.Time
+}
+
+// NewServer wires the dashboard routes.
+func NewServer(store storage.ExpenseStore, signer *Signer, authorizer Authorizer, cfg Config) *Server {
+	if cfg.LoginTTL <= 0 {
+		cfg.LoginTTL = defaultLoginTTL
+	}
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = defaultSessionTTL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	s := &Server{store: store, signer: signer, authorizer: authorizer, cfg: cfg, mux: http.NewServeMux(), now: time.Now}
+
+	assets, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(assets)))
+	s.mux.HandleFunc("GET /login", s.handleLogin)
+	s.mux.HandleFunc("GET /logout", s.handleLogout)
+	s.mux.Handle("GET /api/dashboard", s.requireSession(http.HandlerFunc(s.handleDashboard)))
+	s.mux.Handle("GET /{$}", s.requireSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, assets, "index.html")
+	})))
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle mounts an additional handler, such as the REST API, on the dashboard's listener.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// LoginURL issues a single-use login link for a Telegram user.
+func (s *Server) LoginURL(userID int64, username string) (string, time.Duration, error) {
+	token, err := s.signer.Issue(userID, username, kindLogin, s.cfg.LoginTTL)
+	if err != nil {
+		return "", 0, err
+	}
+	return s.cfg.BaseURL + "/login?token=" + url.QueryEscape(token), s.cfg.LoginTTL, nil
+}
+
+// Run serves on cfg.Addr until ctx is canceled, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	return httpserve.Serve(ctx, "web", s.cfg.Addr, s)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.signer.Redeem(r.URL.Query().Get("token"))
+	if err != nil {
+		http.Error(w, "This login link is invalid or has expired. Send /dashboard to the bot for a new one.", http.StatusUnauthorized)
+		return
+	}
+	if !s.authorizer.IsUserAllowed(claims.Username) {
+		http.Error(w, "You are no longer allowed to use the dashboard.", http.StatusForbidden)
+		return
+	}
+	session, err := s.signer.Issue(claims.UserID, claims.Username, kindSession, s.cfg.SessionTTL)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "issue dashboard session", "err", err)
+		http.Error(w, "Failed to start a session.", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(s.cfg.SessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(s.cfg.BaseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+	http.Error(w, "Logged out.", http.StatusOK)
+}
+
+// requireSession rejects requests without a valid session or from users no longer on
+// the allow list, and puts the logged-in user in the request context.
+func (s *Server) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			http.Error(w, "Send /dashboard to the bot to get a login link.", http.StatusUnauthorized)
+			return
+		}
+		claims, err := s.signer.Verify(cookie.Value, kindSession)
+		if err != nil {
+			http.Error(w, "Your session has expired. Send /dashboard to the bot to get a new login link.", http.StatusUnauthorized)
+			return
+		}
+		if !s.authorizer.IsUserAllowed(claims.Username) {
+			http.Error(w, "You are no longer allowed to use the dashboard.", http.StatusForbidden)
+			return
+		}
+		ctx := reqctx.WithUser(r.Context(), reqctx.User{ID: claims.UserID, Username: claims.Username})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// DayTotal is the spending on one UTC day.
+type DayTotal struct {
+	Day   string  `json:"day"`
+	Total float64 `json:"total"`
+}
+
+// CategoryTotal is the spending and number of expenses in one category.
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+	Count    int     `json:"count"`
+}
+
+// RecentExpense is an expense as listed on the dashboard.
+type RecentExpense struct {
+	ID          int64     `json:"id"`
+	Date        time.Time `json:"date"`
+	Category    string    `json:"category"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+}
+
+// Dashboard is the data behind the dashboard page.
+type Dashboard struct {
+	Since      string          `json:"since"`
+	Total      float64         `json:"total"`
+	Count      int             `json:"count"`
+	Daily      []DayTotal      `json:"daily"`
+	Categories []CategoryTotal `json:"categories"`
+	Recent     []RecentExpense `json:"recent"`
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	days := defaultDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDays {
+			http.Error(w, fmt.Sprintf("days must be between 1 and %d", maxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	user, _ := reqctx.UserFrom(r.Context())
+	dashboard, err := s.buildDashboard(r.Context(), user.ID, days)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "build dashboard", "err", err)
+		http.Error(w, "Failed to load expenses.", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(dashboard); err != nil {
+		slog.ErrorContext(r.Context(), "encode dashboard", "err", err)
+	}
+}
+
+// buildDashboard summarizes the last days of userID's expenses.
+func (s *Server) buildDashboard(ctx context.Context, userID int64, days int) (Dashboard, error) {
+	now := s.now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+
+	dashboard := Dashboard{Since: since.Format("2006-01-02")}
+	daily := make([]DayTotal, days)
+	dayIndex := make(map[string]int, days)
+	for i := range daily {
+		daily[i].Day = since.AddDate(0, 0, i).Format("2006-01-02")
+		dayIndex[daily[i].Day] = i
+	}
+	categories := map[string]*CategoryTotal{}
+	var recent []RecentExpense
+
+	err := s.store.ExportExpenses(ctx, storage.ExportFilter{UserID: userID, Since: since, Kind: expense.KindExpense}, func(e storage.Expense) error {
+		dashboard.Total += e.Amount
+		dashboard.Count++
+		if i, ok := dayIndex[e.CreatedAt.UTC().Format("2006-01-02")]; ok {
+			daily[i].Total += e.Amount
+		}
+		cat, ok := categories[e.Category]
+		if !ok {
+			cat = &CategoryTotal{Category: e.Category}
+			categories[e.Category] = cat
+		}
+		cat.Total += e.Amount
+		cat.Count++
+
+		// Expenses stream oldest first; keep the newest recentLimit.
+		recent = append(recent, RecentExpense{ID: e.ID, Date: e.CreatedAt.UTC(), Category: e.Category, Amount: e.Amount, Description: e.Description})
+		if len(recent) > recentLimit {
+			recent = recent[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return Dashboard{}, err
+	}
+
+	dashboard.Daily = daily
+	dashboard.Categories = make([]CategoryTotal, 0, len(categories))
+	for _, cat := range categories {
+		dashboard.Categories = append(dashboard.Categories, *cat)
+	}
+	sort.Slice(dashboard.Categories, func(i, j int) bool {
+		return dashboard.Categories[i].Total > dashboard.Categories[j].Total
+	})
+	dashboard.Recent = make([]RecentExpense, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		dashboard.Recent = append(dashboard.Recent, recent[i])
+	}
+	return dashboard, nil
+}
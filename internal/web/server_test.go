@@ -0,0 +1,181 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Oxyrus/financebot/internal/expense"
+	"github.com/Oxyrus/financebot/internal/storage/memory"
+)
+
+type allowList map[string]bool
+
+func (a allowList) IsUserAllowed(username string) bool { return a[username] }
+
+func newTestServer(t *testing.T) (*Server, *memory.Store) {
+	t.Helper()
+	store := memory.NewStore()
+	signer, err := NewSigner([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(store, signer, allowList{"iamoxyrus": true}, Config{BaseURL: "https://finance.example/"})
+	server.now = func() time.Time { return time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC) }
+	return server, store
+}
+
+// login follows a login link and returns the session cookie it sets.
+func login(t *testing.T, server *Server) *http.Cookie {
+	t.Helper()
+	link, ttl, err := server.LoginURL(7, "iamoxyrus")
+	if err != nil {
+		t.Fatalf("LoginURL error: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://finance.example/login?token=") || ttl != defaultLoginTTL {
+		t.Fatalf("unexpected login link %q (%v)", link, ttl)
+	}
+	u, _ := url.Parse(link)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/" {
+		t.Fatalf("expected redirect to dashboard, got %d %q", rec.Code, rec.Body.String())
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != sessionCookie || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("unexpected session cookie %#v", cookies)
+	}
+
+	// The link is single-use.
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected reused link to be rejected, got %d", rec.Code)
+	}
+	return cookies[0]
+}
+
+func TestDashboardRequiresSession(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for _, path := range []string{"/", "/api/dashboard"} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("GET %s without session: expected 401, got %d", path, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/dashboard", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "forged.token"})
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected forged session to be rejected, got %d", rec.Code)
+	}
+
+	// Static assets carry no data and load without a session.
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/app.js", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/api/dashboard") {
+		t.Fatalf("expected embedded app.js, got %d", rec.Code)
+	}
+}
+
+func TestDashboardRejectsSessionsOfRemovedUsers(t *testing.T) {
+	server, _ := newTestServer(t)
+	cookie := login(t, server)
+
+	server.authorizer = allowList{}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/dashboard", nil)
+	req.AddCookie(cookie)
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected a removed user's session to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestDashboardServesPageAndData(t *testing.T) {
+	server, store := newTestServer(t)
+	ctx := context.Background()
+	seed := []expense.Item{
+		{Category: "Food", Amount: 12, Description: "Lunch", Date: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)},
+		{Category: "Coffee", Amount: 3.5, Description: "Latte", Date: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)},
+		{Category: "Food", Amount: 20, Description: "Dinner", Date: time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)},
+		{Category: "Travel", Amount: 300, Description: "Flight", Date: time.Date(2026, 8, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, item := range seed {
+		if _, err := store.SaveExpense(ctx, 7, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Another user's spending stays off this dashboard.
+	if _, err := store.SaveExpense(ctx, 8, expense.Item{Category: "Food", Amount: 99, Description: "Groceries", Date: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+	cookie := login(t, server)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Spending over time") {
+		t.Fatalf("expected dashboard page, got %d", rec.Code)
+	}
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Fatal("expected a content security policy")
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/dashboard?days=7", nil)
+	req.AddCookie(cookie)
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected dashboard data, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var data Dashboard
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	if data.Since != "2026-10-12" || data.Count != 3 || data.Total != 35.5 {
+		t.Fatalf("unexpected totals %+v", data)
+	}
+	if len(data.Daily) != 7 || data.Daily[5].Total != 12 || data.Daily[6].Total != 23.5 {
+		t.Fatalf("unexpected daily totals %+v", data.Daily)
+	}
+	if len(data.Categories) != 2 || data.Categories[0].Category != "Food" || data.Categories[0].Count != 2 {
+		t.Fatalf("unexpected categories %+v", data.Categories)
+	}
+	if len(data.Recent) != 3 || data.Recent[0].Description != "Dinner" || data.Recent[2].Description != "Lunch" {
+		t.Fatalf("expected newest expenses first, got %+v", data.Recent)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/dashboard?days=0", nil)
+	req.AddCookie(cookie)
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid days to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestServerMountsAdditionalHandlers(t *testing.T) {
+	server, _ := newTestServer(t)
+	server.Handle("/api/v1/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil))
+	if rec.Code != http.StatusTeapot || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("expected mounted handler behind security headers, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,83 @@
+"use strict";
+
+const money = (n) => "$" + n.toFixed(2);
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined) node.textContent = text;
+  if (className) node.className = className;
+  return node;
+}
+
+function renderDaily(daily) {
+  const svg = document.getElementById("daily");
+  svg.replaceChildren();
+  const max = Math.max(1, ...daily.map((d) => d.total));
+  const width = 800 / daily.length;
+  daily.forEach((d, i) => {
+    const height = (d.total / max) * 190;
+    const rect = document.createElementNS("http://www.w3.org/2000/svg", "rect");
+    rect.setAttribute("x", i * width + width * 0.1);
+    rect.setAttribute("y", 200 - height);
+    rect.setAttribute("width", width * 0.8);
+    rect.setAttribute("height", height);
+    const title = document.createElementNS("http://www.w3.org/2000/svg", "title");
+    title.textContent = d.day + ": " + money(d.total);
+    rect.appendChild(title);
+    svg.appendChild(rect);
+  });
+}
+
+function renderCategories(categories, total) {
+  const list = document.getElementById("categories");
+  list.replaceChildren();
+  categories.forEach((c) => {
+    const item = el("li");
+    item.appendChild(el("span", c.category));
+    const track = el("span");
+    const bar = el("span", undefined, "bar");
+    bar.style.display = "block";
+    bar.style.width = (total > 0 ? (c.total / total) * 100 : 0) + "%";
+    track.appendChild(bar);
+    item.appendChild(track);
+    item.appendChild(el("span", money(c.total), "amount"));
+    list.appendChild(item);
+  });
+}
+
+function renderRecent(recent) {
+  const body = document.getElementById("recent");
+  body.replaceChildren();
+  recent.forEach((e) => {
+    const row = el("tr");
+    row.appendChild(el("td", "#" + e.id));
+    row.appendChild(el("td", e.date.slice(0, 10)));
+    row.appendChild(el("td", e.category));
+    row.appendChild(el("td", e.description));
+    row.appendChild(el("td", money(e.amount), "num"));
+    body.appendChild(row);
+  });
+}
+
+async function load() {
+  const days = document.getElementById("days").value;
+  const error = document.getElementById("error");
+  try {
+    const resp = await fetch("/api/dashboard?days=" + encodeURIComponent(days), { credentials: "same-origin" });
+    if (!resp.ok) throw new Error(await resp.text());
+    const data = await resp.json();
+    document.getElementById("total").textContent = money(data.total);
+    document.getElementById("count").textContent = data.count;
+    document.getElementById("since").textContent = data.since;
+    renderDaily(data.daily);
+    renderCategories(data.categories, data.total);
+    renderRecent(data.recent);
+    error.hidden = true;
+  } catch (err) {
+    error.textContent = err.message;
+    error.hidden = false;
+  }
+}
+
+document.getElementById("days").addEventListener("change", load);
+load();
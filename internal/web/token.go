@@ -0,0 +1,121 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, forged, expired or reused.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+const (
+	kindLogin   = "login"
+	kindSession = "session"
+)
+
+// Claims identify the Telegram user a token was issued to.
+type Claims struct {
+	UserID   int64  `json:"uid"`
+	Username string `json:"usr"`
+	Kind     string `json:"kind"`
+	Expires  int64  `json:"exp"`
+	Nonce    string `json:"nonce"`
+}
+
+// Signer issues and verifies HMAC-SHA256 signed tokens.
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewSigner returns a Signer for secret; an empty secret generates a random one, which
+// invalidates outstanding links and sessions on restart.
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("web: generate secret: %w", err)
+		}
+	}
+	return &Signer{secret: secret, now: time.Now, used: make(map[string]time.Time)}, nil
+}
+
+// Issue returns a token of the given kind for the user, valid for ttl.
+func (s *Signer) Issue(userID int64, username, kind string, ttl time.Duration) (string, error) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("web: generate nonce: %w", err)
+	}
+	payload, err := json.Marshal(Claims{
+		UserID:   userID,
+		Username: username,
+		Kind:     kind,
+		Expires:  s.now().Add(ttl).Unix(),
+		Nonce:    hex.EncodeToString(nonce),
+	})
+	if err != nil {
+		return "", fmt.Errorf("web: encode token: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), nil
+}
+
+// Verify checks the signature, kind and expiry of token.
+func (s *Signer) Verify(token, kind string) (Claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.Kind != kind || s.now().Unix() >= claims.Expires {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Redeem verifies a login token and marks it used, so a leaked link only works once.
+func (s *Signer) Redeem(token string) (Claims, error) {
+	claims, err := s.Verify(token, kindLogin)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for nonce, expires := range s.used {
+		if now.After(expires) {
+			delete(s.used, nonce)
+		}
+	}
+	if _, seen := s.used[claims.Nonce]; seen {
+		return Claims{}, ErrInvalidToken
+	}
+	s.used[claims.Nonce] = time.Unix(claims.Expires, 0)
+	return claims, nil
+}
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
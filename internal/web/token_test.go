@@ -0,0 +1,71 @@
+package web
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignerIssueAndVerify(t *testing.T) {
+	signer, err := NewSigner([]byte("secret"))
+	if err != nil {
+		t.Fatalf("NewSigner error: %v", err)
+	}
+
+	token, err := signer.Issue(7, "iamoxyrus", kindSession, time.Hour)
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+	claims, err := signer.Verify(token, kindSession)
+	if err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+	if claims.UserID != 7 || claims.Username != "iamoxyrus" {
+		t.Fatalf("unexpected claims %#v", claims)
+	}
+
+	if _, err := signer.Verify(token, kindLogin); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected a session token to be rejected as a login token, got %v", err)
+	}
+
+	other, _ := NewSigner([]byte("other secret"))
+	if _, err := other.Verify(token, kindSession); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected a token from another secret to be rejected, got %v", err)
+	}
+
+	payload, sig, _ := strings.Cut(token, ".")
+	tampered := payload[:len(payload)-2] + "AA." + sig
+	if _, err := signer.Verify(tampered, kindSession); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected tampered token to be rejected, got %v", err)
+	}
+	for _, bad := range []string{"", "nodot", "a.b"} {
+		if _, err := signer.Verify(bad, kindSession); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify(%q): expected ErrInvalidToken, got %v", bad, err)
+		}
+	}
+}
+
+func TestSignerExpiry(t *testing.T) {
+	signer, _ := NewSigner([]byte("secret"))
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	signer.now = func() time.Time { return now }
+
+	token, _ := signer.Issue(7, "iamoxyrus", kindLogin, 10*time.Minute)
+	now = now.Add(10 * time.Minute)
+	if _, err := signer.Verify(token, kindLogin); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestSignerRedeemIsSingleUse(t *testing.T) {
+	signer, _ := NewSigner(nil)
+	token, _ := signer.Issue(7, "iamoxyrus", kindLogin, time.Minute)
+
+	if _, err := signer.Redeem(token); err != nil {
+		t.Fatalf("first Redeem error: %v", err)
+	}
+	if _, err := signer.Redeem(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected second Redeem to fail, got %v", err)
+	}
+}